@@ -0,0 +1,128 @@
+/**
+ * File        : accumulator.go
+ * Description : Streaming threshold signature recovery.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module collects signature shares as they arrive over the network, so
+ * that a combiner does not have to gather them into slices before calling
+ * Threshold. Each share is verified on arrival, and the threshold signature is
+ * recovered as soon as t valid shares are present.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+)
+
+// A ThresholdAccumulator collects signature shares on one message digest. It is
+// safe for concurrent use.
+type ThresholdAccumulator struct {
+	mutex      sync.Mutex
+	threshold  int
+	hash       [sha256.Size]byte
+	memberKeys []PublicKey
+	system     System
+	shares     map[int]Signature
+	done       bool
+}
+
+// Create an accumulator for signature shares on the message digest from the
+// group members with the given public key shares, where the key of member i is
+// at index i. The keys must remain valid while the accumulator is in use.
+func NewThresholdAccumulator(t int, hash [sha256.Size]byte, memberKeys []PublicKey, system System) (*ThresholdAccumulator, error) {
+	if t < 1 || len(memberKeys) < t {
+		return nil, errors.New("bls.NewThresholdAccumulator: Bad threshold parameters.")
+	}
+	if !system.combinable() {
+		return nil, errors.New("bls.NewThresholdAccumulator: Signatures without a sign cannot be combined.")
+	}
+	return &ThresholdAccumulator{
+		threshold:  t,
+		hash:       hash,
+		memberKeys: memberKeys,
+		system:     system,
+		shares:     make(map[int]Signature),
+	}, nil
+}
+
+// Add the signature share of a group member. The share is copied, so the caller
+// may free it. An error is returned if the share is invalid, if the member has
+// already contributed, or if the signature has already been recovered. Once t
+// valid shares are present, the threshold signature is returned and the second
+// return value is true. This function allocates C structures on the C heap
+// using malloc. It is the responsibility of the caller to prevent memory leaks
+// by arranging for the C structures to be freed.
+func (acc *ThresholdAccumulator) Add(memberId int, share Signature) (Signature, bool, error) {
+
+	acc.mutex.Lock()
+	defer acc.mutex.Unlock()
+
+	// Check the share.
+	if acc.done {
+		return Element{}, false, errors.New("bls.Add: Signature already recovered.")
+	}
+	if memberId < 0 || memberId >= len(acc.memberKeys) {
+		return Element{}, false, errors.New("bls.Add: Member identifier out of range.")
+	}
+	if _, ok := acc.shares[memberId]; ok {
+		return Element{}, false, errors.New("bls.Add: Duplicate share.")
+	}
+	if !VerifyShare(share, acc.hash, acc.memberKeys[memberId]) {
+		return Element{}, false, errors.New("bls.Add: Invalid share.")
+	}
+
+	// Store a copy of the share.
+	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(sigma, acc.system.pairing.get)
+	C.element_set(sigma, share.get)
+	acc.shares[memberId] = Element{sigma}
+	if len(acc.shares) < acc.threshold {
+		return Element{}, false, nil
+	}
+
+	// Recover the threshold signature.
+	shares := make([]Signature, 0, len(acc.shares))
+	memberIds := make([]int, 0, len(acc.shares))
+	for id, share := range acc.shares {
+		shares = append(shares, share)
+		memberIds = append(memberIds, id)
+	}
+	signature, err := Threshold(shares, memberIds, acc.system)
+	if err != nil {
+		return Element{}, false, err
+	}
+	acc.done = true
+
+	// Return the threshold signature.
+	return signature, true, nil
+
+}
+
+// Get the number of valid shares collected so far.
+func (acc *ThresholdAccumulator) Len() int {
+	acc.mutex.Lock()
+	defer acc.mutex.Unlock()
+	return len(acc.shares)
+}
+
+// Free the memory occupied by the collected shares. The accumulator cannot be
+// used after calling this function.
+func (acc *ThresholdAccumulator) Free() {
+	acc.mutex.Lock()
+	defer acc.mutex.Unlock()
+	for id, share := range acc.shares {
+		share.Free()
+		delete(acc.shares, id)
+	}
+	acc.done = true
+}
@@ -0,0 +1,102 @@
+/**
+ * File        : accumulator_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for streaming threshold signature recovery.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestThresholdAccumulator(test *testing.T) {
+
+	t := 3
+	n := 5
+	message := "This is a message."
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	acc, err := NewThresholdAccumulator(t, hash, memberKeys, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Invalid and duplicate shares are rejected.
+	other := sha256.Sum256([]byte("This is another message."))
+	bad := Sign(other, memberSecrets[0])
+	_, _, err = acc.Add(0, bad)
+	if err == nil {
+		test.Fatal("Accepted an invalid share.")
+	}
+	bad.Free()
+	share := Sign(hash, memberSecrets[4])
+	_, done, err := acc.Add(4, share)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if done {
+		test.Fatal("Recovered a signature from too few shares.")
+	}
+	_, _, err = acc.Add(4, share)
+	if err == nil {
+		test.Fatal("Accepted a duplicate share.")
+	}
+	share.Free()
+
+	// The signature is recovered with the t-th valid share.
+	var signature Signature
+	for _, id := range []int{1, 2} {
+		share := Sign(hash, memberSecrets[id])
+		signature, done, err = acc.Add(id, share)
+		if err != nil {
+			test.Fatal(err)
+		}
+		share.Free()
+	}
+	if !done {
+		test.Fatal("Failed to recover the signature.")
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+	share = Sign(hash, memberSecrets[3])
+	_, _, err = acc.Add(3, share)
+	if err == nil {
+		test.Fatal("Accepted a share after recovery.")
+	}
+	share.Free()
+
+	// Clean up.
+	acc.Free()
+	signature.Free()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,125 @@
+/**
+ * File        : backend.go
+ * Description : Signing backends.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module separates the one step of signing that needs the private key,
+ * raising the message point to the power of the key, from hashing, aggregation,
+ * and verification. A backend performs that step, so the key can be held by a
+ * hardware module or a remote service rather than in process memory.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// A SignerBackend signs messages that are already mapped to points in G1 using
+// a private key it holds.
+type SignerBackend interface {
+
+	// Return the public key of the backend. The key must not be freed by the
+	// caller.
+	PublicKey() PublicKey
+
+	// Sign a message that is already mapped to a point in G1.
+	SignPoint(point Point) (Signature, error)
+}
+
+// A HashSigner is a backend that maps message digests to points itself, such as
+// a remote service that only accepts digests. SignWithBackend prefers SignHash
+// over SignPoint when a backend implements it.
+type HashSigner interface {
+	SignerBackend
+
+	// Sign a message digest.
+	SignHash(hash [sha256.Size]byte) (Signature, error)
+}
+
+type localBackend struct {
+	key    PublicKey
+	secret PrivateKey
+}
+
+// Create a backend for a private key held in process memory. The backend does
+// not take ownership of the key pair.
+func LocalBackend(key PublicKey, secret PrivateKey) SignerBackend {
+	return localBackend{key, secret}
+}
+
+func (backend localBackend) PublicKey() PublicKey {
+	return backend.key
+}
+
+func (backend localBackend) SignPoint(point Point) (Signature, error) {
+	return signPoint(point, backend.secret)
+}
+
+// Sign a message digest using a backend. The digest is mapped to a point as in
+// Sign. This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging for the
+// C structures to be freed.
+func SignWithBackend(hash [sha256.Size]byte, backend SignerBackend) (Signature, error) {
+	if hs, ok := backend.(HashSigner); ok {
+		return hs.SignHash(hash)
+	}
+	h := backend.PublicKey().system.digestToPoint(hash[:])
+	sigma, err := backend.SignPoint(Element{h})
+	Element{h}.Free()
+	return sigma, err
+}
+
+// A RemoteSigner is a service that holds a private key outside this process,
+// such as a cloud key management service. It receives a message point in the
+// signature encoding of the cryptosystem and returns the point raised to the
+// power of the key in the same encoding.
+type RemoteSigner interface {
+	SignBytes(point []byte) ([]byte, error)
+}
+
+// The RemoteSignerFunc type is an adapter to allow the use of ordinary
+// functions as remote signers.
+type RemoteSignerFunc func(point []byte) ([]byte, error)
+
+// SignBytes calls f(point).
+func (f RemoteSignerFunc) SignBytes(point []byte) ([]byte, error) {
+	return f(point)
+}
+
+type remoteBackend struct {
+	key    PublicKey
+	remote RemoteSigner
+}
+
+// Create a backend that delegates signing to a remote signer holding the
+// private key of the public key. Each signature returned by the remote signer
+// is verified before it is used, so a faulty or compromised service cannot
+// inject invalid signatures into aggregates.
+func RemoteBackend(key PublicKey, remote RemoteSigner) SignerBackend {
+	return remoteBackend{key, remote}
+}
+
+func (backend remoteBackend) PublicKey() PublicKey {
+	return backend.key
+}
+
+func (backend remoteBackend) SignPoint(point Point) (Signature, error) {
+	system := backend.key.system
+	bytes, err := backend.remote.SignBytes(system.SigToBytes(point))
+	if err != nil {
+		return Element{}, err
+	}
+	sigma, err := system.SigFromBytes(bytes)
+	if err != nil {
+		return Element{}, err
+	}
+	if !VerifyPoint(sigma, point, backend.key) {
+		sigma.Free()
+		return Element{}, errors.New("bls.SignPoint: Invalid signature from remote signer.")
+	}
+	return sigma, nil
+}
@@ -0,0 +1,116 @@
+/**
+ * File        : backend_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for signing backends.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestLocalBackend(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// A backend signature equals a local signature.
+	hash := sha256.Sum256([]byte(message))
+	signature1, err := SignWithBackend(hash, LocalBackend(key, secret))
+	if err != nil {
+		test.Fatal(err)
+	}
+	signature2 := Sign(hash, secret)
+	if !signature1.Equal(signature2) || !Verify(signature1, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature1.Free()
+	signature2.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestRemoteBackend(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate key pairs.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	otherKey, other, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Simulate a remote service holding a private key.
+	service := func(secret PrivateKey) RemoteSignerFunc {
+		return func(bytes []byte) ([]byte, error) {
+			point, err := system.SigFromBytes(bytes)
+			if err != nil {
+				return nil, err
+			}
+			defer point.Free()
+			sigma := SignPoint(point, secret)
+			defer sigma.Free()
+			return system.SigToBytes(sigma), nil
+		}
+	}
+
+	// Sign through the remote service.
+	hash := sha256.Sum256([]byte(message))
+	signature, err := SignWithBackend(hash, RemoteBackend(key, service(secret)))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// A service signing with the wrong key must be detected.
+	_, err = SignWithBackend(hash, RemoteBackend(key, service(other)))
+	if err == nil {
+		test.Fatal("Accepted an invalid remote signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	otherKey.Free()
+	other.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
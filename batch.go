@@ -0,0 +1,211 @@
+/**
+ * File        : batch.go
+ * Description : Batch verification.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module verifies many independent signatures at once. Each signature is
+ * raised to a random weight, so that the weighted signatures can be combined
+ * into one, and a batch that contains an invalid signature passes with
+ * probability at most 2^-128. The pairings of the signatures that share a key,
+ * or that share a message digest, are combined as well, and the remaining
+ * pairings are computed as a single product.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"unsafe"
+)
+
+// Verify the signatures on the message digests using the public keys of the
+// signers, where the signature, the digest, and the key of each signer are at
+// the same index. The result is true only if every signature is valid, and a
+// caller that needs to know which signatures are invalid must verify them one
+// at a time.
+func BatchVerify(signatures []Signature, hashes [][sha256.Size]byte, keys []PublicKey) (bool, error) {
+
+	// Check the list length.
+	if len(signatures) == 0 {
+		return false, errors.New("bls.BatchVerify: Empty list.")
+	}
+	if len(signatures) != len(hashes) || len(signatures) != len(keys) {
+		return false, errors.New("bls.BatchVerify: List length mismatch.")
+	}
+
+	// Check subgroup membership.
+	system := keys[0].system
+	gx := make([]Element, len(keys))
+	for i := range keys {
+		gx[i] = keys[i].gx
+	}
+	if isIdentity(signatures...) || isIdentity(gx...) {
+		return false, nil
+	}
+	if system.checkOnUse() {
+		if !system.inSubgroup(signatures...) || !system.inSubgroup(gx...) {
+			return false, nil
+		}
+	}
+
+	// Signatures without the sign of the y-coordinate cannot be combined, so
+	// check them one at a time.
+	if system.encoding.Format == PointXOnly {
+		for i := range signatures {
+			if !Verify(signatures[i], hashes[i], keys[i]) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	// Choose the random weights.
+	weights := make([]*big.Int, len(signatures))
+	bound := big.NewInt(0).Lsh(big.NewInt(1), batchWeightBits)
+	for i := range weights {
+		weight, err := rand.Int(rand.Reader, bound)
+		if err != nil {
+			return false, err
+		}
+		weights[i] = weight
+	}
+
+	// Group the signers by key or by message digest, whichever gives fewer
+	// groups.
+	byKey := make(map[string]int)
+	byHash := make(map[[sha256.Size]byte]int)
+	keyGroups := make([]int, len(keys))
+	hashGroups := make([]int, len(hashes))
+	for i := range keys {
+		id := keys[i].gx.key()
+		if _, ok := byKey[id]; !ok {
+			byKey[id] = len(byKey)
+		}
+		keyGroups[i] = byKey[id]
+		if _, ok := byHash[hashes[i]]; !ok {
+			byHash[hashes[i]] = len(byHash)
+		}
+		hashGroups[i] = byHash[hashes[i]]
+	}
+	groupByKey := len(byKey) <= len(byHash)
+	n := len(byHash)
+	groups := hashGroups
+	if groupByKey {
+		n = len(byKey)
+		groups = keyGroups
+	}
+
+	// Allocate the inputs of the pairings. The first pair is the inverse of
+	// the weighted product of the signatures and the system parameter.
+	in1 := make([]*C.struct_element_s, n+1)
+	in2 := make([]*C.struct_element_s, n+1)
+	for j := range in1 {
+		in1[j] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G1(in1[j], system.pairing.get)
+		in2[j] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G2(in2[j], system.pairing.get)
+	}
+	bases := make([]*C.struct_element_s, len(signatures))
+	for i := range signatures {
+		bases[i] = signatures[i].get
+	}
+	multiExp(in1[0], bases, weights)
+	C.element_set(in2[0], system.g.get)
+
+	// Combine the weighted signatures, and the weighted digests of each key or
+	// the weighted keys of each digest.
+	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(h, system.pairing.get)
+	s := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(s, system.pairing.get)
+	k := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(k, system.pairing.get)
+	for j := 1; j <= n; j++ {
+		if groupByKey {
+			C.element_set1(in1[j])
+		} else {
+			C.element_set1(in2[j])
+		}
+	}
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	for i := range signatures {
+		setMpz(&z[0], weights[i])
+		j := groups[i] + 1
+		system.hashToPoint(h, hashes[i][:])
+		if groupByKey {
+			C.element_pow_mpz(s, h, &z[0])
+			C.element_mul(in1[j], in1[j], s)
+			C.element_set(in2[j], keys[i].gx.get)
+		} else {
+			C.element_set(in1[j], h)
+			C.element_pow_mpz(k, keys[i].gx.get, &z[0])
+			C.element_mul(in2[j], in2[j], k)
+		}
+	}
+	C.element_invert(in1[0], in1[0])
+
+	// Check that the product of the pairings is one.
+	result := system.pairingProductIsOne(in1, in2)
+
+	// Clean up.
+	C.mpz_clear(&z[0])
+	C.element_clear(h)
+	C.element_clear(s)
+	C.element_clear(k)
+	for j := range in1 {
+		C.element_clear(in1[j])
+		C.element_clear(in2[j])
+	}
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Check whether the product of the pairings of the elements of G1 and G2 at the
+// same indices is one, computing the product in a single call into PBC.
+func (system System) pairingProductIsOne(in1 []*C.struct_element_s, in2 []*C.struct_element_s) bool {
+
+	// Copy the inputs into arrays.
+	n := len(in1)
+	mem1 := C.malloc(C.size_t(n) * sizeOfElement)
+	mem2 := C.malloc(C.size_t(n) * sizeOfElement)
+	arr1 := unsafe.Slice((*C.struct_element_s)(mem1), n)
+	arr2 := unsafe.Slice((*C.struct_element_s)(mem2), n)
+	for j := 0; j < n; j++ {
+		C.element_init_same_as(&arr1[j], in1[j])
+		C.element_set(&arr1[j], in1[j])
+		C.element_init_same_as(&arr2[j], in2[j])
+		C.element_set(&arr2[j], in2[j])
+	}
+
+	// Calculate the product.
+	product := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_GT(product, system.pairing.get)
+	C.element_prod_pairing(product, (*C.element_t)(mem1), (*C.element_t)(mem2), C.int(n))
+	result := C.element_is1(product) == 1
+
+	// Clean up.
+	C.element_clear(product)
+	for j := 0; j < n; j++ {
+		C.element_clear(&arr1[j])
+		C.element_clear(&arr2[j])
+	}
+	C.free(mem1)
+	C.free(mem2)
+
+	// Return the result.
+	return result
+
+}
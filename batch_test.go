@@ -0,0 +1,93 @@
+/**
+ * File        : batch_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for batch verification.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestBatchVerify(test *testing.T) {
+
+	n := 8
+
+	// Generate the key pairs.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, n)
+	secrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Sign distinct messages with distinct keys, a common message with all
+	// keys, and distinct messages with one key.
+	batches := []func(i int) ([sha256.Size]byte, int){
+		func(i int) ([sha256.Size]byte, int) {
+			return sha256.Sum256([]byte(fmt.Sprintf("This is message %d.", i))), i
+		},
+		func(i int) ([sha256.Size]byte, int) {
+			return sha256.Sum256([]byte("This is a message.")), i
+		},
+		func(i int) ([sha256.Size]byte, int) {
+			return sha256.Sum256([]byte(fmt.Sprintf("This is message %d.", i))), 0
+		},
+	}
+	for _, batch := range batches {
+		signatures := make([]Signature, n)
+		hashes := make([][sha256.Size]byte, n)
+		signers := make([]PublicKey, n)
+		for i := 0; i < n; i++ {
+			var k int
+			hashes[i], k = batch(i)
+			signatures[i] = Sign(hashes[i], secrets[k])
+			signers[i] = keys[k]
+		}
+		valid, err := BatchVerify(signatures, hashes, signers)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !valid {
+			test.Fatal("Failed to verify batch.")
+		}
+
+		// A batch with a signature on another message must be rejected.
+		hashes[n/2] = sha256.Sum256([]byte("This is another message."))
+		valid, err = BatchVerify(signatures, hashes, signers)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if valid {
+			test.Fatal("Accepted a batch with an invalid signature.")
+		}
+		for i := range signatures {
+			signatures[i].Free()
+		}
+	}
+
+	// Clean up.
+	for i := 0; i < n; i++ {
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
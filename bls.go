@@ -12,9 +12,16 @@
 package bls
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"runtime"
+	"sync"
 	"unsafe"
 )
 
@@ -28,12 +35,80 @@ int callback(pbc_cm_t cm, void *data) {
 }
 
 int search(pbc_param_ptr params, unsigned int d, unsigned int bitlimit) {
-	int m = d % 4;
-	if (d == 0 || m == 1 || m == 2) {
-		pbc_die("Discriminant must be 0 or 3 mod 4 and positive.");
-	}
 	return pbc_cm_search_d(callback, params, d, bitlimit);
 }
+
+void locked_enter(void);
+void locked_leave(void);
+
+void pow_secret(struct element_s *out, struct element_s *base, struct element_s *x, unsigned char *blind, int blindlen) {
+	mpz_t e, k;
+	locked_enter();
+	mpz_init(e);
+	mpz_init(k);
+	element_to_mpz(e, x);
+	mpz_import(k, blindlen, 1, 1, 1, 0, blind);
+	mpz_addmul(e, k, x->field->order);
+	locked_leave();
+	element_pow_mpz(out, base, e);
+	mpz_set_ui(e, 0);
+	mpz_clear(e);
+	mpz_clear(k);
+}
+
+void sign_digest(struct element_s *sigma, struct element_s *h, unsigned char *digest, int len, struct element_s *x, unsigned char *blind, int blindlen) {
+	element_from_hash(h, digest, len);
+	pow_secret(sigma, h, x, blind, blindlen);
+}
+
+int verify_point(struct element_s *sigma, struct element_s *g, struct pairing_pp_s *gpp, struct element_s *h, struct element_s *gx, struct element_s *lhs, struct element_s *rhs, int xonly) {
+	if (gpp) {
+		pairing_pp_apply(lhs, sigma, gpp);
+	} else {
+		element_pairing(lhs, sigma, g);
+	}
+	element_pairing(rhs, h, gx);
+	if (!element_cmp(lhs, rhs)) {
+		return 1;
+	}
+	if (xonly) {
+		element_mul(lhs, lhs, rhs);
+		return element_is1(lhs);
+	}
+	return 0;
+}
+
+void aggregate(struct element_s *sigma, struct element_s **signatures, int n) {
+	element_set(sigma, signatures[0]);
+	for (int i = 1; i < n; i++) {
+		element_mul(sigma, sigma, signatures[i]);
+	}
+}
+
+void pp_pow_secret(struct element_s *out, struct element_pp_s *pp, struct element_s *x);
+
+void eval_shares(struct element_s **secrets, struct element_s **keys, struct element_s **commits, struct element_s **xs, int n, struct element_s **coeff, int t, struct element_s *g) {
+	element_pp_t pp;
+	if (keys) {
+		element_pp_init(pp, g);
+	}
+	for (int i = 0; i < n; i++) {
+		element_set0(secrets[i]);
+		for (int j = t - 1; j >= 0; j--) {
+			element_mul(secrets[i], secrets[i], xs[i]);
+			element_add(secrets[i], secrets[i], coeff[j]);
+		}
+		if (keys) {
+			pp_pow_secret(keys[i], pp, secrets[i]);
+		}
+	}
+	if (keys) {
+		for (int j = 0; j < t; j++) {
+			pp_pow_secret(commits[j], pp, coeff[j]);
+		}
+		element_pp_clear(pp);
+	}
+}
 */
 import "C"
 
@@ -50,12 +125,19 @@ type Params struct {
 }
 
 type Pairing struct {
-	get *C.struct_pairing_s
+	get    *C.struct_pairing_s
+	params string
 }
 
 type System struct {
-	pairing Pairing
-	g       Element
+	pairing  Pairing
+	g        Element
+	hash     HashType
+	encoding encoding
+	subgroup SubgroupCheck
+	dst      []byte
+	points   *pointCache
+	gpp      *C.struct_pairing_pp_s
 }
 
 type PublicKey struct {
@@ -70,6 +152,8 @@ type PrivateKey struct {
 
 type Signature = Element
 
+type Point = Element
+
 // Generate type A pairing parameters. This function allocates C structures on
 // the C heap using malloc. It is the responsibility of the caller to prevent
 // memory leaks by arranging for the C structures to be freed. More information
@@ -87,11 +171,30 @@ func GenParamsTypeA(rbits int, qbits int) Params {
 // about type D pairing parameters can be found in the PBC library manual:
 // https://crypto.stanford.edu/pbc/manual/ch08s06.html.
 func GenParamsTypeD(d uint, bitlimit uint) (Params, error) {
+
+	// Check the inputs, which PBC would otherwise reject by aborting the
+	// process.
+	if !validDiscriminant(d) {
+		return Params{}, errors.New("bls.GenParamsTypeD: Discriminant must be 0 or 3 mod 4 and positive.")
+	}
+	if uint64(bitlimit) > math.MaxUint32 {
+		return Params{}, errors.New("bls.GenParamsTypeD: Bit limit out of range.")
+	}
+
+	// Search for a curve.
 	params := (*C.struct_pbc_param_s)(C.malloc(sizeOfParams))
 	if C.search(params, C.uint(d), C.uint(bitlimit)) == 0 {
+		C.free(unsafe.Pointer(params))
 		return Params{}, errors.New("bls.GenParamsTypeD: No suitable curves for this discriminant.")
 	}
 	return Params{params}, nil
+
+}
+
+// Check whether the discriminant is positive, 0 or 3 mod 4, and small enough
+// for PBC.
+func validDiscriminant(d uint) bool {
+	return d != 0 && d%4 != 1 && d%4 != 2 && uint64(d) <= math.MaxUint32
 }
 
 // Generate type F pairing parameters. This function allocates C structures on
@@ -123,22 +226,42 @@ func ParamsFromBytes(bytes []byte) (Params, error) {
 	return Params{params}, nil
 }
 
+// ParamsFromString imports Params from a string in the standard PBC parameter
+// format, as produced by String or found in param/a.param.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func ParamsFromString(s string) (Params, error) {
+	return ParamsFromBytes([]byte(s))
+}
+
 // Generate a pairing from the given parameters. This function allocates C
 // structures on the C heap using malloc. It is the responsibility of the caller
 // to prevent memory leaks by arranging for the C structures to be freed.
 func GenPairing(params Params) Pairing {
 	pairing := (*C.struct_pairing_s)(C.malloc(sizeOfPairing))
 	C.pairing_init_pbc_param(pairing, params.get)
-	return Pairing{pairing}
+	return Pairing{pairing, params.String()}
 }
 
 // Generate a cryptosystem from the given pairing. This function allocates C
 // structures on the C heap using malloc. It is the responsibility of the caller
 // to prevent memory leaks by arranging for the C structures to be freed.
 func GenSystem(pairing Pairing) (System, error) {
+	return GenSystemWithHash(pairing, HashSHA256)
+}
+
+// Generate a cryptosystem from the given pairing that uses the given hash
+// function to derive the system parameter and keys, and to hash messages. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func GenSystemWithHash(pairing Pairing, hash HashType) (System, error) {
 
 	// Generate a cryptographically secure pseudorandom hash.
-	hash, err := randomHash()
+	system := System{pairing: pairing, hash: hash}
+	digest, err := system.randomDigest()
 	if err != nil {
 		return System{}, err
 	}
@@ -146,27 +269,106 @@ func GenSystem(pairing Pairing) (System, error) {
 	// Derive the system parameter from the pseudorandom hash.
 	g := (*C.struct_element_s)(C.malloc(sizeOfElement))
 	C.element_init_G2(g, pairing.get)
-	C.element_from_hash(g, unsafe.Pointer(&hash[0]), sha256.Size)
+	C.element_from_hash(g, unsafe.Pointer(&digest[0]), sha256.Size)
 
 	// Return the cryptosystem.
-	return System{pairing, Element{g}}, nil
+	system.g = Element{g}
+	system.gpp = system.preprocessG()
+	return system, nil
 
 }
 
-// SystemFromBytes imports a System from the provided byte slice.
+// SystemFromBytes imports a System from the provided byte slice. It expects
+// the data format exported by ToBytes. The pairing is rebuilt from the
+// parameters contained in the data.
 //
 // This function allocates C structures on the C heap using malloc. It is
 // the responsibility of the caller to prevent memory leaks by arranging
-// for the C structures to be freed.
-func SystemFromBytes(pairing Pairing, bytes []byte) (System, error) {
+// for the C structures, including the pairing, to be freed.
+func SystemFromBytes(bytes []byte) (System, error) {
+
+	// Split the data into its fields.
+	var fields [][]byte
+	for len(bytes) != 0 {
+		if len(bytes) < 4 || uint32(len(bytes)-4) < binary.BigEndian.Uint32(bytes) {
+			return System{}, errors.New("bls.FromBytes: System too short.")
+		}
+		n := binary.BigEndian.Uint32(bytes)
+		fields, bytes = append(fields, bytes[4:4+n]), bytes[4+n:]
+	}
+	if len(fields) != 7 || len(fields[2]) != 4 || len(fields[3]) != 4 || len(fields[4]) > 255 || len(fields[5]) != 16 || len(fields[6]) != 4 {
+		return System{}, errors.New("bls.FromBytes: System length mismatch.")
+	}
+	hash := HashType(binary.BigEndian.Uint32(fields[3]))
+	if !hash.known() {
+		return System{}, errors.New("bls.FromBytes: Unknown hash function.")
+	}
+	little := binary.BigEndian.Uint32(fields[5])
+	enc := Encoding{
+		LittleEndian: little == 1,
+		SignBit:      SignBit(binary.BigEndian.Uint32(fields[5][4:])),
+		FieldSize:    int(binary.BigEndian.Uint32(fields[5][8:])),
+		Format:       PointFormat(binary.BigEndian.Uint32(fields[5][12:])),
+	}
+	if little > 1 || enc.SignBit < SignTrailingByte || enc.SignBit > SignHighBit || enc.FieldSize > 1<<16 {
+		return System{}, errors.New("bls.FromBytes: Unknown encoding.")
+	}
+	subgroup := SubgroupCheck(binary.BigEndian.Uint32(fields[6]))
+	if subgroup < SubgroupCheckAlways || subgroup > SubgroupCheckNever {
+		return System{}, errors.New("bls.FromBytes: Unknown subgroup check policy.")
+	}
+
+	// Rebuild the pairing.
+	params, err := ParamsFromBytes(fields[0])
+	if err != nil {
+		return System{}, err
+	}
+	pairing := GenPairing(params)
+	params.Free()
+
+	// Check the length of the system parameter.
 	n := int(C.pairing_length_in_bytes_compressed_G2(pairing.get))
-	if n != len(bytes) {
+	if n != len(fields[1]) {
+		pairing.Free()
 		return System{}, errors.New("bls.FromBytes: System length mismatch.")
 	}
+
+	// Import the system parameter.
 	g := (*C.struct_element_s)(C.malloc(sizeOfElement))
 	C.element_init_G2(g, pairing.get)
-	C.element_from_bytes_compressed(g, (*C.uchar)(unsafe.Pointer(&bytes[0])))
-	return System{pairing, Element{g}}, nil
+	if !elementFromBytes(g, fields[1], PointCompressed) {
+		C.element_clear(g)
+		pairing.Free()
+		return System{}, errors.New("bls.FromBytes: Failed to decode system parameter.")
+	}
+	system := System{pairing: pairing, g: Element{g}, hash: hash, subgroup: subgroup}
+	if len(fields[4]) != 0 {
+		system.dst = fields[4]
+	}
+	if !onCurve(g, fields[1], PointCompressed) {
+		C.element_clear(g)
+		pairing.Free()
+		return System{}, errors.New("bls.FromBytes: System not on curve.")
+	}
+	if !system.inSubgroup(system.g) {
+		C.element_clear(g)
+		pairing.Free()
+		return System{}, errors.New("bls.FromBytes: System not in subgroup.")
+	}
+
+	// Select the encoding and check the length of a signature.
+	system, err = system.WithEncoding(enc)
+	if err == nil && uint32(system.encodedSigLength()) != binary.BigEndian.Uint32(fields[2]) {
+		err = errors.New("bls.FromBytes: Signature length mismatch.")
+	}
+	if err != nil {
+		C.element_clear(g)
+		pairing.Free()
+		return System{}, err
+	}
+	system.gpp = system.preprocessG()
+	return system, nil
+
 }
 
 // Generate a key pair from the given cryptosystem. This function allocates C
@@ -175,36 +377,121 @@ func SystemFromBytes(pairing Pairing, bytes []byte) (System, error) {
 func GenKeys(system System) (PublicKey, PrivateKey, error) {
 
 	// Generate a cryptographically secure pseudorandom hash.
-	hash, err := randomHash()
+	hash, err := system.randomDigest()
 	if err != nil {
 		return PublicKey{}, PrivateKey{}, err
 	}
 
-	// Derive the private key from the pseudorandom hash.
+	// Derive the key pair from the pseudorandom hash.
+	key, secret := system.keysFromDigest(hash)
+	clear(hash[:])
+	return key, secret, nil
+
+}
+
+// Derive a key pair deterministically from a seed of at least 32 bytes, such
+// as a BIP-39 seed. The seed is hashed with Digest. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func GenKeysFromSeed(system System, seed []byte) (PublicKey, PrivateKey, error) {
+	if len(seed) < sha256.Size {
+		return PublicKey{}, PrivateKey{}, errors.New("bls.GenKeysFromSeed: Seed too short.")
+	}
+	hash, err := system.Digest(append([]byte("go-bls key"), seed...))
+	if err != nil {
+		return PublicKey{}, PrivateKey{}, err
+	}
+	key, secret := system.keysFromDigest(hash)
+	clear(hash[:])
+	return key, secret, nil
+}
+
+// Derive a key pair from a digest. This function allocates C structures on the
+// C heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func (system System) keysFromDigest(hash [sha256.Size]byte) (PublicKey, PrivateKey) {
+
+	// Derive the private key from the hash.
 	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_Zr(x, system.pairing.get)
+	initSecret(x, system)
 	C.element_from_hash(x, unsafe.Pointer(&hash[0]), sha256.Size)
 
 	// Derive the public key from the private key.
-	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G2(gx, system.pairing.get)
-	C.element_pow_zn(gx, system.g.get, x)
+	secret := PrivateKey{system, Element{x}}
+	return secret.Public(), secret
 
-	// Return the key pair.
-	return PublicKey{system, Element{gx}}, PrivateKey{system, Element{x}}, nil
+}
 
+// Derive the public key g^x of the private key in its cryptosystem. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+//
+// The private key is blinded as in Sign, and Public panics if the blinding
+// factor cannot be drawn from crypto/rand.
+func (secret PrivateKey) Public() PublicKey {
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, secret.system.pairing.get)
+	err := powSecret(gx, secret.system.g.get, secret.x.get)
+	if err != nil {
+		C.element_clear(gx)
+		C.free(unsafe.Pointer(gx))
+		panic(err)
+	}
+	return PublicKey{secret.system, Element{gx}}
+}
+
+// Copy the public key. This function allocates C structures on the C heap
+// using malloc. It is the responsibility of the caller to prevent memory leaks
+// by arranging for the C structures to be freed.
+func (key PublicKey) copy() PublicKey {
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, key.system.pairing.get)
+	C.element_set(gx, key.gx.get)
+	return PublicKey{key.system, Element{gx}}
 }
 
 // Generate a key pair from the given cryptosystem and divide each key into n
 // shares such that t shares can combine signatures to recover a threshold
-// signature. This function allocates C structures on the C heap using malloc.
-// It is the responsibility of the caller to prevent memory leaks by arranging
-// for the C structures to be freed.
-func GenKeyShares(t int, n int, system System) (PublicKey, []PublicKey, PrivateKey, []PrivateKey, error) {
+// signature. The commitments g^{a_j} to the coefficients of the polynomial are
+// also returned, so that members can check their shares with
+// VerifySecretShares without trusting the dealer. The share of member i is the
+// evaluation of the polynomial at i + 1. This function allocates C structures
+// on the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func GenKeyShares(t int, n int, system System) (PublicKey, []PublicKey, PrivateKey, []PrivateKey, []PublicKey, error) {
+	if t < 1 || n < t {
+		return PublicKey{}, nil, PrivateKey{}, nil, nil, errors.New("bls.GenKeyShares: Bad threshold parameters.")
+	}
+	return GenKeySharesAt(t, memberPoints(identityIds(n)), system)
+}
+
+// Generate a key pair and key shares as GenKeyShares does, but evaluate the
+// polynomial at the given points instead of 1, ..., n. The points must be
+// nonzero and distinct modulo the group order, and can be derived from
+// arbitrary member identifiers with MemberPoint. The share at index i belongs
+// to the member at points[i]. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func GenKeySharesAt(t int, points []*big.Int, system System) (PublicKey, []PublicKey, PrivateKey, []PrivateKey, []PublicKey, error) {
 
 	// Check the threshold parameters.
+	n := len(points)
 	if t < 1 || n < t {
-		return PublicKey{}, nil, PrivateKey{}, nil, errors.New("bls.GenKeyShares: Bad threshold parameters.")
+		return PublicKey{}, nil, PrivateKey{}, nil, nil, errors.New("bls.GenKeyShares: Bad threshold parameters.")
+	}
+
+	// Check the evaluation points.
+	r := system.order()
+	xs := make([]*big.Int, n+1)
+	xs[0] = big.NewInt(0)
+	seen := make(map[string]bool)
+	for i := range points {
+		xs[i+1] = big.NewInt(0).Mod(points[i], r)
+		if xs[i+1].Sign() == 0 || seen[xs[i+1].String()] {
+			return PublicKey{}, nil, PrivateKey{}, nil, nil, errors.New("bls.GenKeyShares: Evaluation points must be nonzero and distinct.")
+		}
+		seen[xs[i+1].String()] = true
 	}
 
 	// Generate a polynomial.
@@ -214,114 +501,334 @@ func GenKeyShares(t int, n int, system System) (PublicKey, []PublicKey, PrivateK
 	for j := range coeff {
 
 		// Generate a cryptographically secure pseudorandom hash.
-		hash, err = randomHash()
+		hash, err = system.randomDigest()
 		if err != nil {
-			return PublicKey{}, nil, PrivateKey{}, nil, err
+			for k := 0; k < j; k++ {
+				wipe(coeff[k])
+				C.element_clear(coeff[k])
+			}
+			return PublicKey{}, nil, PrivateKey{}, nil, nil, err
 		}
 
 		// Derive a coefficient of the polynomial from the pseudorandom hash.
 		coeff[j] = (*C.struct_element_s)(C.malloc(sizeOfElement))
-		C.element_init_Zr(coeff[j], system.pairing.get)
+		initSecret(coeff[j], system)
 		C.element_from_hash(coeff[j], unsafe.Pointer(&hash[0]), sha256.Size)
+		clear(hash[:])
 
 	}
 
-	// Derive the key pair and the key shares from the polynomial.
+	// Allocate the key pair, the key shares, and the evaluation points.
 	keys := make([]PublicKey, n+1)
 	secrets := make([]PrivateKey, n+1)
-	var bytes []byte
-	var ij C.mpz_t
-	C.mpz_init(&ij[0])
-	term := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_Zr(term, system.pairing.get)
+	keyPtrs := make([]*C.struct_element_s, n+1)
+	secretPtrs := make([]*C.struct_element_s, n+1)
+	xPtrs := make([]*C.struct_element_s, n+1)
+	var x C.mpz_t
+	C.mpz_init(&x[0])
 	for i := 0; i < n+1; i++ {
+		secretPtrs[i] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		initSecret(secretPtrs[i], system)
+		secrets[i] = PrivateKey{system, Element{secretPtrs[i]}}
+		keyPtrs[i] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G2(keyPtrs[i], system.pairing.get)
+		keys[i] = PublicKey{system, Element{keyPtrs[i]}}
+		xPtrs[i] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_Zr(xPtrs[i], system.pairing.get)
+		setMpz(&x[0], xs[i])
+		C.element_set_mpz(xPtrs[i], &x[0])
+	}
 
-		// Calculate a share of the private key by evaluating the polynomial.
-		secrets[i].system = system
-		secrets[i].x.get = (*C.struct_element_s)(C.malloc(sizeOfElement))
-		C.element_init_Zr(secrets[i].x.get, system.pairing.get)
-		C.element_set0(secrets[i].x.get)
-		for j := 0; j < t; j++ {
-			bytes = big.NewInt(0).Exp(big.NewInt(int64(i)), big.NewInt(int64(j)), nil).Bytes()
-			if len(bytes) == 0 {
-				C.mpz_set_si(&ij[0], 0)
-			} else {
-				C.mpz_import(&ij[0], C.size_t(len(bytes)), 1, 1, 1, 0, unsafe.Pointer(&bytes[0]))
-			}
-			C.element_mul_mpz(term, coeff[j], &ij[0])
-			C.element_add(secrets[i].x.get, secrets[i].x.get, term)
-		}
-
-		// Calculate a share of the public key by exponentiating the system parameter.
-		keys[i].system = system
-		keys[i].gx.get = (*C.struct_element_s)(C.malloc(sizeOfElement))
-		C.element_init_G2(keys[i].gx.get, system.pairing.get)
-		C.element_pow_zn(keys[i].gx.get, system.g.get, secrets[i].x.get)
-
+	// Allocate the commitments to the coefficients.
+	commitments := make([]PublicKey, t)
+	commitPtrs := make([]*C.struct_element_s, t)
+	for j := range coeff {
+		commitPtrs[j] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G2(commitPtrs[j], system.pairing.get)
+		commitments[j] = PublicKey{system, Element{commitPtrs[j]}}
 	}
 
-	// Clean up.
+	// Derive the key pair, the key shares, and the commitments from the
+	// polynomial. The shares of the private key are evaluated by Horner's rule,
+	// and the shares of the public key and the commitments by blinded
+	// exponentiation of the system parameter with a precomputed table, in a
+	// single call into C.
+	C.eval_shares(&secretPtrs[0], &keyPtrs[0], &commitPtrs[0], &xPtrs[0], C.int(n+1), &coeff[0], C.int(t), system.g.get)
+
+	// Clean up. The coefficients determine every share, so they are wiped.
 	for j := range coeff {
+		wipe(coeff[j])
 		C.element_clear(coeff[j])
 	}
-	C.mpz_clear(&ij[0])
-	C.element_clear(term)
+	for i := range xPtrs {
+		C.element_clear(xPtrs[i])
+	}
+	C.mpz_clear(&x[0])
 
-	// Return the key pair and the key shares.
-	return keys[0], keys[1:], secrets[0], secrets[1:], nil
+	// Return the key pair, the key shares, and the commitments.
+	return keys[0], keys[1:], secrets[0], secrets[1:], commitments, nil
 
 }
 
+// The byte length of the random multiple of the group order that is added to
+// the private key before each signature. PBC exponentiates in variable time,
+// so the blinding keeps the timing of signing from depending on the key alone.
+const signingBlindBytes = 8
+
 // Sign a message digest using a private key. This function allocates C
 // structures on the C heap using malloc. It is the responsibility of the caller
 // to prevent memory leaks by arranging for the C structures to be freed.
+//
+// Sign panics if the blinding factor cannot be drawn from crypto/rand. Use
+// SignDigest to handle that error instead.
 func Sign(hash [sha256.Size]byte, secret PrivateKey) Signature {
+	sigma, err := SignDigest(hash[:], secret)
+	if err != nil {
+		panic(err)
+	}
+	return sigma
+}
 
-	// Calculate h.
-	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(h, secret.system.pairing.get)
-	C.element_from_hash(h, unsafe.Pointer(&hash[0]), sha256.Size)
+// Sign a message digest of any length using a private key. The digest must not
+// be empty. This function allocates C structures on the C heap using malloc. It
+// is the responsibility of the caller to prevent memory leaks by arranging for
+// the C structures to be freed.
+func SignDigest(digest []byte, secret PrivateKey) (Signature, error) {
 
-	// Calculate sigma.
+	// Check the digest length.
+	if len(digest) == 0 {
+		return Element{}, errors.New("bls.SignDigest: Empty digest.")
+	}
+
+	// Draw a blinding factor.
+	var blind [signingBlindBytes]byte
+	_, err := rand.Read(blind[:])
+	if err != nil {
+		return Element{}, err
+	}
+
+	// Calculate h and sigma in a single call into C.
+	pairing := secret.system.pairing
+	h := getElement(pairing, poolG1)
 	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(sigma, secret.system.pairing.get)
-	C.element_pow_zn(sigma, h, secret.x.get)
+	C.element_init_G1(sigma, pairing.get)
+	C.sign_digest(sigma, h, (*C.uchar)(unsafe.Pointer(&digest[0])), C.int(len(digest)), secret.x.get, (*C.uchar)(unsafe.Pointer(&blind[0])), signingBlindBytes)
 
 	// Clean up.
-	C.element_clear(h)
+	putElement(pairing, poolG1, h)
 
 	// Return the signature.
-	return Element{sigma}
+	return Element{sigma}, nil
+
+}
+
+// Sign a message digest using a private key and write the signature to dst in
+// the format of SigToBytes, returning the number of bytes written. Unlike Sign,
+// this function does not allocate if the cryptosystem uses the native encoding,
+// which suits signers that serialize every signature they produce.
+func SignTo(dst []byte, hash [sha256.Size]byte, secret PrivateKey) (int, error) {
+
+	// Check the buffer length.
+	system := secret.system
+	n := system.sigLength()
+	if n < 1 {
+		return 0, errors.New("bls.SignTo: Invalid signature length.")
+	}
+	native := system.encoding.Encoding == (Encoding{})
+	if native && len(dst) < n {
+		return 0, errors.New("bls.SignTo: Buffer too short.")
+	}
+
+	// Draw a blinding factor.
+	var blind [signingBlindBytes]byte
+	_, err := rand.Read(blind[:])
+	if err != nil {
+		return 0, err
+	}
+
+	// Calculate h and sigma in a single call into C.
+	pairing := system.pairing
+	h := getElement(pairing, poolG1)
+	sigma := getElement(pairing, poolG1)
+	C.sign_digest(sigma, h, (*C.uchar)(unsafe.Pointer(&hash[0])), sha256.Size, secret.x.get, (*C.uchar)(unsafe.Pointer(&blind[0])), signingBlindBytes)
+
+	// Write the signature.
+	if native {
+		system.putSig(dst[:n], Element{sigma})
+	} else {
+		bytes := system.SigToBytes(Element{sigma})
+		if len(dst) < len(bytes) {
+			n = 0
+		} else {
+			n = copy(dst, bytes)
+		}
+	}
+
+	// Clean up.
+	putElement(pairing, poolG1, h)
+	putElement(pairing, poolG1, sigma)
+
+	// Return the length of the signature.
+	if n == 0 {
+		return 0, errors.New("bls.SignTo: Buffer too short.")
+	}
+	return n, nil
+
+}
+
+// Sign a message of any length using a private key. The message is hashed to
+// a digest with Digest, using the hash function and the domain separation tag
+// of the cryptosystem of the key, and the digest is signed with Sign. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func SignMessage(message []byte, secret PrivateKey) (Signature, error) {
+	hash, err := secret.system.Digest(message)
+	if err != nil {
+		return Element{}, err
+	}
+	return Sign(hash, secret), nil
+}
+
+// Map a message to a point in G1 exactly as SignMessage and VerifyMessage do.
+// The message is hashed to a digest with Digest, and the digest is mapped to
+// the point. This function allocates C structures on the C heap using malloc.
+// It is the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func HashToGroup(system System, message []byte) (Point, error) {
+	hash, err := system.Digest(message)
+	if err != nil {
+		return Element{}, err
+	}
+	return Element{system.digestToPoint(hash[:])}, nil
+}
+
+// Map a nonempty digest to a point in G1. This function allocates C structures
+// on the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func (system System) digestToPoint(digest []byte) *C.struct_element_s {
+	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(h, system.pairing.get)
+	system.hashToPoint(h, digest)
+	return h
+}
+
+// Sign a message that is already mapped to a point in G1 using a private key.
+// This function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+//
+// The private key is blinded as in Sign, and SignPoint panics if the blinding
+// factor cannot be drawn from crypto/rand.
+func SignPoint(point Point, secret PrivateKey) Signature {
+	sigma, err := signPoint(point, secret)
+	if err != nil {
+		panic(err)
+	}
+	return sigma
+}
+
+func signPoint(point Point, secret PrivateKey) (Signature, error) {
+	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(sigma, secret.system.pairing.get)
+	err := powSecret(sigma, point.get, secret.x.get)
+	if err != nil {
+		C.element_clear(sigma)
+		C.free(unsafe.Pointer(sigma))
+		return Element{}, err
+	}
+	return Element{sigma}, nil
+}
 
+// Raise an element to a secret exponent in Zr, such as a private key or a
+// nonce, adding a fresh random multiple of the group order to the exponent as
+// SignDigest does.
+func powSecret(out *C.struct_element_s, base *C.struct_element_s, x *C.struct_element_s) error {
+	var blind [signingBlindBytes]byte
+	_, err := rand.Read(blind[:])
+	if err != nil {
+		return err
+	}
+	C.pow_secret(out, base, x, (*C.uchar)(unsafe.Pointer(&blind[0])), signingBlindBytes)
+	return nil
 }
 
 // Verify a signature on the message digest using the public key of the signer.
 func Verify(signature Signature, hash [sha256.Size]byte, key PublicKey) bool {
+	return VerifyDigest(signature, hash[:], key)
+}
 
-	// Calculate the left-hand side.
-	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_GT(lhs, key.system.pairing.get)
-	C.element_pairing(lhs, signature.get, key.system.g.get)
+// Verify a signature on a message digest of any length using the public key of
+// the signer. An empty digest is rejected.
+func VerifyDigest(signature Signature, digest []byte, key PublicKey) bool {
+
+	// Check the digest length.
+	if len(digest) == 0 {
+		return false
+	}
 
 	// Calculate h.
-	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(h, key.system.pairing.get)
-	C.element_from_hash(h, unsafe.Pointer(&hash[0]), sha256.Size)
+	pairing := key.system.pairing
+	h := getElement(pairing, poolG1)
+	key.system.hashToPoint(h, digest)
 
-	// Calculate the right-hand side.
-	rhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_GT(rhs, key.system.pairing.get)
-	C.element_pairing(rhs, h, key.gx.get)
+	// Verify the signature on h.
+	result := verifyPoint(signature, Element{h}, key)
 
-	// Equate the left and right-hand side.
-	C.element_invert(rhs, rhs)
-	C.element_mul(lhs, lhs, rhs)
-	result := C.element_is1(lhs) == 1
+	// Clean up.
+	putElement(pairing, poolG1, h)
+
+	// Return the result.
+	return result
+
+}
+
+// Verify a signature on a message of any length using the public key of the
+// signer. The message is hashed as in SignMessage.
+func VerifyMessage(signature Signature, message []byte, key PublicKey) bool {
+	hash, err := key.system.Digest(message)
+	if err != nil {
+		return false
+	}
+	return Verify(signature, hash, key)
+}
+
+// Verify a signature on a message that is already mapped to a point in G1 using
+// the public key of the signer.
+func VerifyPoint(signature Signature, point Point, key PublicKey) bool {
+	if key.system.checkOnUse() && !key.system.inSubgroup(point) {
+		return false
+	}
+	return verifyPoint(signature, point, key)
+}
+
+func verifyPoint(signature Signature, point Point, key PublicKey) bool {
+
+	// Check subgroup membership.
+	if isIdentity(signature, key.gx) {
+		return false
+	}
+	if key.system.checkOnUse() {
+		if !key.system.inSubgroup(signature) || !key.system.inSubgroup(key.gx) {
+			return false
+		}
+	}
+
+	// Equate the left and right-hand side in a single call into C. If
+	// signatures are serialized without the sign of the y-coordinate, either
+	// sign is accepted.
+	pairing := key.system.pairing
+	lhs := getElement(pairing, poolGT)
+	rhs := getElement(pairing, poolGT)
+	xonly := C.int(0)
+	if key.system.encoding.Format == PointXOnly {
+		xonly = 1
+	}
+	result := C.verify_point(signature.get, key.system.g.get, key.system.gpp, point.get, key.gx.get, lhs, rhs, xonly) == 1
 
 	// Clean up.
-	C.element_clear(h)
-	C.element_clear(lhs)
-	C.element_clear(rhs)
+	putElement(pairing, poolGT, lhs)
+	putElement(pairing, poolGT, rhs)
 
 	// Return the result.
 	return result
@@ -337,19 +844,26 @@ func Aggregate(signatures []Signature, system System) (Signature, error) {
 	if len(signatures) == 0 {
 		return Element{}, errors.New("bls.Aggregate: Empty list.")
 	}
+	if !system.combinable() {
+		return Element{}, errors.New("bls.Aggregate: Signatures without a sign cannot be combined.")
+	}
+
+	// Check subgroup membership.
+	if isIdentity(signatures...) {
+		return Element{}, errors.New("bls.Aggregate: Signature is the identity.")
+	}
+	if system.checkOnUse() && !system.inSubgroup(signatures...) {
+		return Element{}, errors.New("bls.Aggregate: Signature not in subgroup.")
+	}
 
-	// Calculate sigma.
+	// Calculate sigma in a single call into C.
 	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
 	C.element_init_G1(sigma, system.pairing.get)
-	C.element_set(sigma, signatures[0].get)
-	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(t, system.pairing.get)
-	for i := 1; i < len(signatures); i++ {
-		C.element_mul(sigma, sigma, signatures[i].get)
+	elements := make([]*C.struct_element_s, len(signatures))
+	for i := range signatures {
+		elements[i] = signatures[i].get
 	}
-
-	// Clean up.
-	C.element_clear(t)
+	C.aggregate(sigma, &elements[0], C.int(len(elements)))
 
 	// Return the aggregate signature.
 	return Element{sigma}, nil
@@ -367,136 +881,645 @@ func AggregateVerify(signature Signature, hashes [][sha256.Size]byte, keys []Pub
 	if len(hashes) != len(keys) {
 		return false, errors.New("bls.AggregateVerify: List length mismatch.")
 	}
+	if !keys[0].system.combinable() {
+		return false, errors.New("bls.AggregateVerify: Signatures without a sign cannot be combined.")
+	}
 
 	// Check the uniqueness constraint.
-	if !uniqueHashes(hashes) {
+	if !UniqueHashes(hashes) {
 		return false, errors.New("bls.AggregateVerify: Message digests must be distinct.")
 	}
 
-	// Calculate the left-hand side.
-	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_GT(lhs, keys[0].system.pairing.get)
-	C.element_pairing(lhs, signature.get, keys[0].system.g.get)
+	// Check subgroup membership.
+	system := keys[0].system
+	gx := make([]Element, len(keys))
+	for i := range keys {
+		gx[i] = keys[i].gx
+	}
+	if isIdentity(signature) || isIdentity(gx...) {
+		return false, nil
+	}
+	if system.checkOnUse() {
+		if !system.inSubgroup(signature) || !system.inSubgroup(gx...) {
+			return false, nil
+		}
+	}
 
-	// Calculate the right-hand side.
+	// Allocate the inputs of the pairings. The first pair is the inverse of
+	// the signature and the system parameter, and each further pair is the
+	// product of the message digests signed by a distinct key and that key,
+	// so that one pairing is needed per key rather than one per digest.
+	pairing := keys[0].system.pairing.get
+	size := len(hashes) + 1
+	in1mem := C.malloc(C.size_t(size) * sizeOfElement)
+	in2mem := C.malloc(C.size_t(size) * sizeOfElement)
+	in1 := unsafe.Slice((*C.struct_element_s)(in1mem), size)
+	in2 := unsafe.Slice((*C.struct_element_s)(in2mem), size)
+	C.element_init_G1(&in1[0], pairing)
+	C.element_invert(&in1[0], signature.get)
+	C.element_init_G2(&in2[0], pairing)
+	C.element_set(&in2[0], system.g.get)
+
+	// Sum the message digests signed by each distinct key.
 	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(h, keys[0].system.pairing.get)
-	C.element_from_hash(h, unsafe.Pointer(&hashes[0][0]), sha256.Size)
-	rhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_GT(rhs, keys[0].system.pairing.get)
-	C.element_pairing(rhs, h, keys[0].gx.get)
-	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_GT(t, keys[0].system.pairing.get)
-	for i := 1; i < len(hashes); i++ {
-		C.element_from_hash(h, unsafe.Pointer(&hashes[i][0]), sha256.Size)
-		C.element_pairing(t, h, keys[i].gx.get)
-		C.element_mul(rhs, rhs, t)
+	C.element_init_G1(h, pairing)
+	index := make(map[string]int)
+	n := 1
+	for i := range hashes {
+		id := keys[i].gx.key()
+		j, ok := index[id]
+		if !ok {
+			j = n
+			n++
+			index[id] = j
+			C.element_init_G1(&in1[j], pairing)
+			C.element_set1(&in1[j])
+			C.element_init_G2(&in2[j], pairing)
+			C.element_set(&in2[j], keys[i].gx.get)
+		}
+		system.hashToPoint(h, hashes[i][:])
+		C.element_mul(&in1[j], &in1[j], h)
 	}
 
-	// Equate the left and right-hand side.
-	C.element_invert(rhs, rhs)
-	C.element_mul(lhs, lhs, rhs)
-	result := C.element_is1(lhs) == 1
+	// Calculate the product of the pairings, which is one if and only if the
+	// left and right-hand side are equal.
+	product := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_GT(product, pairing)
+	C.element_prod_pairing(product, (*C.element_t)(in1mem), (*C.element_t)(in2mem), C.int(n))
+	result := C.element_is1(product) == 1
 
 	// Clean up.
 	C.element_clear(h)
-	C.element_clear(lhs)
-	C.element_clear(rhs)
-	C.element_clear(t)
+	for j := 0; j < n; j++ {
+		C.element_clear(&in1[j])
+		C.element_clear(&in2[j])
+	}
+	C.free(in1mem)
+	C.free(in2mem)
+	C.element_clear(product)
 
 	// Return the result.
 	return result, nil
 
 }
 
-// Recover a threshold signature from the signature shares provided by the group
-// members using the cryptosystem. This function allocates C structures on the C
-// heap using malloc. It is the responsibility of the caller to prevent memory
-// leaks by arranging for the C structures to be freed.
-func Threshold(shares []Signature, memberIds []int, system System) (Signature, error) {
+// Verify an aggregate signature of many signers on the same message digest by
+// aggregating their public keys, which takes two pairings regardless of the
+// number of signers. An attacker who chooses its key as a function of the keys
+// of the others can forge such a signature, so each key must have been proven
+// to be held by its owner, for example by a signature on the key itself, before
+// it is used here.
+func VerifySameMessage(signature Signature, hash [sha256.Size]byte, keys []PublicKey) (bool, error) {
 
 	// Check the list length.
-	if len(shares) == 0 {
-		return Element{}, errors.New("bls.Recover: Empty list.")
+	if len(keys) == 0 {
+		return false, errors.New("bls.VerifySameMessage: Empty list.")
 	}
-	if len(shares) != len(memberIds) {
-		return Element{}, errors.New("bls.Recover: List length mismatch.")
+	if !keys[0].system.combinable() {
+		return false, errors.New("bls.VerifySameMessage: Signatures without a sign cannot be combined.")
+	}
+
+	// Check subgroup membership.
+	system := keys[0].system
+	elements := make([]Element, len(keys))
+	for i := range keys {
+		elements[i] = keys[i].gx
+	}
+	if isIdentity(elements...) {
+		return false, nil
+	}
+	if system.checkOnUse() && !system.inSubgroup(elements...) {
+		return false, nil
+	}
+
+	// Aggregate the public keys.
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	C.element_set(gx, keys[0].gx.get)
+	for i := 1; i < len(keys); i++ {
+		C.element_mul(gx, gx, keys[i].gx.get)
+	}
+
+	// Verify the signature under the aggregate key.
+	key := PublicKey{system, Element{gx}}
+	result := Verify(signature, hash, key)
+
+	// Clean up.
+	key.Free()
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Calculate the Lagrange coefficients used to interpolate a threshold
+// signature or key from the shares of the given group members. The coefficients
+// are reduced modulo the group order of the cryptosystem.
+func LagrangeCoefficients(memberIds []int, system System) ([]*big.Int, error) {
+	err := CheckMemberIds(memberIds, 1)
+	if err != nil {
+		return nil, err
+	}
+	return LagrangeCoefficientsAt(memberPoints(memberIds), system)
+}
+
+// Check that the member identifiers are non-negative and distinct, and that
+// there are at least t of them. Interpolation detects duplicates, but cannot
+// detect that fewer than t shares were provided, in which case it yields an
+// invalid signature or key rather than an error, so a combiner that knows the
+// threshold should check the identifiers with this function first.
+func CheckMemberIds(memberIds []int, t int) error {
+	if len(memberIds) < t {
+		return fmt.Errorf("bls.CheckMemberIds: Need at least %d member identifiers, got %d.", t, len(memberIds))
+	}
+	seen := make(map[int]bool, len(memberIds))
+	for _, id := range memberIds {
+		if id < 0 || id == math.MaxInt64 {
+			return fmt.Errorf("bls.CheckMemberIds: Member identifier %d out of range.", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("bls.CheckMemberIds: Duplicate member identifier %d.", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// Calculate the Lagrange coefficients used to interpolate a threshold
+// signature or key from the shares at the given evaluation points. The points
+// must be nonzero and distinct modulo the group order. The coefficients are
+// reduced modulo the group order of the cryptosystem.
+func LagrangeCoefficientsAt(points []*big.Int, system System) ([]*big.Int, error) {
+	return lagrangeCoefficients(points, big.NewInt(0), system)
+}
+
+// Calculate the Lagrange coefficients that interpolate the value at x of a
+// polynomial from its values at the given points.
+func lagrangeCoefficients(points []*big.Int, x *big.Int, system System) ([]*big.Int, error) {
+
+	// Check the list length.
+	if len(points) == 0 {
+		return nil, errors.New("bls.LagrangeCoefficients: Empty list.")
 	}
 
 	// Determine the group order.
-	n := (C.mpz_sizeinbase(&system.pairing.get.r[0], 2) + 7) / 8
-	bytes := make([]byte, n)
-	C.mpz_export(unsafe.Pointer(&bytes[0]), &n, 1, 1, 1, 0, &system.pairing.get.r[0])
-	r := big.NewInt(0).SetBytes(bytes)
+	r := system.order()
 
-	// Calculate sigma.
-	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(sigma, system.pairing.get)
-	C.element_set1(sigma)
+	// Calculate lambda for each member.
+	coeffs := make([]*big.Int, len(points))
 	var p *big.Int
 	var q *big.Int
 	u := big.NewInt(0)
 	v := big.NewInt(0)
-	var lambda C.mpz_t
-	C.mpz_init(&lambda[0])
-	s := (*C.struct_element_s)(C.malloc(sizeOfElement))
-	C.element_init_G1(s, system.pairing.get)
-	for i := range memberIds {
-
-		// Calculate lambda.
+	for i := range points {
 		p = big.NewInt(1)
 		q = big.NewInt(1)
-		for j := range memberIds {
-			if memberIds[i] != memberIds[j] {
-				p.Mul(p, u.Neg(big.NewInt(int64(memberIds[j]+1))))
-				q.Mul(q, v.Sub(big.NewInt(int64(memberIds[i]+1)), big.NewInt(int64(memberIds[j]+1))))
+		for j := range points {
+			if i == j {
+				continue
+			}
+			if v.Sub(points[i], points[j]).Mod(v, r).Sign() == 0 {
+				return nil, errors.New("bls.LagrangeCoefficients: Member identifiers must be distinct.")
 			}
+			p.Mul(p, u.Sub(x, points[j]))
+			q.Mul(q, v.Sub(points[i], points[j]))
 		}
-		bytes = u.Mod(u.Mul(u.Mod(p, r), v.Mod(v.ModInverse(q, r), r)), r).Bytes()
-		if len(bytes) == 0 {
-			C.mpz_set_si(&lambda[0], 0)
-		} else {
-			C.mpz_import(&lambda[0], C.size_t(len(bytes)), 1, 1, 1, 0, unsafe.Pointer(&bytes[0]))
+		if v.ModInverse(q, r) == nil {
+			return nil, errors.New("bls.LagrangeCoefficients: Member identifiers are not invertible.")
+		}
+		coeffs[i] = big.NewInt(0).Mod(u.Mul(u.Mod(p, r), v.Mod(v, r)), r)
+	}
+
+	// Return the coefficients.
+	return coeffs, nil
+
+}
+
+// Derive the evaluation point of a group member from an arbitrary identifier,
+// such as the identifier of its node, by hashing it into the nonzero elements
+// of Zr.
+func MemberPoint(id []byte, system System) *big.Int {
+	r := system.order()
+	rm1 := big.NewInt(0).Sub(r, big.NewInt(1))
+	hash := sha256.Sum256(append([]byte("go-bls member"), id...))
+	x := big.NewInt(0).SetBytes(hash[:])
+	for i := 0; i < r.BitLen()/(8*sha256.Size); i++ {
+		hash = sha256.Sum256(hash[:])
+		x.Lsh(x, 8*sha256.Size).Or(x, big.NewInt(0).SetBytes(hash[:]))
+	}
+	return x.Mod(x, rm1).Add(x, big.NewInt(1))
+}
+
+// Derive the evaluation points of group members from their long-term public
+// keys, so that nodes that know the same keys agree on the points without
+// coordinating. The point of a key is MemberPoint of its compressed encoding,
+// and the points are at the same indices as the keys. The keys must be
+// distinct.
+func MemberPointsFromKeys(keys []PublicKey) ([]*big.Int, error) {
+
+	// Check the list length.
+	if len(keys) == 0 {
+		return nil, errors.New("bls.MemberPointsFromKeys: Empty list.")
+	}
+
+	// Derive the points.
+	points := make([]*big.Int, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for i := range keys {
+		bytes := keys[i].ToBytes()
+		if seen[string(bytes)] {
+			return nil, errors.New("bls.MemberPointsFromKeys: Public keys must be distinct.")
 		}
+		seen[string(bytes)] = true
+		points[i] = MemberPoint(bytes, keys[i].system)
+	}
+
+	// Return the points.
+	return points, nil
+
+}
+
+// Convert the member identifiers 0, 1, ... to their evaluation points 1, 2, ...
+func memberPoints(memberIds []int) []*big.Int {
+	points := make([]*big.Int, len(memberIds))
+	for i := range memberIds {
+		points[i] = big.NewInt(int64(memberIds[i]) + 1)
+	}
+	return points
+}
 
-		// Update the accumulator.
-		C.element_pow_mpz(s, shares[i].get, &lambda[0])
-		C.element_mul(sigma, sigma, s)
+// Calculate the Lagrange coefficients used to interpolate a threshold
+// signature or key from the shares of the given group members as elements of
+// Zr. This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging for the
+// C structures to be freed.
+func LagrangeCoefficientElements(memberIds []int, system System) ([]Element, error) {
 
+	// Calculate the coefficients.
+	coeffs, err := LagrangeCoefficients(memberIds, system)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert the coefficients to elements.
+	elements := make([]Element, len(coeffs))
+	var lambda C.mpz_t
+	C.mpz_init(&lambda[0])
+	for i := range coeffs {
+		setMpz(&lambda[0], coeffs[i])
+		elements[i].get = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_Zr(elements[i].get, system.pairing.get)
+		C.element_set_mpz(elements[i].get, &lambda[0])
 	}
 
 	// Clean up.
-	C.element_clear(s)
 	C.mpz_clear(&lambda[0])
 
+	// Return the elements.
+	return elements, nil
+
+}
+
+// Verify a signature share on the message digest using the public key share of
+// the member that provided it. Threshold does not check the shares, and a
+// single invalid share yields an invalid threshold signature, so a combiner
+// should filter the shares with this function first.
+func VerifyShare(share Signature, hash [sha256.Size]byte, memberKey PublicKey) bool {
+	return Verify(share, hash, memberKey)
+}
+
+// Recover a threshold signature from the signature shares provided by the group
+// members using the cryptosystem. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func Threshold(shares []Signature, memberIds []int, system System) (Signature, error) {
+	err := CheckMemberIds(memberIds, 1)
+	if err != nil {
+		return Element{}, err
+	}
+	return ThresholdAt(shares, memberPoints(memberIds), system)
+}
+
+// Recover a threshold signature from the signature shares at the given
+// evaluation points, as generated by GenKeySharesAt. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func ThresholdAt(shares []Signature, points []*big.Int, system System) (Signature, error) {
+
+	// Check the list length.
+	if len(shares) == 0 {
+		return Element{}, errors.New("bls.Recover: Empty list.")
+	}
+	if len(shares) != len(points) {
+		return Element{}, errors.New("bls.Recover: List length mismatch.")
+	}
+	if !system.combinable() {
+		return Element{}, errors.New("bls.Recover: Signatures without a sign cannot be combined.")
+	}
+
+	// Calculate the Lagrange coefficients.
+	coeffs, err := LagrangeCoefficientsAt(points, system)
+	if err != nil {
+		return Element{}, err
+	}
+
 	// Return the threshold signature.
-	return Element{sigma}, nil
+	return interpolateShares(shares, coeffs, system), nil
+
+}
+
+// Interpolate a threshold signature from the signature shares weighted by the
+// Lagrange coefficients.
+func interpolateShares(shares []Signature, coeffs []*big.Int, system System) Signature {
+
+	// Split the shares among the workers. Small sets are not worth the
+	// overhead of the goroutines.
+	workers := runtime.GOMAXPROCS(0)
+	if len(shares) < minParallelShares || workers < 2 {
+		workers = 1
+	}
+	if workers > len(shares) {
+		workers = len(shares)
+	}
+	chunk := (len(shares) + workers - 1) / workers
+
+	// Calculate the partial products, each with its own scratch space.
+	partials := make([]*C.struct_element_s, workers)
+	var wg sync.WaitGroup
+	for w := range partials {
+		partials[w] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G1(partials[w], system.pairing.get)
+		lo := w * chunk
+		hi := lo + chunk
+		if hi > len(shares) {
+			hi = len(shares)
+		}
+		wg.Add(1)
+		go func(sigma *C.struct_element_s, lo, hi int) {
+			defer wg.Done()
+			interpolateRange(sigma, shares[lo:hi], coeffs[lo:hi])
+		}(partials[w], lo, hi)
+	}
+	wg.Wait()
+
+	// Combine the partial products.
+	sigma := partials[0]
+	for _, partial := range partials[1:] {
+		C.element_mul(sigma, sigma, partial)
+		C.element_clear(partial)
+	}
+
+	// Return the threshold signature.
+	return Element{sigma}
+
+}
+
+// The number of shares below which interpolateShares uses a single goroutine.
+const minParallelShares = 16
+
+// Set sigma to the product of the shares raised to the coefficients.
+func interpolateRange(sigma *C.struct_element_s, shares []Signature, coeffs []*big.Int) {
+	bases := make([]*C.struct_element_s, len(shares))
+	for i := range shares {
+		bases[i] = shares[i].get
+	}
+	multiExp(sigma, bases, coeffs)
+}
+
+// Recover the group public key from the public key shares of t group members
+// by Lagrange interpolation in G2, so that a verifier who only knows the member
+// keys can reconstruct the group key. This function allocates C structures on
+// the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func RecoverPublicKey(memberKeys []PublicKey, memberIds []int) (PublicKey, error) {
+
+	// Check the list length.
+	if len(memberKeys) == 0 {
+		return PublicKey{}, errors.New("bls.RecoverPublicKey: Empty list.")
+	}
+	if len(memberKeys) != len(memberIds) {
+		return PublicKey{}, errors.New("bls.RecoverPublicKey: List length mismatch.")
+	}
+
+	// Calculate the Lagrange coefficients.
+	system := memberKeys[0].system
+	coeffs, err := LagrangeCoefficients(memberIds, system)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	// Return the group public key.
+	return PublicKey{system, Element{evalCommitments(memberKeys, coeffs)}}, nil
 
 }
 
+// Recover the private key from the private key shares of t group members by
+// Lagrange interpolation. Protocols such as resharing apply this to shares of
+// shares, and never to the shares of the group key itself, which would defeat
+// the purpose of the sharing. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func RecoverPrivateKey(secrets []PrivateKey, memberIds []int) (PrivateKey, error) {
+
+	// Check the list length.
+	if len(secrets) == 0 {
+		return PrivateKey{}, errors.New("bls.RecoverPrivateKey: Empty list.")
+	}
+	if len(secrets) != len(memberIds) {
+		return PrivateKey{}, errors.New("bls.RecoverPrivateKey: List length mismatch.")
+	}
+
+	// Calculate the Lagrange coefficients.
+	system := secrets[0].system
+	coeffs, err := LagrangeCoefficients(memberIds, system)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+
+	// Calculate the weighted sum of the shares.
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	C.element_set0(x)
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(t, system)
+	var lambda C.mpz_t
+	C.mpz_init(&lambda[0])
+	for i := range secrets {
+		setMpz(&lambda[0], coeffs[i])
+		C.element_mul_mpz(t, secrets[i].x.get, &lambda[0])
+		C.element_add(x, x, t)
+	}
+
+	// Clean up.
+	C.mpz_clear(&lambda[0])
+	wipe(t)
+	C.element_clear(t)
+
+	// Return the private key.
+	return PrivateKey{system, Element{x}}, nil
+
+}
+
+// Determine the group order of the cryptosystem.
+func (system System) order() *big.Int {
+	return getMpz(&system.pairing.get.r[0])
+}
+
+// Convert a non-negative GMP integer to a big integer.
+func getMpz(z *C.__mpz_struct) *big.Int {
+	n := (C.mpz_sizeinbase(z, 2) + 7) / 8
+	bytes := make([]byte, n)
+	C.mpz_export(unsafe.Pointer(&bytes[0]), &n, 1, 1, 1, 0, z)
+	return big.NewInt(0).SetBytes(bytes[:n])
+}
+
+// Set a GMP integer to the value of a non-negative big integer.
+func setMpz(z *C.__mpz_struct, x *big.Int) {
+	bytes := x.Bytes()
+	if len(bytes) == 0 {
+		C.mpz_set_si(z, 0)
+	} else {
+		C.mpz_import(z, C.size_t(len(bytes)), 1, 1, 1, 0, unsafe.Pointer(&bytes[0]))
+	}
+}
+
 // Convert a signature to a byte slice.
 func (system System) SigToBytes(signature Signature) []byte {
-	n := int(C.pairing_length_in_bytes_compressed_G1(system.pairing.get))
+	n := system.sigLength()
 	if n < 1 {
 		return nil
 	}
 	bytes := make([]byte, n)
-	C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&bytes[0])), signature.get)
-	return bytes
+	system.putSig(bytes, signature)
+	return system.encoding.encode(bytes, system.encoding.g1, system.encoding.Format)
+}
+
+// Write a signature in the native format of the cryptosystem to a byte slice of
+// length sigLength.
+func (system System) putSig(bytes []byte, signature Signature) {
+	switch system.encoding.Format {
+	case PointUncompressed:
+		C.element_to_bytes((*C.uchar)(unsafe.Pointer(&bytes[0])), signature.get)
+	case PointXOnly:
+		C.element_to_bytes_x_only((*C.uchar)(unsafe.Pointer(&bytes[0])), signature.get)
+	default:
+		C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&bytes[0])), signature.get)
+	}
 }
 
 // Convert a byte slice to a signature.
 func (system System) SigFromBytes(bytes []byte) (Signature, error) {
-	n := int(C.pairing_length_in_bytes_compressed_G1(system.pairing.get))
-	if n != len(bytes) {
+	bytes, err := system.encoding.decode(bytes, system.encoding.g1, system.encoding.Format)
+	if err != nil {
+		return Element{}, err
+	}
+	if system.sigLength() != len(bytes) {
 		return Element{}, errors.New("bls.FromBytes: Signature length mismatch.")
 	}
 	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
 	C.element_init_G1(sigma, system.pairing.get)
-	C.element_from_bytes_compressed(sigma, (*C.uchar)(unsafe.Pointer(&bytes[0])))
+	if !elementFromBytes(sigma, bytes, system.encoding.Format) {
+		C.element_clear(sigma)
+		return Element{}, errors.New("bls.FromBytes: Failed to decode signature.")
+	}
+	if !onCurve(sigma, bytes, system.encoding.Format) {
+		C.element_clear(sigma)
+		return Element{}, errors.New("bls.FromBytes: Signature not on curve.")
+	}
+	if isIdentity(Element{sigma}) {
+		C.element_clear(sigma)
+		return Element{}, errors.New("bls.FromBytes: Signature is the identity.")
+	}
+	if system.checkOnDeserialize() && !system.inSubgroup(Element{sigma}) {
+		C.element_clear(sigma)
+		return Element{}, errors.New("bls.FromBytes: Signature not in subgroup.")
+	}
 	return Element{sigma}, nil
 }
 
+// Decode an element from the byte slice in the given point format, which is
+// PointUncompressed for elements other than points, and check that PBC consumed
+// exactly the whole slice. Elements in the plain encoding must also re-encode
+// to the same bytes, since PBC silently reduces values that exceed their
+// modulus.
+func elementFromBytes(e *C.struct_element_s, data []byte, format PointFormat) bool {
+	ptr := (*C.uchar)(unsafe.Pointer(&data[0]))
+	var n C.int
+	switch format {
+	case PointUncompressed:
+		n = C.element_from_bytes(e, ptr)
+	case PointXOnly:
+		n = C.element_from_bytes_x_only(e, ptr)
+	default:
+		n = C.element_from_bytes_compressed(e, ptr)
+	}
+	if int(n) != len(data) {
+		return false
+	}
+	if format != PointUncompressed {
+		return true
+	}
+	encoded := make([]byte, len(data))
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&encoded[0])), e)
+	return subtle.ConstantTimeCompare(encoded, data) == 1
+}
+
+// Determine the length of a signature in the native encoding of the point
+// format of the cryptosystem.
+func (system System) sigLength() int {
+	switch system.encoding.Format {
+	case PointUncompressed:
+		return int(C.pairing_length_in_bytes_G1(system.pairing.get))
+	case PointXOnly:
+		return int(C.pairing_length_in_bytes_x_only_G1(system.pairing.get))
+	default:
+		return int(C.pairing_length_in_bytes_compressed_G1(system.pairing.get))
+	}
+}
+
+// Get the length of a signature in the encoding of the cryptosystem.
+func (system System) encodedSigLength() int {
+	n := system.sigLength()
+	if n < 1 {
+		return 0
+	}
+	return len(system.encoding.encode(make([]byte, n), system.encoding.g1, system.encoding.Format))
+}
+
+// Encode the element as a string suitable for use as a map key.
+func (element Element) key() string {
+	n := int(C.element_length_in_bytes(element.get))
+	bytes := make([]byte, n)
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&bytes[0])), element.get)
+	return string(bytes)
+}
+
+// Report whether two elements are equal. Elements of different groups are not
+// equal.
+func (element Element) Equal(other Element) bool {
+	return element.get.field == other.get.field && C.element_cmp(element.get, other.get) == 0
+}
+
+// Report whether two public keys are equal. Keys of different cryptosystems
+// are not equal.
+func (key PublicKey) Equal(other PublicKey) bool {
+	return key.system.g.Equal(other.system.g) && key.gx.Equal(other.gx)
+}
+
+// Report whether two private keys are equal. Keys of different cryptosystems
+// are not equal. The comparison of the scalars takes constant time.
+func (secret PrivateKey) Equal(other PrivateKey) bool {
+	if !secret.system.g.Equal(other.system.g) {
+		return false
+	}
+	a := secret.ToBytes()
+	b := other.ToBytes()
+	result := subtle.ConstantTimeCompare(a, b) == 1
+	clear(a)
+	clear(b)
+	return result
+}
+
 // Free the memory occupied by the element. The element cannot be used after
 // calling this function.
 func (element Element) Free() {
@@ -512,6 +1535,7 @@ func (params Params) Free() {
 // Free the memory occupied by the pairing. The pairing cannot be used after
 // calling this function.
 func (pairing Pairing) Free() {
+	drainElements(pairing)
 	C.pairing_clear(pairing.get)
 }
 
@@ -531,20 +1555,62 @@ func (params Params) ToBytes() ([]byte, error) {
 	return C.GoBytes(unsafe.Pointer(buf), C.int(size)), nil
 }
 
+// Pairing returns the pairing on which the cryptosystem is built.
+func (system System) Pairing() Pairing {
+	return system.pairing
+}
+
+// String exports Params in the standard PBC parameter format, so that
+// parameters generated on one machine can be reused on another without
+// regenerating curves.
+func (params Params) String() string {
+	bytes, _ := params.ToBytes()
+	return string(bytes)
+}
+
 // Free the memory occupied by the cryptosystem. The cryptosystem cannot be used
 // after calling this function.
 func (system System) Free() {
+	if system.points != nil {
+		system.points.free()
+	}
+	if system.gpp != nil {
+		C.pairing_pp_clear(system.gpp)
+		C.free(unsafe.Pointer(system.gpp))
+	}
 	system.g.Free()
 }
 
-// ToBytes exports the System to a byte slice.
+// ToBytes exports the System to a byte slice. The data contains the pairing
+// parameters, the system parameter, the length of an encoded signature, the
+// hash function, the domain separation tag, the encoding, and the subgroup
+// check policy, each prefixed with its length as a 32-bit big-endian integer,
+// so that verifiers can rebuild the whole cryptosystem.
 func (system System) ToBytes() []byte {
 	n := int(C.pairing_length_in_bytes_compressed_G2(system.pairing.get))
 	if n < 1 {
 		return nil
 	}
-	bytes := make([]byte, n)
-	C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&bytes[0])), system.g.get)
+	g := make([]byte, n)
+	C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&g[0])), system.g.get)
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(system.encodedSigLength()))
+	hash := make([]byte, 4)
+	binary.BigEndian.PutUint32(hash, uint32(system.hash))
+	enc := make([]byte, 16)
+	if system.encoding.LittleEndian {
+		enc[3] = 1
+	}
+	binary.BigEndian.PutUint32(enc[4:], uint32(system.encoding.SignBit))
+	binary.BigEndian.PutUint32(enc[8:], uint32(system.encoding.FieldSize))
+	binary.BigEndian.PutUint32(enc[12:], uint32(system.encoding.Format))
+	subgroup := make([]byte, 4)
+	binary.BigEndian.PutUint32(subgroup, uint32(system.subgroup))
+	var bytes []byte
+	for _, field := range [][]byte{[]byte(system.pairing.params), g, length, hash, system.dst, enc, subgroup} {
+		bytes = binary.BigEndian.AppendUint32(bytes, uint32(len(field)))
+		bytes = append(bytes, field...)
+	}
 	return bytes
 }
 
@@ -554,8 +1620,145 @@ func (key PublicKey) Free() {
 	key.gx.Free()
 }
 
+// ToBytes exports the public key to a byte slice.
+func (key PublicKey) ToBytes() []byte {
+	n := int(C.pairing_length_in_bytes_compressed_G2(key.system.pairing.get))
+	if n < 1 {
+		return nil
+	}
+	bytes := make([]byte, n)
+	C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&bytes[0])), key.gx.get)
+	return key.system.encoding.encode(bytes, key.system.encoding.g2, PointCompressed)
+}
+
+// PublicKeyFromBytes imports a public key from the provided byte slice.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PublicKeyFromBytes(system System, bytes []byte) (PublicKey, error) {
+	bytes, err := system.encoding.decode(bytes, system.encoding.g2, PointCompressed)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	n := int(C.pairing_length_in_bytes_compressed_G2(system.pairing.get))
+	if n != len(bytes) {
+		return PublicKey{}, errors.New("bls.FromBytes: Public key length mismatch.")
+	}
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	if !elementFromBytes(gx, bytes, PointCompressed) {
+		C.element_clear(gx)
+		return PublicKey{}, errors.New("bls.FromBytes: Failed to decode public key.")
+	}
+	if !onCurve(gx, bytes, PointCompressed) {
+		C.element_clear(gx)
+		return PublicKey{}, errors.New("bls.FromBytes: Public key not on curve.")
+	}
+	if isIdentity(Element{gx}) {
+		C.element_clear(gx)
+		return PublicKey{}, errors.New("bls.FromBytes: Public key is the identity.")
+	}
+	if system.checkOnDeserialize() && !system.inSubgroup(Element{gx}) {
+		C.element_clear(gx)
+		return PublicKey{}, errors.New("bls.FromBytes: Public key not in subgroup.")
+	}
+	return PublicKey{system, Element{gx}}, nil
+}
+
+// ToBytes exports the private key to a byte slice.
+func (secret PrivateKey) ToBytes() []byte {
+	n := int(C.pairing_length_in_bytes_Zr(secret.system.pairing.get))
+	if n < 1 {
+		return nil
+	}
+	bytes := make([]byte, n)
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&bytes[0])), secret.x.get)
+	return bytes
+}
+
+// PrivateKeyFromBytes imports a private key from the provided byte slice.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PrivateKeyFromBytes(system System, bytes []byte) (PrivateKey, error) {
+	n := int(C.pairing_length_in_bytes_Zr(system.pairing.get))
+	if n != len(bytes) {
+		return PrivateKey{}, errors.New("bls.FromBytes: Private key length mismatch.")
+	}
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	if !elementFromBytes(x, bytes, PointUncompressed) {
+		C.element_clear(x)
+		return PrivateKey{}, errors.New("bls.FromBytes: Failed to decode private key.")
+	}
+	if C.element_is0(x) == 1 {
+		C.element_clear(x)
+		return PrivateKey{}, errors.New("bls.FromBytes: Private key is zero.")
+	}
+	return PrivateKey{system, Element{x}}, nil
+}
+
+// ScalarBytes exports the private key as a big-endian integer padded to the
+// byte length of the group order.
+func (secret PrivateKey) ScalarBytes() []byte {
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	C.element_to_mpz(&z[0], secret.x.get)
+	x := getMpz(&z[0])
+	C.mpz_clear(&z[0])
+	bytes := x.FillBytes(make([]byte, (secret.system.order().BitLen()+7)/8))
+	x.SetInt64(0)
+	return bytes
+}
+
+// PrivateKeyFromScalar imports a private key from a big-endian integer padded
+// to the byte length of the group order, as exported by ScalarBytes and by
+// other BLS libraries. The integer must lie in the range [1, r-1], where r is
+// the group order.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PrivateKeyFromScalar(system System, bytes []byte) (PrivateKey, error) {
+	r := system.order()
+	if len(bytes) != (r.BitLen()+7)/8 {
+		return PrivateKey{}, errors.New("bls.PrivateKeyFromScalar: Scalar length mismatch.")
+	}
+	x := big.NewInt(0).SetBytes(bytes)
+	defer x.SetInt64(0)
+	if x.Sign() == 0 || x.Cmp(r) >= 0 {
+		return PrivateKey{}, errors.New("bls.PrivateKeyFromScalar: Scalar out of range.")
+	}
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	setMpz(&z[0], x)
+	e := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(e, system)
+	C.element_set_mpz(e, &z[0])
+	C.mpz_clear(&z[0])
+	return PrivateKey{system, Element{e}}, nil
+}
+
 // Free the memory occupied by the private key. The private key cannot be used
 // after calling this function.
 func (secret PrivateKey) Free() {
 	secret.x.Free()
 }
+
+// Overwrite the private key and free the memory it occupies. Free releases the
+// memory without overwriting it, which leaves the scalar readable until the
+// memory is reused. The private key cannot be used after calling this function.
+func (secret PrivateKey) Zeroize() {
+	wipe(secret.x.get)
+	C.element_clear(secret.x.get)
+}
+
+// Overwrite an element in place, first with a random value and then with zero.
+// Setting an element to zero alone may only reset its size or a flag, while
+// assigning a value of full size overwrites the limbs holding the old value.
+func wipe(element *C.struct_element_s) {
+	C.element_random(element)
+	C.element_set0(element)
+}
@@ -11,7 +11,10 @@
 package bls
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
 	"math/rand"
 	"testing"
 	"time"
@@ -52,6 +55,279 @@ func TestSignVerify(test *testing.T) {
 
 }
 
+func TestSignTo(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message into a buffer and compare it with Sign.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+	expected := system.SigToBytes(signature)
+	buffer := make([]byte, len(expected)+8)
+	n, err := SignTo(buffer, hash, secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bytes.Equal(buffer[:n], expected) {
+		test.Fatal("Signature written to the buffer does not match.")
+	}
+
+	// Reject a buffer that is too short.
+	_, err = SignTo(buffer[:len(expected)-1], hash, secret)
+	if err == nil {
+		test.Fatal("Failed to reject a short buffer.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSignBlinded(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Calculate the unblinded signature.
+	hash, err := system.Digest(message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	point, err := HashToGroup(system, message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	x := new(big.Int).SetBytes(secret.ScalarBytes())
+	expected := interpolateShares([]Signature{point}, []*big.Int{x}, system)
+	if !Verify(expected, hash, key) {
+		test.Fatal("Failed to verify unblinded signature.")
+	}
+
+	// The public key must be derived from the blinded private key consistently.
+	public := secret.Public()
+	if !public.Equal(key) {
+		test.Fatal("Derived public key does not match.")
+	}
+	public.Free()
+
+	// Every blinded signing path must verify and match the unblinded signature,
+	// however many times it is repeated.
+	table := NewPointTable(hash, system)
+	backend := LocalBackend(key, secret)
+	for i := 0; i < 4; i++ {
+		signatures := []Signature{Sign(hash, secret), SignPoint(point, secret), table.Sign(secret)}
+		signature, err := SignWithBackend(hash, backend)
+		if err != nil {
+			test.Fatal(err)
+		}
+		signatures = append(signatures, signature)
+		signature, proof := SignWithProof(hash, secret)
+		if !VerifyShareProof(signature, hash, key, proof) {
+			test.Fatal("Failed to verify proof.")
+		}
+		signatures = append(signatures, signature)
+		for j, signature := range signatures {
+			if !Verify(signature, hash, key) {
+				test.Fatalf("Failed to verify signature %d.", j)
+			}
+			if !signature.Equal(expected) {
+				test.Fatalf("Signature %d does not match the unblinded signature.", j)
+			}
+			signature.Free()
+		}
+		proof.Free()
+		buffer := make([]byte, len(system.SigToBytes(expected)))
+		_, err = SignTo(buffer, hash, secret)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !bytes.Equal(buffer, system.SigToBytes(expected)) {
+			test.Fatal("Signature written to the buffer does not match the unblinded signature.")
+		}
+	}
+
+	// Clean up.
+	table.Free()
+	expected.Free()
+	point.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSignVerifyPoint(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Any element of G1 can serve as a pre-mapped message.
+	hash := sha256.Sum256([]byte(message))
+	point := Sign(hash, secret)
+
+	// Sign and verify the point.
+	signature := SignPoint(point, secret)
+	if !VerifyPoint(signature, point, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	if VerifyPoint(signature, signature, key) {
+		test.Fatal("Verified signature on wrong point.")
+	}
+
+	// Clean up.
+	signature.Free()
+	point.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSignVerifyMessage(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify the message.
+	signature, err := SignMessage(message, secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !VerifyMessage(signature, message, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	if VerifyMessage(signature, message[1:], key) {
+		test.Fatal("Verified signature on wrong message.")
+	}
+
+	// The message is hashed with Digest.
+	hash, err := system.Digest(message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature on digest.")
+	}
+
+	// The message is mapped with HashToGroup.
+	point, err := HashToGroup(system, message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !VerifyPoint(signature, point, key) {
+		test.Fatal("Failed to verify signature on point.")
+	}
+	point.Free()
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSignVerifyDigest(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify a 64-byte digest.
+	digest := sha512.Sum512(message)
+	signature, err := SignDigest(digest[:], secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !VerifyDigest(signature, digest[:], key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	if VerifyDigest(signature, digest[:32], key) {
+		test.Fatal("Verified signature on truncated digest.")
+	}
+
+	// Empty digests must be rejected.
+	_, err = SignDigest(nil, secret)
+	if err == nil {
+		test.Fatal("Signed an empty digest.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
 func TestAggregateVerify(test *testing.T) {
 
 	messages := []string{
@@ -60,123 +336,815 @@ func TestAggregateVerify(test *testing.T) {
 		"This is yet another message.",
 		"These messages are unique.",
 	}
-	n := len(messages)
+	n := len(messages)
+
+	// Generate key pairs.
+	params, err := GenParamsTypeD(9563, 512)
+	if err != nil {
+		test.Fatal(err)
+	}
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, n)
+	secrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Sign the messages.
+	hashes := make([][sha256.Size]byte, n)
+	signatures := make([]Signature, n)
+	for i := 0; i < n; i++ {
+		hashes[i] = sha256.Sum256([]byte(messages[i]))
+		signatures[i] = Sign(hashes[i], secrets[i])
+	}
+
+	// Aggregate the signatures.
+	aggregate, err := Aggregate(signatures, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the aggregate signature.
+	valid, err := AggregateVerify(aggregate, hashes, keys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify aggregate signature.")
+	}
+
+	// Clean up.
+	aggregate.Free()
+	for i := 0; i < n; i++ {
+		signatures[i].Free()
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestVerifySameMessage(test *testing.T) {
+
+	n := 5
+	message := "This is a message."
+
+	// Generate the key pairs.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, n)
+	secrets := make([]PrivateKey, n)
+	for i := range keys {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Sign the message with every key and aggregate the signatures.
+	hash := sha256.Sum256([]byte(message))
+	signatures := make([]Signature, n)
+	for i := range signatures {
+		signatures[i] = Sign(hash, secrets[i])
+	}
+	aggregate, err := Aggregate(signatures, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the aggregate signature.
+	valid, err := VerifySameMessage(aggregate, hash, keys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify aggregate signature.")
+	}
+
+	// A missing signer must be detected.
+	valid, err = VerifySameMessage(aggregate, hash, keys[1:])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified aggregate signature with a missing signer.")
+	}
+
+	// Clean up.
+	aggregate.Free()
+	for i := range keys {
+		signatures[i].Free()
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestAggregateVerifyRepeatedKeys(test *testing.T) {
+
+	messages := []string{
+		"This is a message.",
+		"This is another message.",
+		"This is yet another message.",
+		"These messages are unique.",
+	}
+	n := len(messages)
+
+	// Generate two key pairs.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, 2)
+	secrets := make([]PrivateKey, 2)
+	for i := range keys {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Sign the messages, alternating between the keys.
+	hashes := make([][sha256.Size]byte, n)
+	signers := make([]PublicKey, n)
+	signatures := make([]Signature, n)
+	for i := 0; i < n; i++ {
+		hashes[i] = sha256.Sum256([]byte(messages[i]))
+		signers[i] = keys[i%2]
+		signatures[i] = Sign(hashes[i], secrets[i%2])
+	}
+
+	// Aggregate the signatures.
+	aggregate, err := Aggregate(signatures, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the aggregate signature.
+	valid, err := AggregateVerify(aggregate, hashes, signers)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify aggregate signature.")
+	}
+
+	// Attributing a message to the wrong signer must fail.
+	signers[0], signers[1] = signers[1], signers[0]
+	valid, err = AggregateVerify(aggregate, hashes, signers)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified aggregate signature with wrong signers.")
+	}
+
+	// Clean up.
+	aggregate.Free()
+	for i := 0; i < n; i++ {
+		signatures[i].Free()
+	}
+	for i := range keys {
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestThresholdSignature(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate key shares.
+	params := GenParamsTypeF(256)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	rand.Seed(time.Now().UnixNano())
+	n := rand.Intn(20) + 1
+	t := rand.Intn(n) + 1
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Check the shares against the commitments.
+	valid, err := VerifySecretShares(commitments, identityIds(n), memberSecrets)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Key shares do not match the commitments.")
+	}
+	if !commitments[0].Equal(groupKey) {
+		test.Fatal("Commitment to the constant term is not the group key.")
+	}
+
+	// Select group members.
+	memberIds := rand.Perm(n)[:t]
+
+	// Sign the message.
+	hash := sha256.Sum256([]byte(message))
+	shares := make([]Signature, t)
+	for i := 0; i < t; i++ {
+		shares[i] = Sign(hash, memberSecrets[memberIds[i]])
+	}
+
+	// Verify the signature shares.
+	for i := 0; i < t; i++ {
+		if !VerifyShare(shares[i], hash, memberKeys[memberIds[i]]) {
+			test.Fatal("Failed to verify signature share.")
+		}
+		if n > 1 && VerifyShare(shares[i], hash, memberKeys[(memberIds[i]+1)%n]) {
+			test.Fatal("Verified signature share under another member key.")
+		}
+	}
+
+	// Recover the threshold signature.
+	signature, err := Threshold(shares, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the threshold signature.
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Recover the group public key from the member keys.
+	keys := make([]PublicKey, t)
+	for i := 0; i < t; i++ {
+		keys[i] = memberKeys[memberIds[i]]
+	}
+	recovered, err := RecoverPublicKey(keys, memberIds)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !recovered.Equal(groupKey) {
+		test.Fatal("Recovered public key does not match the group key.")
+	}
+	recovered.Free()
+
+	// Clean up.
+	signature.Free()
+	groupKey.Free()
+	groupSecret.Free()
+	for i := 0; i < t; i++ {
+		shares[i].Free()
+		commitments[i].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestThresholdSignatureAt(test *testing.T) {
+
+	t := 3
+	message := "This is a message."
+
+	// Generate key shares for members with arbitrary identifiers.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	nodes := []string{"node-alpha", "node-bravo", "node-charlie", "node-delta", "node-echo"}
+	points := make([]*big.Int, len(nodes))
+	for i := range nodes {
+		points[i] = MemberPoint([]byte(nodes[i]), system)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeySharesAt(t, points, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message with three members and recover the signature.
+	hash := sha256.Sum256([]byte(message))
+	signers := []int{4, 1, 2}
+	shares := make([]Signature, t)
+	signerPoints := make([]*big.Int, t)
+	for i, k := range signers {
+		shares[i] = Sign(hash, memberSecrets[k])
+		signerPoints[i] = points[k]
+	}
+	signature, err := ThresholdAt(shares, signerPoints, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Zero and duplicate points must be rejected.
+	_, _, _, _, _, err = GenKeySharesAt(t, []*big.Int{big.NewInt(1), big.NewInt(2), system.order()}, system)
+	if err == nil {
+		test.Fatal("Failed to reject a zero evaluation point.")
+	}
+	_, _, _, _, _, err = GenKeySharesAt(t, []*big.Int{points[0], points[1], points[0]}, system)
+	if err == nil {
+		test.Fatal("Failed to reject duplicate evaluation points.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := 0; i < t; i++ {
+		shares[i].Free()
+		commitments[i].Free()
+	}
+	for i := range nodes {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestThresholdSignatureParallel(test *testing.T) {
+
+	t := 2*minParallelShares + 1
+	n := t + 2
+	message := "This is a message."
+
+	// Generate key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message with enough members to split the interpolation.
+	hash := sha256.Sum256([]byte(message))
+	memberIds := rand.Perm(n)[:t]
+	shares := make([]Signature, t)
+	for i := range shares {
+		shares[i] = Sign(hash, memberSecrets[memberIds[i]])
+	}
+
+	// Recover the threshold signature and compare it to the group signature.
+	signature, err := Threshold(shares, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	expected := Sign(hash, groupSecret)
+	if !signature.Equal(expected) {
+		test.Fatal("Failed to recover the group signature.")
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	expected.Free()
+	for i := range shares {
+		shares[i].Free()
+		commitments[i].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestToFromBytes(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message and serialize the signature.
+	hash := sha256.Sum256([]byte(message))
+	signatureOut := Sign(hash, secret)
+	bytes := system.SigToBytes(signatureOut)
+
+	// Deserialize the signature and verify it.
+	signatureIn, err := system.SigFromBytes(bytes)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signatureIn.Free()
+	signatureOut.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func BenchmarkVerify(benchmark *testing.B) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeF(160)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+
+	// Sign the message.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Verify the signature.
+	benchmark.StartTimer()
+	for i := 0; i < benchmark.N; i++ {
+		Verify(signature, hash, key)
+	}
+	benchmark.StopTimer()
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func BenchmarkGenKeyShares(benchmark *testing.B) {
+
+	t := 667
+	n := 1000
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+
+	// Generate the key shares.
+	benchmark.ResetTimer()
+	for i := 0; i < benchmark.N; i++ {
+		groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+		if err != nil {
+			benchmark.Fatal(err)
+		}
+		benchmark.StopTimer()
+		for j := range commitments {
+			commitments[j].Free()
+		}
+		for j := 0; j < n; j++ {
+			memberKeys[j].Free()
+			memberSecrets[j].Free()
+		}
+		groupSecret.Free()
+		groupKey.Free()
+		benchmark.StartTimer()
+	}
+	benchmark.StopTimer()
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestLagrangeCoefficients(test *testing.T) {
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Select group members.
+	rand.Seed(time.Now().UnixNano())
+	n := rand.Intn(20) + 1
+	t := rand.Intn(n) + 1
+	memberIds := rand.Perm(n)[:t]
+
+	// Interpolating the constant polynomial must yield one.
+	coeffs, err := LagrangeCoefficients(memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	sum := big.NewInt(0)
+	for i := range coeffs {
+		sum.Add(sum, coeffs[i])
+	}
+	if sum.Mod(sum, system.order()).Cmp(big.NewInt(1)) != 0 {
+		test.Fatal("Lagrange coefficients do not sum to one.")
+	}
 
-	// Generate key pairs.
+	// Duplicate members must be rejected.
+	_, err = LagrangeCoefficients([]int{0, 0}, system)
+	if err == nil {
+		test.Fatal("Failed to reject duplicate members.")
+	}
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestParamsString(test *testing.T) {
+
+	// Generate type D parameters, which are slow to search for.
 	params, err := GenParamsTypeD(9563, 512)
 	if err != nil {
 		test.Fatal(err)
 	}
+
+	// Round-trip the parameters through the text format.
+	imported, err := ParamsFromString(params.String())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if imported.String() != params.String() {
+		test.Fatal("Parameters changed in round trip.")
+	}
+
+	// The imported parameters must yield a working pairing.
+	pairing := GenPairing(imported)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	imported.Free()
+	params.Free()
+
+}
+
+func TestSystemToFromBytes(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeF(160)
 	pairing := GenPairing(params)
 	system, err := GenSystem(pairing)
 	if err != nil {
 		test.Fatal(err)
 	}
-	keys := make([]PublicKey, n)
-	secrets := make([]PrivateKey, n)
-	for i := 0; i < n; i++ {
-		keys[i], secrets[i], err = GenKeys(system)
-		if err != nil {
-			test.Fatal(err)
-		}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
 	}
 
-	// Sign the messages.
-	hashes := make([][sha256.Size]byte, n)
-	signatures := make([]Signature, n)
-	for i := 0; i < n; i++ {
-		hashes[i] = sha256.Sum256([]byte(messages[i]))
-		signatures[i] = Sign(hashes[i], secrets[i])
+	// Distribute the cryptosystem, the public key, and a signature.
+	hash := sha256.Sum256([]byte(message))
+	signatureOut := Sign(hash, secret)
+	imported, err := SystemFromBytes(system.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	keyIn, err := PublicKeyFromBytes(imported, key.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	signatureIn, err := imported.SigFromBytes(system.SigToBytes(signatureOut))
+	if err != nil {
+		test.Fatal(err)
 	}
 
-	// Aggregate the signatures.
-	aggregate, err := Aggregate(signatures, system)
+	// Verify the signature in the imported cryptosystem.
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// The hash function is recorded.
+	hashed, err := SystemFromBytes(system.WithHash(HashSHA3_256).ToBytes())
 	if err != nil {
 		test.Fatal(err)
 	}
+	if hashed.hash != HashSHA3_256 {
+		test.Fatal("Hash function does not match.")
+	}
+	hashed.Free()
+	hashed.Pairing().Free()
 
-	// Verify the aggregate signature.
-	valid, err := AggregateVerify(aggregate, hashes, keys)
+	// The encoding and the subgroup check policy are recorded, so signatures
+	// in a foreign encoding verify in the imported cryptosystem.
+	encoded, err := system.WithEncoding(Encoding{LittleEndian: true, FieldSize: 32, Format: PointUncompressed})
 	if err != nil {
 		test.Fatal(err)
 	}
-	if !valid {
-		test.Fatal("Failed to verify aggregate signature.")
+	encoded = encoded.WithSubgroupCheck(SubgroupCheckOnDeserialize)
+	decoded, err := SystemFromBytes(encoded.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if decoded.encoding.Encoding != encoded.encoding.Encoding || decoded.subgroup != SubgroupCheckOnDeserialize {
+		test.Fatal("Encoding does not match.")
+	}
+	signatureEnc, err := decoded.SigFromBytes(encoded.SigToBytes(signatureOut))
+	if err != nil {
+		test.Fatal(err)
+	}
+	keyEnc, err := PublicKeyFromBytes(decoded, PublicKey{encoded, key.gx}.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureEnc, hash, keyEnc) {
+		test.Fatal("Failed to verify signature in the imported encoding.")
+	}
+	signatureEnc.Free()
+	keyEnc.Free()
+	decoded.Free()
+	decoded.Pairing().Free()
+
+	// Truncated data must be rejected.
+	bytes := system.ToBytes()
+	_, err = SystemFromBytes(bytes[:len(bytes)-1])
+	if err == nil {
+		test.Fatal("Failed to reject truncated system.")
 	}
 
 	// Clean up.
-	aggregate.Free()
-	for i := 0; i < n; i++ {
-		signatures[i].Free()
-		keys[i].Free()
-		secrets[i].Free()
+	signatureIn.Free()
+	signatureOut.Free()
+	keyIn.Free()
+	key.Free()
+	secret.Free()
+	imported.Free()
+	imported.Pairing().Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestPublic(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Recompute the public key from an imported private key.
+	imported, err := PrivateKeyFromBytes(system, secret.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	derived := imported.Public()
+	if derived.Hex() != key.Hex() {
+		test.Fatal("Public keys do not match.")
 	}
+
+	// Clean up.
+	derived.Free()
+	imported.Free()
+	key.Free()
+	secret.Free()
 	system.Free()
 	pairing.Free()
 	params.Free()
 
 }
 
-func TestThresholdSignature(test *testing.T) {
+func TestEqual(test *testing.T) {
 
 	message := "This is a message."
 
-	// Generate key shares.
-	params := GenParamsTypeF(256)
+	// Generate key pairs.
+	params := GenParamsTypeA(160, 512)
 	pairing := GenPairing(params)
 	system, err := GenSystem(pairing)
 	if err != nil {
 		test.Fatal(err)
 	}
-	rand.Seed(time.Now().UnixNano())
-	n := rand.Intn(20) + 1
-	t := rand.Intn(n) + 1
-	groupKey, memberKeys, groupSecret, memberSecrets, err := GenKeyShares(t, n, system)
+	key1, secret1, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key2, secret2, err := GenKeys(system)
 	if err != nil {
 		test.Fatal(err)
 	}
 
-	// Select group members.
-	memberIds := rand.Perm(n)[:t]
-
-	// Sign the message.
-	hash := sha256.Sum256([]byte(message))
-	shares := make([]Signature, t)
-	for i := 0; i < t; i++ {
-		shares[i] = Sign(hash, memberSecrets[memberIds[i]])
+	// Compare the keys.
+	derived := secret1.Public()
+	if !derived.Equal(key1) || key1.Equal(key2) {
+		test.Fatal("Unexpected public key comparison.")
+	}
+	imported, err := PrivateKeyFromBytes(system, secret1.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !imported.Equal(secret1) || secret1.Equal(secret2) {
+		test.Fatal("Unexpected private key comparison.")
 	}
 
-	// Recover the threshold signature.
-	signature, err := Threshold(shares, memberIds, system)
+	// Compare the signatures.
+	hash := sha256.Sum256([]byte(message))
+	signature1 := Sign(hash, secret1)
+	signature2 := Sign(hash, secret2)
+	roundTrip, err := system.SigFromBytes(system.SigToBytes(signature1))
 	if err != nil {
 		test.Fatal(err)
 	}
+	if !roundTrip.Equal(signature1) || signature1.Equal(signature2) {
+		test.Fatal("Unexpected signature comparison.")
+	}
 
-	// Verify the threshold signature.
-	if !Verify(signature, hash, groupKey) {
-		test.Fatal("Failed to verify signature.")
+	// Elements of different groups are not equal.
+	if signature1.Equal(key1.gx) {
+		test.Fatal("Elements of different groups are equal.")
 	}
 
 	// Clean up.
-	signature.Free()
-	groupKey.Free()
-	groupSecret.Free()
-	for i := 0; i < t; i++ {
-		shares[i].Free()
-	}
-	for i := 0; i < n; i++ {
-		memberKeys[i].Free()
-		memberSecrets[i].Free()
-	}
+	roundTrip.Free()
+	signature1.Free()
+	signature2.Free()
+	imported.Free()
+	derived.Free()
+	key1.Free()
+	key2.Free()
+	secret1.Free()
+	secret2.Free()
 	system.Free()
 	pairing.Free()
 	params.Free()
 
 }
 
-func TestToFromBytes(test *testing.T) {
-
-	message := "This is a message."
+func TestScalar(test *testing.T) {
 
 	// Generate a key pair.
 	params := GenParamsTypeA(160, 512)
@@ -190,23 +1158,31 @@ func TestToFromBytes(test *testing.T) {
 		test.Fatal(err)
 	}
 
-	// Sign the message and serialize the signature.
-	hash := sha256.Sum256([]byte(message))
-	signatureOut := Sign(hash, secret)
-	bytes := system.SigToBytes(signatureOut)
-
-	// Deserialize the signature and verify it.
-	signatureIn, err := system.SigFromBytes(bytes)
+	// Round-trip the scalar.
+	scalar := secret.ScalarBytes()
+	if len(scalar) != 20 {
+		test.Fatal("Unexpected scalar length.")
+	}
+	imported, err := PrivateKeyFromScalar(system, scalar)
 	if err != nil {
 		test.Fatal(err)
 	}
-	if !Verify(signatureIn, hash, key) {
-		test.Fatal("Failed to verify signature.")
+	if !imported.Equal(secret) {
+		test.Fatal("Private keys do not match.")
+	}
+
+	// Zero and the group order are out of range.
+	_, err = PrivateKeyFromScalar(system, make([]byte, 20))
+	if err == nil {
+		test.Fatal("Accepted a zero scalar.")
+	}
+	_, err = PrivateKeyFromScalar(system, system.order().FillBytes(make([]byte, 20)))
+	if err == nil {
+		test.Fatal("Accepted the group order.")
 	}
 
 	// Clean up.
-	signatureIn.Free()
-	signatureOut.Free()
+	imported.Free()
 	key.Free()
 	secret.Free()
 	system.Free()
@@ -215,39 +1191,137 @@ func TestToFromBytes(test *testing.T) {
 
 }
 
-func BenchmarkVerify(benchmark *testing.B) {
+func TestCheckMemberIds(test *testing.T) {
 
-	message := "This is a message."
+	// Valid identifiers.
+	if err := CheckMemberIds([]int{4, 0, 2}, 3); err != nil {
+		test.Fatal(err)
+	}
 
-	// Generate a key pair.
-	params := GenParamsTypeF(160)
+	// Invalid identifiers.
+	cases := []struct {
+		memberIds []int
+		t         int
+	}{
+		{[]int{0, 2, 0}, 3},
+		{[]int{0, -1, 2}, 3},
+		{[]int{0, 1}, 3},
+		{[]int{}, 1},
+	}
+	for _, c := range cases {
+		if CheckMemberIds(c.memberIds, c.t) == nil {
+			test.Fatalf("Accepted member identifiers %v with threshold %d.", c.memberIds, c.t)
+		}
+	}
+
+}
+
+func TestMemberPointsFromKeys(test *testing.T) {
+
+	n := 4
+
+	// Generate the long-term keys of the members.
+	params := GenParamsTypeA(160, 512)
 	pairing := GenPairing(params)
 	system, err := GenSystem(pairing)
 	if err != nil {
-		benchmark.Fatal(err)
+		test.Fatal(err)
 	}
-	key, secret, err := GenKeys(system)
+	keys := make([]PublicKey, n)
+	secrets := make([]PrivateKey, n)
+	for i := range keys {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// The points do not depend on the order of the keys.
+	points, err := MemberPointsFromKeys(keys)
 	if err != nil {
-		benchmark.Fatal(err)
+		test.Fatal(err)
+	}
+	reversed := []PublicKey{keys[3], keys[2], keys[1], keys[0]}
+	other, err := MemberPointsFromKeys(reversed)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for i := range points {
+		if points[i].Cmp(other[n-1-i]) != 0 {
+			test.Fatal("Points do not match.")
+		}
 	}
 
-	// Sign the message.
-	hash := sha256.Sum256([]byte(message))
-	signature := Sign(hash, secret)
+	// The points are valid evaluation points.
+	_, err = LagrangeCoefficientsAt(points, system)
+	if err != nil {
+		test.Fatal(err)
+	}
 
-	// Verify the signature.
-	benchmark.StartTimer()
-	for i := 0; i < benchmark.N; i++ {
-		Verify(signature, hash, key)
+	// Duplicate keys must be rejected.
+	_, err = MemberPointsFromKeys([]PublicKey{keys[0], keys[1], keys[0]})
+	if err == nil {
+		test.Fatal("Failed to reject duplicate keys.")
 	}
-	benchmark.StopTimer()
 
 	// Clean up.
-	signature.Free()
-	key.Free()
+	for i := range keys {
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestFromBytesNonCanonical(test *testing.T) {
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Reject a private key that exceeds the group order.
+	n := len(secret.ToBytes())
+	x := new(big.Int).Add(system.order(), big.NewInt(1))
+	if x.BitLen() <= 8*n {
+		_, err = PrivateKeyFromBytes(system, x.FillBytes(make([]byte, n)))
+		if err == nil {
+			test.Fatal("Failed to reject a private key that exceeds the group order.")
+		}
+	}
+
+	// Accept the canonical encoding of the same private key.
+	one, err := PrivateKeyFromBytes(system, big.NewInt(1).FillBytes(make([]byte, n)))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Clean up.
+	one.Free()
 	secret.Free()
 	system.Free()
 	pairing.Free()
 	params.Free()
 
 }
+
+func TestGenParamsTypeDInvalid(test *testing.T) {
+
+	// Reject discriminants that PBC would abort on.
+	for _, d := range []uint{0, 9561, 9562} {
+		_, err := GenParamsTypeD(d, 512)
+		if err == nil {
+			test.Fatalf("Failed to reject discriminant %d.", d)
+		}
+	}
+
+}
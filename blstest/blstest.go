@@ -0,0 +1,279 @@
+/**
+ * File        : blstest.go
+ * Description : Fake signature scheme for unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module mirrors the API of the bls package without cgo, so that
+ * applications embedding go-bls can run fast unit tests on machines without
+ * the PBC library. The "cryptography" is linear arithmetic modulo a prime. It
+ * supports aggregate and threshold signatures, but offers no security at all.
+ * Never use it outside of tests.
+ */
+
+package blstest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// The modulus of the fake group, 2^127 - 1.
+var order, _ = big.NewInt(0).SetString("170141183460469231731687303715884105727", 10)
+
+type Element struct {
+	get *big.Int
+}
+
+type Params struct{}
+
+type Pairing struct{}
+
+type System struct {
+	pairing Pairing
+	g       Element
+}
+
+type PublicKey struct {
+	system System
+	gx     Element
+}
+
+type PrivateKey struct {
+	system System
+	x      Element
+}
+
+type Signature = Element
+
+// Generate fake type A pairing parameters.
+func GenParamsTypeA(rbits int, qbits int) Params {
+	return Params{}
+}
+
+// Generate fake type D pairing parameters.
+func GenParamsTypeD(d uint, bitlimit uint) (Params, error) {
+	m := d % 4
+	if d == 0 || m == 1 || m == 2 {
+		return Params{}, errors.New("blstest.GenParamsTypeD: Discriminant must be 0 or 3 mod 4 and positive.")
+	}
+	return Params{}, nil
+}
+
+// Generate fake type F pairing parameters.
+func GenParamsTypeF(bits int) Params {
+	return Params{}
+}
+
+// Generate a fake pairing from the given parameters.
+func GenPairing(params Params) Pairing {
+	return Pairing{}
+}
+
+// Generate a fake cryptosystem from the given pairing.
+func GenSystem(pairing Pairing) (System, error) {
+	g, err := randomElement()
+	if err != nil {
+		return System{}, err
+	}
+	return System{pairing, g}, nil
+}
+
+// Generate a fake key pair from the given cryptosystem.
+func GenKeys(system System) (PublicKey, PrivateKey, error) {
+	x, err := randomElement()
+	if err != nil {
+		return PublicKey{}, PrivateKey{}, err
+	}
+	return PublicKey{system, system.g.mul(x)}, PrivateKey{system, x}, nil
+}
+
+// Generate a fake key pair from the given cryptosystem and divide each key into
+// n shares such that t shares can combine signatures to recover a threshold
+// signature. The fake commitments to the coefficients of the polynomial are
+// also returned.
+func GenKeyShares(t int, n int, system System) (PublicKey, []PublicKey, PrivateKey, []PrivateKey, []PublicKey, error) {
+
+	// Check the threshold parameters.
+	if t < 1 || n < t {
+		return PublicKey{}, nil, PrivateKey{}, nil, nil, errors.New("blstest.GenKeyShares: Bad threshold parameters.")
+	}
+
+	// Generate a polynomial.
+	coeff := make([]Element, t)
+	var err error
+	for j := range coeff {
+		coeff[j], err = randomElement()
+		if err != nil {
+			return PublicKey{}, nil, PrivateKey{}, nil, nil, err
+		}
+	}
+
+	// Derive the key pair and the key shares from the polynomial.
+	keys := make([]PublicKey, n+1)
+	secrets := make([]PrivateKey, n+1)
+	for i := 0; i < n+1; i++ {
+		x := big.NewInt(0)
+		for j := t - 1; j >= 0; j-- {
+			x.Mul(x, big.NewInt(int64(i)))
+			x.Add(x, coeff[j].get)
+			x.Mod(x, order)
+		}
+		secrets[i] = PrivateKey{system, Element{x}}
+		keys[i] = PublicKey{system, system.g.mul(secrets[i].x)}
+	}
+
+	// Commit to the coefficients.
+	commitments := make([]PublicKey, t)
+	for j := range coeff {
+		commitments[j] = PublicKey{system, system.g.mul(coeff[j])}
+	}
+
+	// Return the key pair, the key shares, and the commitments.
+	return keys[0], keys[1:], secrets[0], secrets[1:], commitments, nil
+
+}
+
+// Sign a message digest using a private key.
+func Sign(hash [sha256.Size]byte, secret PrivateKey) Signature {
+	return hashElement(hash).mul(secret.x)
+}
+
+// Verify a signature on the message digest using the public key of the signer.
+func Verify(signature Signature, hash [sha256.Size]byte, key PublicKey) bool {
+	lhs := signature.mul(key.system.g)
+	rhs := hashElement(hash).mul(key.gx)
+	return lhs.get.Cmp(rhs.get) == 0
+}
+
+// Aggregate signatures using the cryptosystem.
+func Aggregate(signatures []Signature, system System) (Signature, error) {
+	if len(signatures) == 0 {
+		return Element{}, errors.New("blstest.Aggregate: Empty list.")
+	}
+	sigma := big.NewInt(0)
+	for i := range signatures {
+		sigma.Add(sigma, signatures[i].get)
+	}
+	return Element{sigma.Mod(sigma, order)}, nil
+}
+
+// Verify an aggregate signature on the message digests using the public keys of
+// the signers.
+func AggregateVerify(signature Signature, hashes [][sha256.Size]byte, keys []PublicKey) (bool, error) {
+
+	// Check the list length.
+	if len(hashes) == 0 {
+		return false, errors.New("blstest.AggregateVerify: Empty list.")
+	}
+	if len(hashes) != len(keys) {
+		return false, errors.New("blstest.AggregateVerify: List length mismatch.")
+	}
+
+	// Check the uniqueness constraint.
+	seen := make(map[[sha256.Size]byte]struct{}, len(hashes))
+	for i := range hashes {
+		if _, ok := seen[hashes[i]]; ok {
+			return false, errors.New("blstest.AggregateVerify: Message digests must be distinct.")
+		}
+		seen[hashes[i]] = struct{}{}
+	}
+
+	// Equate the left and right-hand side.
+	lhs := signature.mul(keys[0].system.g)
+	rhs := big.NewInt(0)
+	for i := range hashes {
+		rhs.Add(rhs, hashElement(hashes[i]).mul(keys[i].gx).get)
+	}
+	return lhs.get.Cmp(rhs.Mod(rhs, order)) == 0, nil
+
+}
+
+// Recover a threshold signature from the signature shares provided by the group
+// members using the cryptosystem.
+func Threshold(shares []Signature, memberIds []int, system System) (Signature, error) {
+
+	// Check the list length.
+	if len(shares) == 0 {
+		return Element{}, errors.New("blstest.Recover: Empty list.")
+	}
+	if len(shares) != len(memberIds) {
+		return Element{}, errors.New("blstest.Recover: List length mismatch.")
+	}
+
+	// Interpolate the shares at zero.
+	sigma := big.NewInt(0)
+	for i := range memberIds {
+		p := big.NewInt(1)
+		q := big.NewInt(1)
+		for j := range memberIds {
+			if i == j {
+				continue
+			}
+			if memberIds[i] == memberIds[j] {
+				return Element{}, errors.New("blstest.Recover: Member identifiers must be distinct.")
+			}
+			p.Mul(p, big.NewInt(int64(-memberIds[j]-1)))
+			q.Mul(q, big.NewInt(int64(memberIds[i]-memberIds[j])))
+		}
+		q.ModInverse(q.Mod(q, order), order)
+		lambda := p.Mul(p, q)
+		sigma.Add(sigma, lambda.Mul(lambda, shares[i].get))
+		sigma.Mod(sigma, order)
+	}
+	return Element{sigma}, nil
+
+}
+
+// Convert a signature to a byte slice.
+func (system System) SigToBytes(signature Signature) []byte {
+	bytes := make([]byte, 16)
+	return signature.get.FillBytes(bytes)
+}
+
+// Convert a byte slice to a signature.
+func (system System) SigFromBytes(bytes []byte) (Signature, error) {
+	if len(bytes) != 16 {
+		return Element{}, errors.New("blstest.FromBytes: Signature length mismatch.")
+	}
+	return Element{big.NewInt(0).SetBytes(bytes)}, nil
+}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (element Element) Free() {}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (params Params) Free() {}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (pairing Pairing) Free() {}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (system System) Free() {}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (key PublicKey) Free() {}
+
+// Free does nothing. It exists for compatibility with the bls package.
+func (secret PrivateKey) Free() {}
+
+func (element Element) mul(other Element) Element {
+	x := big.NewInt(0).Mul(element.get, other.get)
+	return Element{x.Mod(x, order)}
+}
+
+func hashElement(hash [sha256.Size]byte) Element {
+	x := big.NewInt(0).SetBytes(hash[:])
+	return Element{x.Mod(x, order)}
+}
+
+func randomElement() (Element, error) {
+	x, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return Element{}, err
+	}
+	return Element{x}, nil
+}
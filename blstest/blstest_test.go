@@ -0,0 +1,129 @@
+/**
+ * File        : blstest_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the fake signature scheme.
+ */
+
+package blstest
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message and round-trip the signature.
+	hash := sha256.Sum256([]byte(message))
+	signature, err := system.SigFromBytes(system.SigToBytes(Sign(hash, secret)))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the signature.
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	if Verify(signature, sha256.Sum256([]byte("Another message.")), key) {
+		test.Fatal("Verified signature on wrong message.")
+	}
+
+}
+
+func TestAggregateVerify(test *testing.T) {
+
+	messages := []string{
+		"This is a message.",
+		"This is another message.",
+		"This is yet another message.",
+	}
+	n := len(messages)
+
+	// Generate key pairs and sign the messages.
+	system, err := GenSystem(GenPairing(GenParamsTypeF(256)))
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, n)
+	hashes := make([][sha256.Size]byte, n)
+	signatures := make([]Signature, n)
+	for i := 0; i < n; i++ {
+		var secret PrivateKey
+		keys[i], secret, err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+		hashes[i] = sha256.Sum256([]byte(messages[i]))
+		signatures[i] = Sign(hashes[i], secret)
+	}
+
+	// Aggregate and verify the signatures.
+	aggregate, err := Aggregate(signatures, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	valid, err := AggregateVerify(aggregate, hashes, keys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify aggregate signature.")
+	}
+
+}
+
+func TestThresholdSignature(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate key shares.
+	system, err := GenSystem(GenPairing(GenParamsTypeF(256)))
+	if err != nil {
+		test.Fatal(err)
+	}
+	rand.Seed(time.Now().UnixNano())
+	n := rand.Intn(20) + 1
+	t := rand.Intn(n) + 1
+	groupKey, _, _, memberSecrets, _, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message with a random selection of members.
+	memberIds := rand.Perm(n)[:t]
+	hash := sha256.Sum256([]byte(message))
+	shares := make([]Signature, t)
+	for i := 0; i < t; i++ {
+		shares[i] = Sign(hash, memberSecrets[memberIds[i]])
+	}
+
+	// Recover and verify the threshold signature.
+	signature, err := Threshold(shares, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+}
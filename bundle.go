@@ -0,0 +1,270 @@
+/**
+ * File        : bundle.go
+ * Description : Key share bundles.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module packages everything a group member needs to take part in
+ * threshold signing into a single serializable bundle, which is validated as a
+ * whole when it is imported.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"unsafe"
+)
+
+// A MemberShareBundle holds the key shares of a group member together with the
+// threshold configuration of the group.
+type MemberShareBundle struct {
+	MemberId    int
+	Threshold   int
+	Members     int
+	Fingerprint [sha256.Size]byte
+	Secret      PrivateKey
+	Key         PublicKey
+	Commitments []PublicKey
+}
+
+// Generate a key pair from the given cryptosystem and divide it into n bundles
+// such that t members can combine signatures to recover a threshold signature.
+// Each bundle carries the commitments of the dealer, so that Validate checks
+// the share against them. The group private key is discarded. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func GenShareBundles(t int, n int, system System) (PublicKey, []MemberShareBundle, error) {
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		return PublicKey{}, nil, err
+	}
+	groupSecret.Zeroize()
+	fingerprint := system.Fingerprint()
+	bundles := make([]MemberShareBundle, n)
+	for i := range bundles {
+		copies := make([]PublicKey, t)
+		for j := range copies {
+			copies[j] = commitments[j].copy()
+		}
+		bundles[i] = MemberShareBundle{i, t, n, fingerprint, memberSecrets[i], memberKeys[i], copies}
+	}
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	return groupKey, bundles, nil
+}
+
+// Compute the fingerprint of the cryptosystem, which identifies the system
+// parameter and the group order.
+func (system System) Fingerprint() [sha256.Size]byte {
+	bytes := make([]byte, C.element_length_in_bytes(system.g.get))
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&bytes[0])), system.g.get)
+	return sha256.Sum256(append(bytes, system.order().Bytes()...))
+}
+
+// Compute the fingerprint of the public key, which identifies the key and its
+// cryptosystem regardless of the encoding of the system.
+func (key PublicKey) Fingerprint() [sha256.Size]byte {
+	fingerprint := key.system.Fingerprint()
+	bytes := make([]byte, C.element_length_in_bytes(key.gx.get))
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&bytes[0])), key.gx.get)
+	return sha256.Sum256(append(fingerprint[:], bytes...))
+}
+
+// Export the bundle to a byte slice.
+func (bundle MemberShareBundle) ToBytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(bundle.Fingerprint[:])
+	for _, v := range []int{bundle.MemberId, bundle.Threshold, bundle.Members, len(bundle.Commitments)} {
+		binary.Write(&buf, binary.BigEndian, uint32(v))
+	}
+	x := make([]byte, C.element_length_in_bytes(bundle.Secret.x.get))
+	C.element_to_bytes((*C.uchar)(unsafe.Pointer(&x[0])), bundle.Secret.x.get)
+	buf.Write(x)
+	buf.Write(bundle.Key.ToBytes())
+	for i := range bundle.Commitments {
+		buf.Write(bundle.Commitments[i].ToBytes())
+	}
+	return buf.Bytes()
+}
+
+// MemberShareBundleFromBytes imports a bundle from the provided byte slice and
+// validates it against the cryptosystem.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func MemberShareBundleFromBytes(system System, data []byte) (MemberShareBundle, error) {
+
+	// Read the header.
+	var bundle MemberShareBundle
+	buf := bytes.NewBuffer(data)
+	var header [4]uint32
+	if copy(bundle.Fingerprint[:], buf.Next(sha256.Size)) != sha256.Size || binary.Read(buf, binary.BigEndian, &header) != nil {
+		return MemberShareBundle{}, errors.New("bls.FromBytes: Bundle too short.")
+	}
+	bundle.MemberId = int(header[0])
+	bundle.Threshold = int(header[1])
+	bundle.Members = int(header[2])
+	if bundle.Fingerprint != system.Fingerprint() {
+		return MemberShareBundle{}, errors.New("bls.FromBytes: Bundle belongs to another system.")
+	}
+	if int(header[3]) != 0 && int(header[3]) != bundle.Threshold {
+		return MemberShareBundle{}, errors.New("bls.FromBytes: Commitment count mismatch.")
+	}
+
+	// Read the keys.
+	xn := int(C.pairing_length_in_bytes_Zr(system.pairing.get))
+	kn := len(PublicKey{system, system.g}.ToBytes())
+	if buf.Len() != xn+kn*(1+int(header[3])) {
+		return MemberShareBundle{}, errors.New("bls.FromBytes: Bundle length mismatch.")
+	}
+	secret, err := PrivateKeyFromBytes(system, buf.Next(xn))
+	if err != nil {
+		return MemberShareBundle{}, err
+	}
+	bundle.Secret = secret
+	keys := make([]PublicKey, 1+int(header[3]))
+	for i := range keys {
+		key, err := PublicKeyFromBytes(system, buf.Next(kn))
+		if err != nil {
+			for j := 0; j < i; j++ {
+				keys[j].Free()
+			}
+			bundle.Secret.Free()
+			return MemberShareBundle{}, err
+		}
+		keys[i] = key
+	}
+	bundle.Key = keys[0]
+	if len(keys) > 1 {
+		bundle.Commitments = keys[1:]
+	}
+
+	// Validate the bundle.
+	err = bundle.Validate()
+	if err != nil {
+		bundle.Free()
+		return MemberShareBundle{}, err
+	}
+	return bundle, nil
+
+}
+
+// Validate the bundle. The threshold configuration must be consistent, the
+// public key share must match the private key share, and if dealer commitments
+// are present, the private key share must lie on the committed polynomial.
+func (bundle MemberShareBundle) Validate() error {
+
+	// Check the threshold parameters.
+	if bundle.Threshold < 1 || bundle.Members < bundle.Threshold {
+		return errors.New("bls.Validate: Bad threshold parameters.")
+	}
+	if bundle.MemberId < 0 || bundle.MemberId >= bundle.Members {
+		return errors.New("bls.Validate: Member identifier out of range.")
+	}
+	if len(bundle.Commitments) != 0 && len(bundle.Commitments) != bundle.Threshold {
+		return errors.New("bls.Validate: Commitment count mismatch.")
+	}
+
+	// Check the system.
+	system := bundle.Secret.system
+	if bundle.Fingerprint != system.Fingerprint() {
+		return errors.New("bls.Validate: Bundle belongs to another system.")
+	}
+
+	// Check the public key share.
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	err := powSecret(gx, system.g.get, bundle.Secret.x.get)
+	if err != nil {
+		C.element_clear(gx)
+		return err
+	}
+	match := C.element_cmp(gx, bundle.Key.gx.get) == 0
+
+	// Check the commitments.
+	if match && len(bundle.Commitments) != 0 {
+		exps := make([]*big.Int, len(bundle.Commitments))
+		for j := range exps {
+			exps[j] = big.NewInt(0).Exp(big.NewInt(int64(bundle.MemberId+1)), big.NewInt(int64(j)), nil)
+		}
+		rhs := evalCommitments(bundle.Commitments, exps)
+		match = C.element_cmp(gx, rhs) == 0
+		C.element_clear(rhs)
+	}
+
+	// Clean up.
+	C.element_clear(gx)
+
+	// Return the result.
+	if !match {
+		return errors.New("bls.Validate: Key shares are inconsistent.")
+	}
+	return nil
+
+}
+
+// Get the group public key, which is the first commitment of the dealer. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func (bundle MemberShareBundle) GroupKey() (PublicKey, error) {
+	if len(bundle.Commitments) == 0 {
+		return PublicKey{}, errors.New("bls.GroupKey: Bundle has no commitments.")
+	}
+	return bundle.Commitments[0].copy(), nil
+}
+
+// Get the identifiers of all members of the group.
+func (bundle MemberShareBundle) MemberIds() []int {
+	return identityIds(bundle.Members)
+}
+
+// Describe the threshold configuration of the bundle.
+func (bundle MemberShareBundle) String() string {
+	if len(bundle.Commitments) == 0 {
+		return fmt.Sprintf("member %d of %d-of-%d group", bundle.MemberId, bundle.Threshold, bundle.Members)
+	}
+	return fmt.Sprintf("member %d of %d-of-%d group %s", bundle.MemberId, bundle.Threshold, bundle.Members, keyTag(bundle.Commitments[0]))
+}
+
+// Abbreviate a public key for display.
+func keyTag(key PublicKey) string {
+	hash := sha256.Sum256(key.ToBytes())
+	return hex.EncodeToString(hash[:8])
+}
+
+// Overwrite the private key share and free the memory occupied by the bundle.
+// The bundle cannot be used after calling this function.
+func (bundle MemberShareBundle) Zeroize() {
+	bundle.Secret.Zeroize()
+	bundle.Key.Free()
+	for i := range bundle.Commitments {
+		bundle.Commitments[i].Free()
+	}
+}
+
+// Free the memory occupied by the bundle. The bundle cannot be used after
+// calling this function.
+func (bundle MemberShareBundle) Free() {
+	bundle.Secret.Free()
+	bundle.Key.Free()
+	for i := range bundle.Commitments {
+		bundle.Commitments[i].Free()
+	}
+}
@@ -0,0 +1,121 @@
+/**
+ * File        : bundle_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for key share bundles.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestMemberShareBundle(test *testing.T) {
+
+	message := "This is a message."
+	t := 3
+	n := 5
+
+	// Generate bundles.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, bundles, err := GenShareBundles(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Round-trip the bundles of the first t members and sign.
+	hash := sha256.Sum256([]byte(message))
+	shares := make([]Signature, t)
+	memberIds := make([]int, t)
+	for i := 0; i < t; i++ {
+		bundle, err := MemberShareBundleFromBytes(system, bundles[i].ToBytes())
+		if err != nil {
+			test.Fatal(err)
+		}
+		shares[i] = Sign(hash, bundle.Secret)
+		memberIds[i] = bundle.MemberId
+		bundle.Free()
+	}
+
+	// Recover and verify the threshold signature.
+	signature, err := Threshold(shares, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// A bundle with a mismatched key share must be rejected.
+	bundles[0].Key, bundles[1].Key = bundles[1].Key, bundles[0].Key
+	_, err = MemberShareBundleFromBytes(system, bundles[0].ToBytes())
+	if err == nil {
+		test.Fatal("Failed to reject inconsistent bundle.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := 0; i < t; i++ {
+		shares[i].Free()
+	}
+	for i := 0; i < n; i++ {
+		bundles[i].Free()
+	}
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestMemberShareBundleIntrospection(test *testing.T) {
+
+	// Generate the bundles.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, bundles, err := GenShareBundles(2, 3, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Report the threshold configuration.
+	key, err := bundles[1].GroupKey()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !key.Equal(groupKey) {
+		test.Fatal("Group keys do not match.")
+	}
+	if len(bundles[1].MemberIds()) != 3 {
+		test.Fatal("Member identifiers do not match.")
+	}
+	if !strings.HasPrefix(bundles[1].String(), "member 1 of 2-of-3 group ") {
+		test.Fatal("Unexpected description:", bundles[1].String())
+	}
+
+	// Clean up.
+	key.Free()
+	for i := range bundles {
+		bundles[i].Free()
+	}
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,195 @@
+/**
+ * File        : cbor.go
+ * Description : CBOR encoding.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module encodes keys and signatures in the deterministic subset of CBOR
+ * (RFC 8949, Section 4.2). Each value is an array of two byte strings: the
+ * fingerprint of the cryptosystem and the byte encoding of the value. The
+ * method signatures match the Marshaler and Unmarshaler interfaces of common
+ * CBOR libraries.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// CBOR major types.
+const (
+	cborBytes = 2
+	cborArray = 4
+)
+
+// MarshalCBOR encodes the public key in deterministic CBOR.
+func (key PublicKey) MarshalCBOR() ([]byte, error) {
+	fingerprint := key.system.Fingerprint()
+	return cborPair(fingerprint[:], key.ToBytes()), nil
+}
+
+// UnmarshalCBOR decodes a public key from deterministic CBOR. If the key
+// already belongs to a cryptosystem, the data must belong to the same system.
+// Otherwise, the system is looked up in the registry.
+func (key *PublicKey) UnmarshalCBOR(data []byte) error {
+	fingerprint, bytes, err := cborUnpair(data)
+	if err != nil {
+		return err
+	}
+	system, err := key.system.resolve(fingerprint)
+	if err != nil {
+		return err
+	}
+	k, err := PublicKeyFromBytes(system, bytes)
+	if err != nil {
+		return err
+	}
+	*key = k
+	return nil
+}
+
+// MarshalCBOR encodes the private key in deterministic CBOR.
+func (secret PrivateKey) MarshalCBOR() ([]byte, error) {
+	fingerprint := secret.system.Fingerprint()
+	return cborPair(fingerprint[:], secret.ToBytes()), nil
+}
+
+// UnmarshalCBOR decodes a private key from deterministic CBOR. If the key
+// already belongs to a cryptosystem, the data must belong to the same system.
+// Otherwise, the system is looked up in the registry.
+func (secret *PrivateKey) UnmarshalCBOR(data []byte) error {
+	fingerprint, bytes, err := cborUnpair(data)
+	if err != nil {
+		return err
+	}
+	system, err := secret.system.resolve(fingerprint)
+	if err != nil {
+		return err
+	}
+	s, err := PrivateKeyFromBytes(system, bytes)
+	if err != nil {
+		return err
+	}
+	*secret = s
+	return nil
+}
+
+// MarshalCBOR encodes the signature in deterministic CBOR. The system of the
+// signature must be registered.
+func (element Element) MarshalCBOR() ([]byte, error) {
+	system, group, err := lookupElement(element)
+	if err != nil {
+		return nil, err
+	}
+	if group != groupG1 {
+		return nil, errors.New("bls.MarshalCBOR: Element is not a signature.")
+	}
+	fingerprint := system.Fingerprint()
+	return cborPair(fingerprint[:], system.SigToBytes(element)), nil
+}
+
+// UnmarshalCBOR decodes a signature from deterministic CBOR. The system of the
+// signature must be registered.
+func (element *Element) UnmarshalCBOR(data []byte) error {
+	fingerprint, bytes, err := cborUnpair(data)
+	if err != nil {
+		return err
+	}
+	system, err := lookupSystem(fingerprint)
+	if err != nil {
+		return err
+	}
+	signature, err := system.SigFromBytes(bytes)
+	if err != nil {
+		return err
+	}
+	*element = signature
+	return nil
+}
+
+// Encode an array of two byte strings.
+func cborPair(a []byte, b []byte) []byte {
+	data := cborHead(nil, cborArray, 2)
+	data = append(cborHead(data, cborBytes, uint64(len(a))), a...)
+	return append(cborHead(data, cborBytes, uint64(len(b))), b...)
+}
+
+// Decode an array of two byte strings. The first must be a fingerprint.
+func cborUnpair(data []byte) ([]byte, []byte, error) {
+	n, data, err := cborReadHead(data, cborArray)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n != 2 {
+		return nil, nil, errors.New("bls.UnmarshalCBOR: Expected a pair.")
+	}
+	var items [2][]byte
+	for i := range items {
+		n, data, err = cborReadHead(data, cborBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if uint64(len(data)) < n {
+			return nil, nil, errors.New("bls.UnmarshalCBOR: Data too short.")
+		}
+		items[i], data = data[:n], data[n:]
+	}
+	if len(data) != 0 {
+		return nil, nil, errors.New("bls.UnmarshalCBOR: Trailing data.")
+	}
+	if len(items[0]) != sha256.Size {
+		return nil, nil, errors.New("bls.UnmarshalCBOR: Invalid fingerprint.")
+	}
+	return items[0], items[1], nil
+}
+
+// Append the head of a data item using the shortest form of the argument.
+func cborHead(data []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(data, major<<5|byte(n))
+	case n <= 0xff:
+		return append(data, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return append(data, major<<5|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return append(data, major<<5|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		data = append(data, major<<5|27)
+		for i := 56; i >= 0; i -= 8 {
+			data = append(data, byte(n>>uint(i)))
+		}
+		return data
+	}
+}
+
+// Read the head of a data item of the given major type. Arguments that are not
+// in the shortest form are rejected, as are indefinite lengths.
+func cborReadHead(data []byte, major byte) (uint64, []byte, error) {
+	if len(data) == 0 || data[0]>>5 != major {
+		return 0, nil, errors.New("bls.UnmarshalCBOR: Unexpected data item.")
+	}
+	info := data[0] & 0x1f
+	data = data[1:]
+	if info < 24 {
+		return uint64(info), data, nil
+	}
+	if info > 27 {
+		return 0, nil, errors.New("bls.UnmarshalCBOR: Indefinite or reserved length.")
+	}
+	size := 1 << (info - 24)
+	if len(data) < size {
+		return 0, nil, errors.New("bls.UnmarshalCBOR: Data too short.")
+	}
+	var n uint64
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	if len(cborHead(nil, major, n)) != 1+size {
+		return 0, nil, errors.New("bls.UnmarshalCBOR: Non-canonical length.")
+	}
+	return n, data[size:], nil
+}
@@ -0,0 +1,101 @@
+/**
+ * File        : cbor_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for CBOR encoding.
+ */
+
+package bls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestCBOR(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	err = RegisterSystem(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer UnregisterSystem(system)
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Round-trip the key and the signature.
+	data, err := key.MarshalCBOR()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var keyIn PublicKey
+	err = keyIn.UnmarshalCBOR(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err = signature.MarshalCBOR()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var signatureIn Signature
+	err = signatureIn.UnmarshalCBOR(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signatureIn.Free()
+	keyIn.Free()
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestCBORCanonical(test *testing.T) {
+
+	// Lengths are encoded in the shortest form.
+	for _, n := range []uint64{0, 23, 24, 255, 256, 65535, 65536, 1 << 32} {
+		head := cborHead(nil, cborBytes, n)
+		m, rest, err := cborReadHead(head, cborBytes)
+		if err != nil || m != n || len(rest) != 0 {
+			test.Fatal("Failed to decode length.")
+		}
+	}
+	if !bytes.Equal(cborPair([]byte{1}, nil), []byte{0x82, 0x41, 0x01, 0x40}) {
+		test.Fatal("Unexpected encoding.")
+	}
+
+	// Non-canonical and indefinite lengths must be rejected.
+	_, _, err := cborReadHead([]byte{0x58, 0x05}, cborBytes)
+	if err == nil {
+		test.Fatal("Accepted a non-canonical length.")
+	}
+	_, _, err = cborReadHead([]byte{0x5f}, cborBytes)
+	if err == nil {
+		test.Fatal("Accepted an indefinite length.")
+	}
+
+}
@@ -0,0 +1,39 @@
+/**
+ * File        : clone.go
+ * Description : Concurrent use of a cryptosystem.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module documents and supports the concurrency model of the package. A
+ * System, its Pairing, and the keys and signatures of the System may be used
+ * by many goroutines at once. PBC only reads a pairing after initializing it,
+ * every operation allocates its own scratch space or takes it from a pool
+ * guarded by a mutex, and the caches of a System are guarded likewise. Free,
+ * however, must not be called on a value while any goroutine still uses it,
+ * and elements must not be modified while other goroutines read them.
+ *
+ * Callers that want goroutines to share no C structures at all, for instance
+ * to free them independently, can give each goroutine its own Clone of the
+ * System and import the keys into it with the FromBytes functions.
+ */
+
+package bls
+
+// Clone the cryptosystem, rebuilding the pairing and the system parameter so
+// that the clone shares no C structures with the original. The clone keeps the
+// hash function, the domain separation tag, the encoding, the subgroup check
+// policy, and the size of the point cache, but not its contents. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures,
+// including the pairing, to be freed.
+func (system System) Clone() (System, error) {
+	clone, err := SystemFromBytes(system.ToBytes())
+	if err != nil {
+		return System{}, err
+	}
+	if system.points != nil {
+		clone = clone.WithPointCache(system.points.size)
+	}
+	return clone, nil
+}
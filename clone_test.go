@@ -0,0 +1,118 @@
+/**
+ * File        : clone_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the concurrent use of a cryptosystem.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentUse(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	system = system.WithPointCache(4)
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify messages from many goroutines at once.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 16; j++ {
+				hash := sha256.Sum256([]byte(fmt.Sprintf("Message %d.", j%6)))
+				signature := Sign(hash, secret)
+				if !Verify(signature, hash, key) {
+					test.Errorf("Goroutine %d failed to verify signature %d.", i, j)
+				}
+				signature.Free()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Clean up.
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestClone(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystemWithHash(pairing, HashSHA512_256)
+	if err != nil {
+		test.Fatal(err)
+	}
+	system = system.WithPointCache(4)
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Clone the cryptosystem and import the public key into the clone.
+	clone, err := system.Clone()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if clone.pairing.get == system.pairing.get || clone.g.get == system.g.get {
+		test.Fatal("Clone shares C structures with the original.")
+	}
+	if clone.hash != system.hash || clone.points == nil || clone.points == system.points {
+		test.Fatal("Clone does not keep the options of the original.")
+	}
+	cloneKey, err := PublicKeyFromBytes(clone, key.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify a signature of the original under the imported key.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+	cloneSignature, err := clone.SigFromBytes(system.SigToBytes(signature))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(cloneSignature, hash, cloneKey) {
+		test.Fatal("Failed to verify signature with the clone.")
+	}
+
+	// Clean up.
+	cloneSignature.Free()
+	cloneKey.Free()
+	clone.Free()
+	clone.pairing.Free()
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,82 @@
+/**
+ * File        : coefficients.go
+ * Description : Precomputed Lagrange coefficients.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides a set of Lagrange coefficients computed once for a
+ * fixed set of signers, so that a combiner that recovers many threshold
+ * signatures from the same members does not recompute them for each message.
+ */
+
+package bls
+
+import (
+	"errors"
+	"math/big"
+)
+
+// A set of Lagrange coefficients for a fixed set of group members.
+type CoefficientSet struct {
+	system    System
+	memberIds []int
+	coeffs    []*big.Int
+}
+
+// Calculate the Lagrange coefficients for the given group members, of which
+// there must be at least t.
+func NewCoefficientSet(t int, memberIds []int, system System) (CoefficientSet, error) {
+
+	// Check the member identifiers.
+	err := CheckMemberIds(memberIds, t)
+	if err != nil {
+		return CoefficientSet{}, err
+	}
+
+	// Calculate the Lagrange coefficients.
+	coeffs, err := LagrangeCoefficients(memberIds, system)
+	if err != nil {
+		return CoefficientSet{}, err
+	}
+
+	// Return the coefficient set.
+	return CoefficientSet{system, append([]int(nil), memberIds...), coeffs}, nil
+
+}
+
+// Get the identifiers of the group members in the set.
+func (set CoefficientSet) MemberIds() []int {
+	return append([]int(nil), set.memberIds...)
+}
+
+// Get the Lagrange coefficients, in the order of the member identifiers.
+func (set CoefficientSet) Coefficients() []*big.Int {
+	coeffs := make([]*big.Int, len(set.coeffs))
+	for i := range set.coeffs {
+		coeffs[i] = big.NewInt(0).Set(set.coeffs[i])
+	}
+	return coeffs
+}
+
+// Recover a threshold signature from the signature shares of the group members
+// in the set, in the order of the member identifiers. This function allocates
+// C structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be freed.
+func (set CoefficientSet) Threshold(shares []Signature) (Signature, error) {
+
+	// Check the list length.
+	if len(set.coeffs) == 0 {
+		return Element{}, errors.New("bls.CoefficientSet.Threshold: Empty set.")
+	}
+	if len(shares) != len(set.coeffs) {
+		return Element{}, errors.New("bls.CoefficientSet.Threshold: List length mismatch.")
+	}
+	if !set.system.combinable() {
+		return Element{}, errors.New("bls.CoefficientSet.Threshold: Signatures without a sign cannot be combined.")
+	}
+
+	// Return the threshold signature.
+	return interpolateShares(shares, set.coeffs, set.system), nil
+
+}
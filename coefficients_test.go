@@ -0,0 +1,86 @@
+/**
+ * File        : coefficients_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for precomputed Lagrange coefficients.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestCoefficientSet(test *testing.T) {
+
+	t := 3
+	n := 5
+	messages := 4
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Reject too few or duplicate members.
+	_, err = NewCoefficientSet(t, []int{1, 3}, system)
+	if err == nil {
+		test.Fatal("Accepted too few members.")
+	}
+	_, err = NewCoefficientSet(t, []int{1, 3, 1}, system)
+	if err == nil {
+		test.Fatal("Accepted duplicate members.")
+	}
+
+	// Recover threshold signatures on several messages from the same members.
+	memberIds := []int{4, 1, 3}
+	set, err := NewCoefficientSet(t, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for j := 0; j < messages; j++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("This is message %d.", j)))
+		shares := make([]Signature, t)
+		for i, id := range set.MemberIds() {
+			shares[i] = Sign(hash, memberSecrets[id])
+		}
+		signature, err := set.Threshold(shares)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !Verify(signature, hash, groupKey) {
+			test.Fatal("Failed to verify signature.")
+		}
+		signature.Free()
+		for i := range shares {
+			shares[i].Free()
+		}
+	}
+
+	// Clean up.
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,141 @@
+/**
+ * File        : credential.go
+ * Description : Delegatable credentials.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements macaroon-style credentials. A root keyholder issues a
+ * capability to a delegate, who can attenuate it with further caveats and pass
+ * it on. The signatures along the delegation chain are aggregated, so that a
+ * credential carries a single signature regardless of its length.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// A Caveat is a statement restricting a credential, together with the key of
+// the delegate to whom the credential is passed.
+type Caveat struct {
+	Statement []byte
+	Delegate  PublicKey
+}
+
+// A Credential is a chain of caveats and the aggregate signature of the keys
+// along the chain. The first caveat is signed by the root key, and each
+// subsequent caveat by the delegate of the caveat before it.
+type Credential struct {
+	Caveats   []Caveat
+	Signature Signature
+}
+
+// Issue a credential to the delegate. This function allocates C structures on
+// the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func IssueCredential(statement []byte, delegate PublicKey, root PrivateKey) Credential {
+	hash := caveatHash(nil, statement, delegate)
+	return Credential{[]Caveat{{statement, delegate}}, Sign(hash, root)}
+}
+
+// Attenuate the credential with a further caveat and pass it on to the
+// delegate. The holder must be the delegate of the last caveat. The original
+// credential remains valid. This function allocates C structures on the C heap
+// using malloc. It is the responsibility of the caller to prevent memory leaks
+// by arranging for the C structures to be freed.
+func (credential Credential) Attenuate(statement []byte, delegate PublicKey, holder PrivateKey) (Credential, error) {
+
+	// Check the list length.
+	n := len(credential.Caveats)
+	if n == 0 {
+		return Credential{}, errors.New("bls.Attenuate: Empty credential.")
+	}
+
+	// Check that the holder is the current delegate.
+	system := holder.system
+	key := holder.Public()
+	match := C.element_cmp(key.gx.get, credential.Caveats[n-1].Delegate.gx.get) == 0
+	key.Free()
+	if !match {
+		return Credential{}, errors.New("bls.Attenuate: Holder is not the delegate.")
+	}
+
+	// Sign the new caveat.
+	var prev []byte
+	for i := range credential.Caveats {
+		hash := caveatHash(prev, credential.Caveats[i].Statement, credential.Caveats[i].Delegate)
+		prev = hash[:]
+	}
+	hash := caveatHash(prev, statement, delegate)
+	sigma := Sign(hash, holder)
+
+	// Aggregate the signatures.
+	aggregate, err := Aggregate([]Signature{credential.Signature, sigma}, system)
+	sigma.Free()
+	if err != nil {
+		return Credential{}, err
+	}
+
+	// Return the attenuated credential.
+	caveats := make([]Caveat, n, n+1)
+	copy(caveats, credential.Caveats)
+	return Credential{append(caveats, Caveat{statement, delegate}), aggregate}, nil
+
+}
+
+// Verify the delegation chain of the credential against the root key. The
+// caller is responsible for checking that the request at hand satisfies every
+// caveat statement.
+func VerifyCredential(credential Credential, root PublicKey) (bool, error) {
+
+	// Check the list length.
+	n := len(credential.Caveats)
+	if n == 0 {
+		return false, errors.New("bls.VerifyCredential: Empty credential.")
+	}
+
+	// Derive the message digest and the signer of each caveat.
+	hashes := make([][sha256.Size]byte, n)
+	keys := make([]PublicKey, n)
+	var prev []byte
+	for i := range credential.Caveats {
+		hashes[i] = caveatHash(prev, credential.Caveats[i].Statement, credential.Caveats[i].Delegate)
+		prev = hashes[i][:]
+		if i == 0 {
+			keys[i] = root
+		} else {
+			keys[i] = credential.Caveats[i-1].Delegate
+		}
+	}
+
+	// Verify the aggregate signature.
+	return AggregateVerify(credential.Signature, hashes, keys)
+
+}
+
+// Free the memory occupied by the credential signature. The delegate keys are
+// not freed. The credential cannot be used after calling this function.
+func (credential Credential) Free() {
+	credential.Signature.Free()
+}
+
+// Hash a caveat, chaining it to the hash of the previous caveat. Chaining binds
+// each caveat to its position and keeps the message digests distinct.
+func caveatHash(prev []byte, statement []byte, delegate PublicKey) [sha256.Size]byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(statement)))
+	data := append([]byte("go-bls credential"), prev...)
+	data = append(data, length[:]...)
+	data = append(data, statement...)
+	data = append(data, delegate.ToBytes()...)
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,80 @@
+/**
+ * File        : credential_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for delegatable credentials.
+ */
+
+package bls
+
+import (
+	"testing"
+)
+
+func TestCredential(test *testing.T) {
+
+	// Generate key pairs for the root and two delegates.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, 3)
+	secrets := make([]PrivateKey, 3)
+	for i := range keys {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Issue a credential and delegate it.
+	credential := IssueCredential([]byte("read /photos"), keys[1], secrets[0])
+	attenuated, err := credential.Attenuate([]byte("expires 2018-06-01"), keys[2], secrets[1])
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify both credentials against the root key.
+	for _, c := range []Credential{credential, attenuated} {
+		valid, err := VerifyCredential(c, keys[0])
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !valid {
+			test.Fatal("Failed to verify credential.")
+		}
+	}
+
+	// Only the current delegate can attenuate.
+	_, err = attenuated.Attenuate([]byte("anything"), keys[0], secrets[1])
+	if err == nil {
+		test.Fatal("Failed to reject attenuation by non-delegate.")
+	}
+
+	// Dropping a caveat must invalidate the credential.
+	stripped := Credential{attenuated.Caveats[:1], attenuated.Signature}
+	valid, err := VerifyCredential(stripped, keys[0])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified credential with a dropped caveat.")
+	}
+
+	// Clean up.
+	attenuated.Free()
+	credential.Free()
+	for i := range keys {
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
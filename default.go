@@ -0,0 +1,96 @@
+/**
+ * File        : default.go
+ * Description : Default cryptosystem.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides a fixed cryptosystem for applications that do not need
+ * to choose their own parameters. Every process derives the same system, so
+ * signatures produced by one process can be verified by any other.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"sync"
+	"unsafe"
+)
+
+// The type A pairing parameters of param/a.param. The 160-bit group order and
+// the 1024-bit embedding field give about 80 bits of security, which suits
+// tests but not the default cryptosystem.
+const stdParamsA = `type a
+q 8780710799663312522437781984754049815806883199414208211028653399266475630880222957078625179422662221423155858769582317459277713367317481324925129998224791
+h 12016012264891146079388821366740534204802954401251311822919615131047207289359704531102844802183906537786776
+r 730750818665451621361119245571504901405976559617
+exp2 159
+exp1 107
+sign1 1
+sign0 1
+`
+
+// Return the type A pairing parameters of param/a.param without generating new
+// parameters. They give about 80 bits of security. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func StdParamsA() Params {
+	params, _ := ParamsFromBytes([]byte(stdParamsA))
+	return params
+}
+
+// The type A pairing parameters of the default cryptosystem, generated as
+// GenParamsTypeA(256, 1536) does. The group order r = 2^255 + 2^176 - 1 has
+// 256 bits, and the pairing lands in the field of q^2 elements, which has 3072
+// bits, so both discrete logarithm problems offer about 128 bits of security.
+const defaultParams = `type a
+q 1719850782606881142482295984201556443580389550196567450496316538375911840780184552804229070929804963692654451920658322447479720768898229432122801605789232990967336990803716738016236886716600442094697112299937542034935637796074785702781274594171268035700850197272660154158745036348024941998037296295422714454372407590517086205208984796678537151703081695781273473571515466523921210467256564675481864616495084939838234364212977620443133051110344157322321104201421079
+h 29705842496408237024423571232774943085879983566580953301840364775948689072219230905382791237478479096018337304744176394908979637518940439065163485449161767793252348553500674802809284153413145788992986071822741790608125815937934982539080449527940573584813037038056411336166980563972333842477690594789684472596185043580502492219560220338212063639633725872128049604655752252673036854128360
+r 57896044618658097711785588285315258044688639729509478914052768175151701295103
+exp2 255
+exp1 176
+sign1 1
+sign0 -1
+`
+
+// The seed from which the system parameter of the default cryptosystem is
+// derived.
+const defaultSeed = "go-bls default system"
+
+var defaultSystem struct {
+	once   sync.Once
+	system System
+	err    error
+}
+
+// Return the default cryptosystem, which offers about 128 bits of security. The
+// system is built once per process and must not be freed.
+func DefaultSystem() (System, error) {
+	defaultSystem.once.Do(func() {
+		params, err := ParamsFromBytes([]byte(defaultParams))
+		if err != nil {
+			defaultSystem.err = err
+			return
+		}
+		defaultSystem.system = genSystemFromSeed(GenPairing(params), []byte(defaultSeed))
+	})
+	return defaultSystem.system, defaultSystem.err
+}
+
+// Generate a cryptosystem from the given pairing, deriving the system parameter
+// deterministically from the seed.
+func genSystemFromSeed(pairing Pairing, seed []byte) System {
+	hash := sha256.Sum256(seed)
+	g := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(g, pairing.get)
+	C.element_from_hash(g, unsafe.Pointer(&hash[0]), sha256.Size)
+	system := System{pairing: pairing, g: Element{g}}
+	system.gpp = system.preprocessG()
+	return system
+}
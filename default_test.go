@@ -0,0 +1,88 @@
+/**
+ * File        : default_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the default cryptosystem.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestStdParamsA(test *testing.T) {
+
+	message := "This is a message."
+
+	// Build a cryptosystem from the standard parameters.
+	params := StdParamsA()
+	if params.String() != stdParamsA {
+		test.Fatal("Standard parameters do not match param/a.param.")
+	}
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify the message.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestDefaultParams(test *testing.T) {
+
+	// Parse the parameters.
+	values := make(map[string]*big.Int)
+	for _, line := range strings.Split(strings.TrimSpace(defaultParams), "\n")[1:] {
+		fields := strings.Fields(line)
+		value, ok := new(big.Int).SetString(fields[1], 10)
+		if !ok {
+			test.Fatalf("Bad value for %s.", fields[0])
+		}
+		values[fields[0]] = value
+	}
+	q, h, r := values["q"], values["h"], values["r"]
+
+	// The group order must be the Solinas prime of the parameters.
+	expected := new(big.Int).Lsh(big.NewInt(1), uint(values["exp2"].Int64()))
+	expected.Add(expected, new(big.Int).Lsh(values["sign1"], uint(values["exp1"].Int64())))
+	expected.Add(expected, values["sign0"])
+	if r.Cmp(expected) != 0 || r.BitLen() < 256 || !r.ProbablyPrime(32) {
+		test.Fatal("Bad group order.")
+	}
+
+	// The field order must be a prime of 1536 bits with q = h r - 1.
+	expected.Mul(h, r)
+	expected.Sub(expected, big.NewInt(1))
+	if q.Cmp(expected) != 0 || q.BitLen() < 1536 || q.Bit(0) != 1 || q.Bit(1) != 1 || !q.ProbablyPrime(32) {
+		test.Fatal("Bad field order.")
+	}
+	if new(big.Int).Mod(h, big.NewInt(12)).Sign() != 0 {
+		test.Fatal("Bad cofactor.")
+	}
+
+}
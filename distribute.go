@@ -0,0 +1,376 @@
+/**
+ * File        : distribute.go
+ * Description : Encrypted share distribution.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module encrypts the private key shares of a dealer to the long-term
+ * public keys of the group members, producing a single package that can be
+ * broadcast, so that share delivery does not need a secure channel to each
+ * member. The encryption is ECIES over G2 with one ephemeral key for all
+ * members. Member i derives the AES-256-GCM key of its share from the
+ * Diffie-Hellman value of the ephemeral key and its own key, bound to its
+ * index. If the package carries the commitments of the dealer, each member
+ * also checks its share against them on opening. A member can extract its
+ * share from the package for storage, and rewrap it under a new key of its
+ * own when it rotates its long-term key, so that whoever stores or relays the
+ * share never sees it in the clear.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// A SharePackage holds the private key shares of a dealer, each encrypted to the
+// public key of its member, and optionally the commitments of the dealer.
+type SharePackage struct {
+	Ephemeral   PublicKey
+	Commitments []PublicKey
+	Ciphertexts [][]byte
+}
+
+// A SealedShare holds the private key share of one member, encrypted to the
+// public key of the member.
+type SealedShare struct {
+	MemberId   int
+	Ephemeral  PublicKey
+	Ciphertext []byte
+}
+
+// Encrypt the private key shares to the public keys of the members, where the
+// share and the key of member i are at index i. The commitments may be nil.
+// This function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func SealShares(commitments []PublicKey, shares []PrivateKey, memberKeys []PublicKey) (SharePackage, error) {
+
+	// Check the list length.
+	if len(shares) == 0 {
+		return SharePackage{}, errors.New("bls.SealShares: Empty list.")
+	}
+	if len(shares) != len(memberKeys) {
+		return SharePackage{}, errors.New("bls.SealShares: List length mismatch.")
+	}
+
+	// Generate the ephemeral key pair.
+	system := shares[0].system
+	ephemeral, r, err := GenKeys(system)
+	if err != nil {
+		return SharePackage{}, err
+	}
+	defer r.Zeroize()
+
+	// Encrypt the shares.
+	pkg := SharePackage{Ephemeral: ephemeral, Ciphertexts: make([][]byte, len(shares))}
+	for i := range shares {
+		aead, err := shareCipher(ephemeral, memberKeys[i], memberKeys[i], r, i)
+		if err != nil {
+			ephemeral.Free()
+			return SharePackage{}, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		pkg.Ciphertexts[i] = aead.Seal(nil, nonce, shares[i].ToBytes(), ephemeral.ToBytes())
+	}
+
+	// Copy the commitments.
+	for j := range commitments {
+		pkg.Commitments = append(pkg.Commitments, commitments[j].copy())
+	}
+
+	// Return the package.
+	return pkg, nil
+
+}
+
+// Decrypt the private key share of member i with the long-term private key of
+// the member. If the package carries commitments, the share is checked against
+// them. This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging for the
+// C structures to be freed.
+func (pkg SharePackage) Open(memberId int, secret PrivateKey) (PrivateKey, error) {
+
+	// Check the member.
+	if memberId < 0 || memberId >= len(pkg.Ciphertexts) {
+		return PrivateKey{}, errors.New("bls.Open: Member identifier out of range.")
+	}
+
+	// Decrypt the share.
+	return SealedShare{memberId, pkg.Ephemeral, pkg.Ciphertexts[memberId]}.Open(secret, pkg.Commitments)
+
+}
+
+// Extract the encrypted private key share of member i from the package. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func (pkg SharePackage) Share(memberId int) (SealedShare, error) {
+	if memberId < 0 || memberId >= len(pkg.Ciphertexts) {
+		return SealedShare{}, errors.New("bls.Share: Member identifier out of range.")
+	}
+	return SealedShare{memberId, pkg.Ephemeral.copy(), append([]byte{}, pkg.Ciphertexts[memberId]...)}, nil
+}
+
+// Decrypt the private key share with the long-term private key of the member.
+// If commitments are given, the share is checked against them. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func (sealed SealedShare) Open(secret PrivateKey, commitments []PublicKey) (PrivateKey, error) {
+
+	// Decrypt the share.
+	key := secret.Public()
+	aead, err := shareCipher(sealed.Ephemeral, key, sealed.Ephemeral, secret, sealed.MemberId)
+	key.Free()
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, sealed.Ciphertext, sealed.Ephemeral.ToBytes())
+	if err != nil {
+		return PrivateKey{}, errors.New("bls.Open: Decryption failed.")
+	}
+	share, err := PrivateKeyFromBytes(secret.system, plaintext)
+	clear(plaintext)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+
+	// Check the share against the commitments.
+	if len(commitments) != 0 {
+		valid, err := VerifyKeyShare(sealed.MemberId, share, commitments)
+		if err != nil || !valid {
+			share.Zeroize()
+			return PrivateKey{}, errors.New("bls.Open: Share does not match the commitments.")
+		}
+	}
+
+	// Return the share.
+	return share, nil
+
+}
+
+// Rewrap the private key share under a new public key of the member, using the
+// old long-term private key of the member. The member runs this itself when it
+// rotates its key, and hands only the result to whoever stores the share. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func (sealed SealedShare) Rewrap(oldSecret PrivateKey, newKey PublicKey) (SealedShare, error) {
+
+	// Decrypt the share.
+	share, err := sealed.Open(oldSecret, nil)
+	if err != nil {
+		return SealedShare{}, err
+	}
+	defer share.Zeroize()
+
+	// Encrypt the share to the new key.
+	return sealShare(sealed.MemberId, share, newKey)
+
+}
+
+// Encrypt the private key share of member i to a public key of the member under
+// a fresh ephemeral key.
+func sealShare(memberId int, share PrivateKey, memberKey PublicKey) (SealedShare, error) {
+
+	// Generate the ephemeral key pair.
+	ephemeral, r, err := GenKeys(share.system)
+	if err != nil {
+		return SealedShare{}, err
+	}
+	defer r.Zeroize()
+
+	// Encrypt the share.
+	aead, err := shareCipher(ephemeral, memberKey, memberKey, r, memberId)
+	if err != nil {
+		ephemeral.Free()
+		return SealedShare{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext := share.ToBytes()
+	ciphertext := aead.Seal(nil, nonce, plaintext, ephemeral.ToBytes())
+	clear(plaintext)
+
+	// Return the sealed share.
+	return SealedShare{memberId, ephemeral, ciphertext}, nil
+
+}
+
+// Derive the cipher of the share of member i from the Diffie-Hellman value,
+// which is the base raised to the secret. The dealer uses the member key and
+// the ephemeral secret, and the member uses the ephemeral key and its own
+// secret.
+func shareCipher(ephemeral PublicKey, memberKey PublicKey, base PublicKey, secret PrivateKey, memberId int) (cipher.AEAD, error) {
+
+	// Calculate the Diffie-Hellman value.
+	system := ephemeral.system
+	dh := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(dh, system.pairing.get)
+	err := powSecret(dh, base.gx.get, secret.x.get)
+	if err != nil {
+		C.element_clear(dh)
+		return nil, err
+	}
+	shared := PublicKey{system, Element{dh}}
+
+	// Derive the key.
+	h := sha256.New()
+	h.Write([]byte("go-bls share"))
+	binary.Write(h, binary.BigEndian, uint32(memberId))
+	h.Write(shared.ToBytes())
+	h.Write(ephemeral.ToBytes())
+	h.Write(memberKey.ToBytes())
+	key := h.Sum(nil)
+	wipe(dh)
+	shared.Free()
+
+	// Build the cipher.
+	block, err := aes.NewCipher(key)
+	clear(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+
+}
+
+// Export the package to a byte slice. The encoding is the fingerprint of the
+// cryptosystem, the number of commitments and ciphertexts, the ephemeral key,
+// the commitments, and the ciphertexts.
+func (pkg SharePackage) ToBytes() []byte {
+	var buf bytes.Buffer
+	fingerprint := pkg.Ephemeral.system.Fingerprint()
+	buf.Write(fingerprint[:])
+	binary.Write(&buf, binary.BigEndian, [2]uint32{uint32(len(pkg.Commitments)), uint32(len(pkg.Ciphertexts))})
+	buf.Write(pkg.Ephemeral.ToBytes())
+	for j := range pkg.Commitments {
+		buf.Write(pkg.Commitments[j].ToBytes())
+	}
+	for i := range pkg.Ciphertexts {
+		buf.Write(pkg.Ciphertexts[i])
+	}
+	return buf.Bytes()
+}
+
+// SharePackageFromBytes imports a package from the provided byte slice. It
+// expects the data format exported by ToBytes.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func SharePackageFromBytes(system System, data []byte) (SharePackage, error) {
+
+	// Read the header.
+	var fingerprint [sha256.Size]byte
+	var header [2]uint32
+	buf := bytes.NewBuffer(data)
+	if copy(fingerprint[:], buf.Next(sha256.Size)) != sha256.Size || binary.Read(buf, binary.BigEndian, &header) != nil {
+		return SharePackage{}, errors.New("bls.SharePackageFromBytes: Data too short.")
+	}
+	if fingerprint != system.Fingerprint() {
+		return SharePackage{}, errors.New("bls.SharePackageFromBytes: Package belongs to another system.")
+	}
+	t, n := int(header[0]), int(header[1])
+	kn := len(PublicKey{system, system.g}.ToBytes())
+	cn := int(C.pairing_length_in_bytes_Zr(system.pairing.get)) + 16
+	if n == 0 || buf.Len() != kn*(1+t)+cn*n {
+		return SharePackage{}, errors.New("bls.SharePackageFromBytes: Package length mismatch.")
+	}
+
+	// Read the keys.
+	var pkg SharePackage
+	var err error
+	pkg.Ephemeral, err = PublicKeyFromBytes(system, buf.Next(kn))
+	if err != nil {
+		return SharePackage{}, err
+	}
+	for j := 0; j < t; j++ {
+		key, err := PublicKeyFromBytes(system, buf.Next(kn))
+		if err != nil {
+			pkg.Free()
+			return SharePackage{}, err
+		}
+		pkg.Commitments = append(pkg.Commitments, key)
+	}
+
+	// Read the ciphertexts.
+	for i := 0; i < n; i++ {
+		pkg.Ciphertexts = append(pkg.Ciphertexts, append([]byte{}, buf.Next(cn)...))
+	}
+
+	// Return the package.
+	return pkg, nil
+
+}
+
+// Export the sealed share to a byte slice. The encoding is the fingerprint of
+// the cryptosystem, the member identifier, the ephemeral key, and the
+// ciphertext.
+func (sealed SealedShare) ToBytes() []byte {
+	var buf bytes.Buffer
+	fingerprint := sealed.Ephemeral.system.Fingerprint()
+	buf.Write(fingerprint[:])
+	binary.Write(&buf, binary.BigEndian, uint32(sealed.MemberId))
+	buf.Write(sealed.Ephemeral.ToBytes())
+	buf.Write(sealed.Ciphertext)
+	return buf.Bytes()
+}
+
+// SealedShareFromBytes imports a sealed share from the provided byte slice. It
+// expects the data format exported by ToBytes.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func SealedShareFromBytes(system System, data []byte) (SealedShare, error) {
+
+	// Read the header.
+	var fingerprint [sha256.Size]byte
+	var memberId uint32
+	buf := bytes.NewBuffer(data)
+	if copy(fingerprint[:], buf.Next(sha256.Size)) != sha256.Size || binary.Read(buf, binary.BigEndian, &memberId) != nil {
+		return SealedShare{}, errors.New("bls.SealedShareFromBytes: Data too short.")
+	}
+	if fingerprint != system.Fingerprint() {
+		return SealedShare{}, errors.New("bls.SealedShareFromBytes: Share belongs to another system.")
+	}
+	kn := len(PublicKey{system, system.g}.ToBytes())
+	cn := int(C.pairing_length_in_bytes_Zr(system.pairing.get)) + 16
+	if buf.Len() != kn+cn {
+		return SealedShare{}, errors.New("bls.SealedShareFromBytes: Share length mismatch.")
+	}
+
+	// Read the ephemeral key and the ciphertext.
+	ephemeral, err := PublicKeyFromBytes(system, buf.Next(kn))
+	if err != nil {
+		return SealedShare{}, err
+	}
+	return SealedShare{int(memberId), ephemeral, append([]byte{}, buf.Bytes()...)}, nil
+
+}
+
+// Free the memory occupied by the sealed share.
+func (sealed SealedShare) Free() {
+	sealed.Ephemeral.Free()
+}
+
+// Free the memory occupied by the package.
+func (pkg SharePackage) Free() {
+	pkg.Ephemeral.Free()
+	for j := range pkg.Commitments {
+		pkg.Commitments[j].Free()
+	}
+}
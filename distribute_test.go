@@ -0,0 +1,183 @@
+/**
+ * File        : distribute_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for encrypted share distribution.
+ */
+
+package bls
+
+import (
+	"testing"
+)
+
+func TestSealShares(test *testing.T) {
+
+	t := 2
+	n := 3
+
+	// Generate the long-term keys of the members and the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	longTermKeys := make([]PublicKey, n)
+	longTermSecrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		longTermKeys[i], longTermSecrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	commitments, shares, err := Deal(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Seal the shares, round-trip the package, and open each share.
+	pkg, err := SealShares(commitments, shares, longTermKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	imported, err := SharePackageFromBytes(system, pkg.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		share, err := imported.Open(i, longTermSecrets[i])
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !share.Equal(shares[i]) {
+			test.Fatal("Opened share does not match.")
+		}
+		share.Free()
+	}
+
+	// A member cannot open the share of another.
+	_, err = imported.Open(1, longTermSecrets[0])
+	if err == nil {
+		test.Fatal("Opened the share of another member.")
+	}
+
+	// A share that does not match the commitments is rejected.
+	swapped := []PrivateKey{shares[1], shares[0], shares[2]}
+	bad, err := SealShares(commitments, swapped, longTermKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = bad.Open(0, longTermSecrets[0])
+	if err == nil {
+		test.Fatal("Accepted a share that does not match the commitments.")
+	}
+
+	// Clean up.
+	bad.Free()
+	imported.Free()
+	pkg.Free()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		shares[i].Free()
+		longTermKeys[i].Free()
+		longTermSecrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestRewrapShare(test *testing.T) {
+
+	t := 2
+	n := 3
+
+	// Generate the long-term keys of the members and seal the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	longTermKeys := make([]PublicKey, n)
+	longTermSecrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		longTermKeys[i], longTermSecrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	commitments, shares, err := Deal(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	pkg, err := SealShares(commitments, shares, longTermKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Member 2 extracts its share and rotates its long-term key.
+	sealed, err := pkg.Share(2)
+	if err != nil {
+		test.Fatal(err)
+	}
+	newKey, newSecret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	rewrapped, err := sealed.Rewrap(longTermSecrets[2], newKey)
+	if err != nil {
+		test.Fatal(err)
+	}
+	imported, err := SealedShareFromBytes(system, rewrapped.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Only the new key opens the rewrapped share.
+	share, err := imported.Open(newSecret, commitments)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !share.Equal(shares[2]) {
+		test.Fatal("Rewrapped share does not match.")
+	}
+	_, err = imported.Open(longTermSecrets[2], commitments)
+	if err == nil {
+		test.Fatal("Opened the rewrapped share with the old key.")
+	}
+
+	// Another member cannot rewrap the share.
+	_, err = sealed.Rewrap(longTermSecrets[0], newKey)
+	if err == nil {
+		test.Fatal("Rewrapped the share of another member.")
+	}
+
+	// Clean up.
+	share.Free()
+	imported.Free()
+	rewrapped.Free()
+	sealed.Free()
+	newKey.Free()
+	newSecret.Free()
+	pkg.Free()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		shares[i].Free()
+		longTermKeys[i].Free()
+		longTermSecrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
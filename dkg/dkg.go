@@ -0,0 +1,346 @@
+/**
+ * File        : dkg.go
+ * Description : Distributed key generation.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements the distributed key generation protocol of Pedersen,
+ * in its joint Feldman form, so that n parties can create a group key and
+ * threshold key shares without a trusted dealer. Each party deals a random
+ * secret to the others. A party that receives an invalid share complains, and
+ * the dealer answers the complaint by revealing the share. Dealers that fail
+ * to answer a complaint are disqualified, and the group secret is the sum of
+ * the secrets of the qualified dealers. No party ever learns it.
+ *
+ * The transport is left to the caller. Commitments, complaints, and answers
+ * must be broadcast, so that all parties agree on the qualified set, while
+ * shares must be sent over private authenticated channels.
+ */
+
+package dkg
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/enzoh/go-bls"
+)
+
+// A Participant runs the protocol on behalf of one party.
+type Participant struct {
+	system      bls.System
+	id          int
+	t           int
+	n           int
+	commitments []bls.PublicKey
+	shares      []bls.PrivateKey
+	received    map[int]deal
+	complaints  []int
+}
+
+// The commitments and the share received from a dealer.
+type deal struct {
+	commitments []bls.PublicKey
+	share       bls.PrivateKey
+	valid       bool
+}
+
+// The outcome of the protocol for one party.
+type Result struct {
+
+	// The group public key.
+	GroupKey bls.PublicKey
+
+	// The commitments to the group polynomial. The first commitment is the
+	// group public key.
+	Commitments []bls.PublicKey
+
+	// The public key shares of all parties, indexed by party.
+	MemberKeys []bls.PublicKey
+
+	// The private key share of this party.
+	Secret bls.PrivateKey
+
+	// The identifiers of the dealers whose deals make up the group secret, in
+	// increasing order. The other dealers were disqualified.
+	Qualified []int
+}
+
+// Create the participant with the given identifier, in the range [0, n), and
+// deal its secret. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+func NewParticipant(system bls.System, id int, t int, n int) (*Participant, error) {
+	if id < 0 || id >= n {
+		return nil, errors.New("dkg.NewParticipant: Identifier out of range.")
+	}
+	commitments, shares, err := bls.Deal(t, n, system)
+	if err != nil {
+		return nil, err
+	}
+	return &Participant{
+		system:      system,
+		id:          id,
+		t:           t,
+		n:           n,
+		commitments: commitments,
+		shares:      shares,
+		received:    make(map[int]deal),
+	}, nil
+}
+
+// Get the identifier of the participant.
+func (p *Participant) Id() int {
+	return p.id
+}
+
+// Get the commitments of the participant, to be broadcast to all parties.
+func (p *Participant) Commitments() []bls.PublicKey {
+	return p.commitments
+}
+
+// Get the share the participant deals to a party, to be sent over a private
+// channel.
+func (p *Participant) Share(id int) (bls.PrivateKey, error) {
+	if id < 0 || id >= p.n {
+		return bls.PrivateKey{}, errors.New("dkg.Share: Identifier out of range.")
+	}
+	return p.shares[id], nil
+}
+
+// Receive the commitments and the share of a dealer. The arguments are copied.
+// If the share does not match the commitments, the participant records a
+// complaint against the dealer, and an error is returned.
+func (p *Participant) Receive(dealer int, commitments []bls.PublicKey, share bls.PrivateKey) error {
+
+	// Check the dealer.
+	if dealer < 0 || dealer >= p.n {
+		return errors.New("dkg.Receive: Dealer out of range.")
+	}
+	if _, ok := p.received[dealer]; ok {
+		return errors.New("dkg.Receive: Duplicate deal.")
+	}
+	if len(commitments) != p.t {
+		p.complain(dealer)
+		return errors.New("dkg.Receive: Wrong number of commitments.")
+	}
+
+	// Copy the deal.
+	d, err := p.copyDeal(commitments, share)
+	if err != nil {
+		p.complain(dealer)
+		return err
+	}
+	d.valid, err = bls.VerifySecretShares(d.commitments, []int{p.id}, []bls.PrivateKey{d.share})
+	p.received[dealer] = d
+	if err != nil || !d.valid {
+		p.complain(dealer)
+		return errors.New("dkg.Receive: Invalid share.")
+	}
+	return nil
+
+}
+
+// Get the dealers the participant complains about, to be broadcast to all
+// parties.
+func (p *Participant) Complaints() []int {
+	return append([]int{}, p.complaints...)
+}
+
+// Answer a complaint against the participant by revealing the share of the
+// complainer, to be broadcast to all parties.
+func (p *Participant) Answer(complainer int) (bls.PrivateKey, error) {
+	return p.Share(complainer)
+}
+
+// Check the answer of a dealer to a complaint. If the revealed share matches
+// the commitments of the dealer, the complaint is dismissed, and a complainer
+// that is this participant adopts the share. Otherwise, the dealer must be
+// disqualified.
+func (p *Participant) CheckAnswer(dealer int, complainer int, share bls.PrivateKey) (bool, error) {
+
+	// Look up the commitments of the dealer.
+	d, ok := p.received[dealer]
+	if !ok || len(d.commitments) == 0 {
+		return false, errors.New("dkg.CheckAnswer: Unknown dealer.")
+	}
+	if complainer < 0 || complainer >= p.n {
+		return false, errors.New("dkg.CheckAnswer: Complainer out of range.")
+	}
+
+	// Check the revealed share.
+	valid, err := bls.VerifySecretShares(d.commitments, []int{complainer}, []bls.PrivateKey{share})
+	if err != nil || !valid {
+		return false, err
+	}
+
+	// Adopt the share.
+	if complainer == p.id && !d.valid {
+		s, err := bls.PrivateKeyFromBytes(p.system, share.ToBytes())
+		if err != nil {
+			return false, err
+		}
+		d.share.Free()
+		d.share = s
+		d.valid = true
+		p.received[dealer] = d
+		p.dismiss(dealer)
+	}
+	return true, nil
+
+}
+
+// Compute the result of the protocol from the qualified dealers, on which all
+// parties must agree. The participant must hold a valid share from each of
+// them. This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging for
+// the C structures to be freed.
+func (p *Participant) Finalize(qualified []int) (Result, error) {
+
+	// Collect the deals of the qualified dealers.
+	if len(qualified) == 0 {
+		return Result{}, errors.New("dkg.Finalize: Empty qualified set.")
+	}
+	seen := make(map[int]bool)
+	commitments := make([][]bls.PublicKey, len(qualified))
+	shares := make([]bls.PrivateKey, len(qualified))
+	for i, dealer := range qualified {
+		if seen[dealer] {
+			return Result{}, errors.New("dkg.Finalize: Duplicate dealer.")
+		}
+		seen[dealer] = true
+		d, ok := p.received[dealer]
+		if !ok || !d.valid {
+			return Result{}, errors.New("dkg.Finalize: No valid share from a qualified dealer.")
+		}
+		commitments[i] = d.commitments
+		shares[i] = d.share
+	}
+
+	// Combine the deals.
+	groupCommitments, err := bls.AddCommitments(commitments)
+	if err != nil {
+		return Result{}, err
+	}
+	secret, err := bls.AddPrivateKeys(shares)
+	if err != nil {
+		for _, commitment := range groupCommitments {
+			commitment.Free()
+		}
+		return Result{}, err
+	}
+
+	// Derive the group public key and the public key shares.
+	groupKey, err := bls.CommitmentKey(groupCommitments[:1], 0)
+	if err != nil {
+		for _, commitment := range groupCommitments {
+			commitment.Free()
+		}
+		secret.Zeroize()
+		return Result{}, err
+	}
+	memberKeys := make([]bls.PublicKey, p.n)
+	for i := range memberKeys {
+		memberKeys[i], _ = bls.CommitmentKey(groupCommitments, i)
+	}
+
+	// Return the result.
+	qualified = append([]int{}, qualified...)
+	sort.Ints(qualified)
+	return Result{groupKey, groupCommitments, memberKeys, secret, qualified}, nil
+
+}
+
+// Determine the qualified dealers from the complaints broadcast by all parties
+// and the outcome of their answers. A dealer is disqualified if any of its
+// answers failed, or if it did not answer a complaint.
+func Qualified(n int, complaints map[int][]int, answered map[[2]int]bool) []int {
+	disqualified := make(map[int]bool)
+	for complainer, dealers := range complaints {
+		for _, dealer := range dealers {
+			if !answered[[2]int{dealer, complainer}] {
+				disqualified[dealer] = true
+			}
+		}
+	}
+	qualified := make([]int, 0, n)
+	for dealer := 0; dealer < n; dealer++ {
+		if !disqualified[dealer] {
+			qualified = append(qualified, dealer)
+		}
+	}
+	sort.Ints(qualified)
+	return qualified
+}
+
+// Free the memory occupied by the participant, wiping the dealt and received
+// shares.
+func (p *Participant) Free() {
+	for i := range p.commitments {
+		p.commitments[i].Free()
+	}
+	for i := range p.shares {
+		p.shares[i].Zeroize()
+	}
+	for _, d := range p.received {
+		for i := range d.commitments {
+			d.commitments[i].Free()
+		}
+		d.share.Zeroize()
+	}
+}
+
+// Free the memory occupied by the result, wiping the private key share.
+func (result Result) Free() {
+	result.GroupKey.Free()
+	for i := range result.Commitments {
+		result.Commitments[i].Free()
+	}
+	for i := range result.MemberKeys {
+		result.MemberKeys[i].Free()
+	}
+	result.Secret.Zeroize()
+}
+
+// Copy a deal into the cryptosystem of the participant.
+func (p *Participant) copyDeal(commitments []bls.PublicKey, share bls.PrivateKey) (deal, error) {
+	d := deal{commitments: make([]bls.PublicKey, len(commitments))}
+	for i := range commitments {
+		key, err := bls.PublicKeyFromBytes(p.system, commitments[i].ToBytes())
+		if err != nil {
+			for j := 0; j < i; j++ {
+				d.commitments[j].Free()
+			}
+			return deal{}, err
+		}
+		d.commitments[i] = key
+	}
+	secret, err := bls.PrivateKeyFromBytes(p.system, share.ToBytes())
+	if err != nil {
+		for i := range d.commitments {
+			d.commitments[i].Free()
+		}
+		return deal{}, err
+	}
+	d.share = secret
+	return d, nil
+}
+
+func (p *Participant) complain(dealer int) {
+	for _, d := range p.complaints {
+		if d == dealer {
+			return
+		}
+	}
+	p.complaints = append(p.complaints, dealer)
+}
+
+func (p *Participant) dismiss(dealer int) {
+	for i, d := range p.complaints {
+		if d == dealer {
+			p.complaints = append(p.complaints[:i], p.complaints[i+1:]...)
+			return
+		}
+	}
+}
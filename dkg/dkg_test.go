@@ -0,0 +1,145 @@
+/**
+ * File        : dkg_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for distributed key generation.
+ */
+
+package dkg
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+func TestDKG(test *testing.T) {
+
+	t := 3
+	n := 5
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Create the participants.
+	participants := make([]*Participant, n)
+	for i := range participants {
+		participants[i], err = NewParticipant(system, i, t, n)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Exchange the deals. Dealer 3 sends party 0 the share of party 1 by
+	// mistake, and dealer 4 sends party 2 the share of party 1 and answers
+	// the complaint with it again.
+	for _, dealer := range participants {
+		for _, party := range participants {
+			id := party.Id()
+			if (dealer.Id() == 3 && id == 0) || (dealer.Id() == 4 && id == 2) {
+				id = 1
+			}
+			share, err := dealer.Share(id)
+			if err != nil {
+				test.Fatal(err)
+			}
+			err = party.Receive(dealer.Id(), dealer.Commitments(), share)
+			if (err == nil) != (id == party.Id()) {
+				test.Fatal("Unexpected outcome of receiving a share.")
+			}
+		}
+	}
+
+	// Broadcast the complaints and the answers.
+	complaints := make(map[int][]int)
+	answered := make(map[[2]int]bool)
+	for _, complainer := range participants {
+		complaints[complainer.Id()] = complainer.Complaints()
+		for _, dealer := range complainer.Complaints() {
+			id := complainer.Id()
+			if dealer == 4 {
+				id = 1
+			}
+			answer, err := participants[dealer].Answer(id)
+			if err != nil {
+				test.Fatal(err)
+			}
+			valid := true
+			for _, party := range participants {
+				ok, err := party.CheckAnswer(dealer, complainer.Id(), answer)
+				if err != nil {
+					test.Fatal(err)
+				}
+				valid = valid && ok
+			}
+			answered[[2]int{dealer, complainer.Id()}] = valid
+		}
+	}
+	if !reflect.DeepEqual(complaints[0], []int{3}) || !reflect.DeepEqual(complaints[2], []int{4}) {
+		test.Fatal("Unexpected complaints.")
+	}
+	if len(participants[0].Complaints()) != 0 {
+		test.Fatal("Complaint was not dismissed by a valid answer.")
+	}
+	qualified := Qualified(n, complaints, answered)
+	if !reflect.DeepEqual(qualified, []int{0, 1, 2, 3}) {
+		test.Fatal("Unexpected qualified set.")
+	}
+
+	// Compute the results. All parties must agree on the group key.
+	results := make([]Result, n)
+	for i, party := range participants {
+		results[i], err = party.Finalize(qualified)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !results[i].GroupKey.Equal(results[0].GroupKey) {
+			test.Fatal("Group keys do not match.")
+		}
+		public := results[i].Secret.Public()
+		if !public.Equal(results[0].MemberKeys[i]) {
+			test.Fatal("Public key share does not match.")
+		}
+		public.Free()
+	}
+
+	// A threshold signature must verify under the group key.
+	hash := sha256.Sum256([]byte(message))
+	memberIds := []int{0, 2, 4}
+	signatures := make([]bls.Signature, t)
+	for i, id := range memberIds {
+		signatures[i] = bls.Sign(hash, results[id].Secret)
+	}
+	signature, err := bls.Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bls.Verify(signature, hash, results[0].GroupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	for i := range participants {
+		results[i].Free()
+		participants[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
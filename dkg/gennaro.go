@@ -0,0 +1,641 @@
+/**
+ * File        : gennaro.go
+ * Description : Bias-resistant distributed key generation.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements the distributed key generation protocol of Gennaro,
+ * Jarecki, Krawczyk, and Rabin. In the joint Feldman protocol, a party that
+ * sees the commitments of the others before its own are checked can bias the
+ * group key by provoking its own disqualification. Here the parties first
+ * deal with Pedersen commitments, which hide the secrets, and agree on the
+ * qualified dealers. Only then do the qualified dealers reveal the Feldman
+ * commitments that determine the group key. A dealer whose revealed
+ * commitments do not match its shares is exposed, and its polynomial is
+ * reconstructed from the shares of the other parties, so the qualified set
+ * and the group key can no longer be influenced.
+ *
+ * The protocol runs in synchronous rounds over a Transport supplied by the
+ * caller, which must provide reliable broadcast and private authenticated
+ * channels. A message that is dropped is treated as misbehavior of its sender:
+ * a missing share draws a complaint, which the dealer must answer in public, a
+ * missing answer disqualifies the dealer, and a missing reveal exposes it. The
+ * protocol therefore completes as long as broadcasts reach all parties alike,
+ * and the dealers that were disqualified are absent from the qualified set of
+ * the result.
+ */
+
+package dkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/enzoh/go-bls"
+)
+
+// The rounds of the protocol.
+const (
+	RoundDeal = iota
+	RoundComplain
+	RoundAnswer
+	RoundReveal
+	RoundExpose
+	RoundReconstruct
+)
+
+// A Transport carries the messages of one party. Each round completes once the
+// messages of all parties have been delivered or have timed out. Messages are
+// indexed by party, including the party itself, and a missing message is nil.
+type Transport interface {
+
+	// Broadcast a message to all parties, and return the messages broadcast
+	// in the round. All parties must receive the same messages.
+	Broadcast(round int, message []byte) ([][]byte, error)
+
+	// Send a private message to each party, and return the messages sent to
+	// this party in the round.
+	Send(round int, messages [][]byte) ([][]byte, error)
+}
+
+// The state of one party in a run of the protocol.
+type gennaro struct {
+	system       bls.System
+	id           int
+	t            int
+	n            int
+	feldman      []bls.PublicKey
+	shares       []bls.PrivateKey
+	blinding     []bls.PrivateKey
+	commitments  [][]bls.PublicKey
+	revealed     [][]bls.PublicKey
+	received     []*record
+	valid        []bool
+	complaints   [][]int
+	disqualified []bool
+	exposed      []bool
+}
+
+// A share of a dealer, or a share revealed on behalf of a party.
+type record struct {
+	id       int
+	secret   bls.PrivateKey
+	blinding bls.PrivateKey
+}
+
+// A round of the protocol. The party produces a broadcast message and, in some
+// rounds, a private message for each party. Once the messages of all parties
+// have arrived, the party processes them.
+type round struct {
+	private bool
+	skip    func() bool
+	out     func() ([]byte, [][]byte, error)
+	in      func([][]byte, [][]byte) error
+}
+
+// Run the protocol as the party with the given identifier, in the range [0, n).
+// The group secret is shared with threshold t. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func RunGennaro(system bls.System, id int, t int, n int, transport Transport) (Result, error) {
+
+	// Check the identifier.
+	if id < 0 || id >= n {
+		return Result{}, errors.New("dkg.RunGennaro: Identifier out of range.")
+	}
+
+	// Run the rounds.
+	g := newGennaro(system, id, t, n)
+	defer g.free()
+	for r, round := range g.rounds() {
+		if round.skip != nil && round.skip() {
+			continue
+		}
+		message, messages, err := round.out()
+		if err != nil {
+			return Result{}, err
+		}
+		broadcasts, err := g.checkCount(transport.Broadcast(r, message))
+		if err != nil {
+			return Result{}, err
+		}
+		var received [][]byte
+		if round.private {
+			received, err = g.checkCount(transport.Send(r, messages))
+			if err != nil {
+				return Result{}, err
+			}
+		}
+		err = round.in(broadcasts, received)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Return the result.
+	return g.result()
+
+}
+
+func newGennaro(system bls.System, id int, t int, n int) *gennaro {
+	return &gennaro{
+		system:       system,
+		id:           id,
+		t:            t,
+		n:            n,
+		commitments:  make([][]bls.PublicKey, n),
+		revealed:     make([][]bls.PublicKey, n),
+		received:     make([]*record, n),
+		valid:        make([]bool, n),
+		disqualified: make([]bool, n),
+		exposed:      make([]bool, n),
+	}
+}
+
+// List the rounds of the protocol, indexed by their numbers.
+func (g *gennaro) rounds() []round {
+	return []round{
+		RoundDeal:        {private: true, out: g.dealOut, in: g.dealIn},
+		RoundComplain:    {out: g.complainOut, in: g.complainIn},
+		RoundAnswer:      {out: g.answerOut, in: g.answerIn},
+		RoundReveal:      {out: g.revealOut, in: g.revealIn},
+		RoundExpose:      {out: g.exposeOut, in: g.exposeIn},
+		RoundReconstruct: {skip: g.skipReconstruct, out: g.reconstructOut, in: g.reconstructIn},
+	}
+}
+
+// Deal the shares with Pedersen commitments.
+func (g *gennaro) dealOut() ([]byte, [][]byte, error) {
+	commitments, feldman, shares, blinding, err := bls.DealPedersen(g.t, g.n, g.system)
+	if err != nil {
+		return nil, nil, err
+	}
+	g.feldman, g.shares, g.blinding = feldman, shares, blinding
+	messages := make([][]byte, g.n)
+	for j := range messages {
+		messages[j] = pack([][]byte{shares[j].ToBytes(), blinding[j].ToBytes()})
+	}
+	message := encodeKeys(commitments)
+	freeKeys(commitments)
+	return message, messages, nil
+}
+
+// Check the commitments and the shares of the dealers. A dealer with malformed
+// commitments is disqualified outright.
+func (g *gennaro) dealIn(broadcasts [][]byte, received [][]byte) error {
+	var err error
+	for i := 0; i < g.n; i++ {
+		g.commitments[i], err = decodeKeys(g.system, broadcasts[i], g.t)
+		if err != nil {
+			g.disqualified[i] = true
+			continue
+		}
+		items, err := unpack(received[i])
+		if err != nil || len(items) != 2 {
+			continue
+		}
+		r, err := decodeRecord(g.system, append([][]byte{uint32Bytes(i)}, items...))
+		if err != nil {
+			continue
+		}
+		g.received[i] = r
+		g.valid[i], _ = bls.VerifyPedersenShare(g.commitments[i], g.id, r.secret, r.blinding)
+	}
+	return nil
+}
+
+// Complain about the dealers whose shares are invalid.
+func (g *gennaro) complainOut() ([]byte, [][]byte, error) {
+	var complaints [][]byte
+	for i := 0; i < g.n; i++ {
+		if !g.disqualified[i] && !g.valid[i] {
+			complaints = append(complaints, uint32Bytes(i))
+		}
+	}
+	return pack(complaints), nil, nil
+}
+
+// Record the complaints of all parties.
+func (g *gennaro) complainIn(broadcasts [][]byte, _ [][]byte) error {
+	g.complaints = make([][]int, g.n)
+	for j := range broadcasts {
+		items, err := unpack(broadcasts[j])
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			if len(item) == 4 && int(binary.BigEndian.Uint32(item)) < g.n {
+				g.complaints[j] = append(g.complaints[j], int(binary.BigEndian.Uint32(item)))
+			}
+		}
+	}
+	return nil
+}
+
+// Answer the complaints against this party by revealing the shares of the
+// complainers.
+func (g *gennaro) answerOut() ([]byte, [][]byte, error) {
+	var answers [][]byte
+	for j := range g.complaints {
+		if contains(g.complaints[j], g.id) {
+			answers = append(answers, encodeRecord(record{j, g.shares[j], g.blinding[j]}))
+		}
+	}
+	return pack(answers), nil, nil
+}
+
+// Check the answers of the dealers, and disqualify the dealers that fail to
+// answer.
+func (g *gennaro) answerIn(broadcasts [][]byte, _ [][]byte) error {
+	for i := range broadcasts {
+		if g.disqualified[i] {
+			continue
+		}
+		records := decodeRecords(g.system, broadcasts[i])
+		for j := range g.complaints {
+			if !contains(g.complaints[j], i) {
+				continue
+			}
+			k := find(records, j)
+			if k < 0 {
+				g.disqualified[i] = true
+				continue
+			}
+			valid, _ := bls.VerifyPedersenShare(g.commitments[i], j, records[k].secret, records[k].blinding)
+			if !valid {
+				g.disqualified[i] = true
+				continue
+			}
+			if j == g.id && !g.valid[i] {
+				if g.received[i] != nil {
+					g.received[i].free()
+				}
+				g.received[i], records[k] = records[k], nil
+				g.received[i].id = i
+				g.valid[i] = true
+			}
+		}
+		freeRecords(records)
+	}
+	return nil
+}
+
+// Reveal the Feldman commitments of this party if it is qualified.
+func (g *gennaro) revealOut() ([]byte, [][]byte, error) {
+	if g.disqualified[g.id] {
+		return nil, nil, nil
+	}
+	return encodeKeys(g.feldman), nil, nil
+}
+
+// Record the Feldman commitments of the qualified dealers, and expose those
+// that fail to reveal them.
+func (g *gennaro) revealIn(broadcasts [][]byte, _ [][]byte) error {
+	var err error
+	for i := range broadcasts {
+		if g.disqualified[i] {
+			continue
+		}
+		g.revealed[i], err = decodeKeys(g.system, broadcasts[i], g.t)
+		if err != nil {
+			g.exposed[i] = true
+		}
+	}
+	return nil
+}
+
+// Reveal the shares that do not match the revealed commitments.
+func (g *gennaro) exposeOut() ([]byte, [][]byte, error) {
+	var complaints [][]byte
+	for i := 0; i < g.n; i++ {
+		if g.disqualified[i] || g.exposed[i] || !g.valid[i] {
+			continue
+		}
+		valid, _ := bls.VerifySecretShares(g.revealed[i], []int{g.id}, []bls.PrivateKey{g.received[i].secret})
+		if !valid {
+			complaints = append(complaints, encodeRecord(*g.received[i]))
+		}
+	}
+	return pack(complaints), nil, nil
+}
+
+// Expose the dealers whose commitments are contradicted. A complaint is
+// justified if the share matches the Pedersen commitments but not the Feldman
+// commitments.
+func (g *gennaro) exposeIn(broadcasts [][]byte, _ [][]byte) error {
+	for j := range broadcasts {
+		records := decodeRecords(g.system, broadcasts[j])
+		for _, r := range records {
+			i := r.id
+			if i >= g.n || g.disqualified[i] || g.exposed[i] {
+				continue
+			}
+			pedersen, _ := bls.VerifyPedersenShare(g.commitments[i], j, r.secret, r.blinding)
+			feldman, _ := bls.VerifySecretShares(g.revealed[i], []int{j}, []bls.PrivateKey{r.secret})
+			if pedersen && !feldman {
+				g.exposed[i] = true
+			}
+		}
+		freeRecords(records)
+	}
+	return nil
+}
+
+// Skip the reconstruction if no dealer is exposed. All parties agree on this.
+func (g *gennaro) skipReconstruct() bool {
+	return !anySet(g.exposed)
+}
+
+// Reveal the shares of the exposed dealers.
+func (g *gennaro) reconstructOut() ([]byte, [][]byte, error) {
+	var shares [][]byte
+	for i := range g.exposed {
+		if g.exposed[i] && g.valid[i] {
+			shares = append(shares, encodeRecord(*g.received[i]))
+		}
+	}
+	return pack(shares), nil, nil
+}
+
+// Reconstruct the polynomials of the exposed dealers from the shares of all
+// parties, and replace their Feldman commitments.
+func (g *gennaro) reconstructIn(broadcasts [][]byte, _ [][]byte) error {
+
+	// Collect t valid shares for each exposed dealer.
+	points := make([][]*record, g.n)
+	ids := make([][]int, g.n)
+	for j := range broadcasts {
+		records := decodeRecords(g.system, broadcasts[j])
+		for k, r := range records {
+			i := r.id
+			if i >= g.n || !g.exposed[i] || len(points[i]) == g.t || contains(ids[i], j) {
+				continue
+			}
+			valid, _ := bls.VerifyPedersenShare(g.commitments[i], j, r.secret, r.blinding)
+			if valid {
+				points[i] = append(points[i], r)
+				ids[i] = append(ids[i], j)
+				records[k] = nil
+			}
+		}
+		freeRecords(records)
+	}
+	defer func() {
+		for i := range points {
+			freeRecords(points[i])
+		}
+	}()
+
+	// Interpolate the polynomials and commit to their coefficients.
+	for i := range g.exposed {
+		if !g.exposed[i] {
+			continue
+		}
+		if len(points[i]) < g.t {
+			return errors.New("dkg.RunGennaro: Too few shares to reconstruct an exposed dealer.")
+		}
+		secrets := make([]bls.PrivateKey, g.t)
+		for k := range secrets {
+			secrets[k] = points[i][k].secret
+		}
+		coeffs, err := bls.InterpolateCoefficients(secrets, ids[i], g.system)
+		if err != nil {
+			return err
+		}
+		freeKeys(g.revealed[i])
+		g.revealed[i] = make([]bls.PublicKey, g.t)
+		for k := range coeffs {
+			g.revealed[i][k] = coeffs[k].Public()
+			coeffs[k].Zeroize()
+		}
+	}
+	return nil
+
+}
+
+// Combine the shares and the Feldman commitments of the qualified dealers.
+func (g *gennaro) result() (Result, error) {
+
+	// Collect the qualified deals.
+	var commitments [][]bls.PublicKey
+	var shares []bls.PrivateKey
+	var qualified []int
+	for i := 0; i < g.n; i++ {
+		if g.disqualified[i] {
+			continue
+		}
+		if !g.valid[i] || g.revealed[i] == nil {
+			return Result{}, errors.New("dkg.RunGennaro: No valid share from a qualified dealer.")
+		}
+		commitments = append(commitments, g.revealed[i])
+		shares = append(shares, g.received[i].secret)
+		qualified = append(qualified, i)
+	}
+	if len(shares) == 0 {
+		return Result{}, errors.New("dkg.RunGennaro: Empty qualified set.")
+	}
+
+	// Combine the deals.
+	groupCommitments, err := bls.AddCommitments(commitments)
+	if err != nil {
+		return Result{}, err
+	}
+	secret, err := bls.AddPrivateKeys(shares)
+	if err != nil {
+		freeKeys(groupCommitments)
+		return Result{}, err
+	}
+
+	// Derive the group public key and the public key shares.
+	groupKey, _ := bls.CommitmentKey(groupCommitments[:1], 0)
+	memberKeys := make([]bls.PublicKey, g.n)
+	for i := range memberKeys {
+		memberKeys[i], _ = bls.CommitmentKey(groupCommitments, i)
+	}
+
+	// Return the result.
+	return Result{groupKey, groupCommitments, memberKeys, secret, qualified}, nil
+
+}
+
+// Check the number of messages received in a round.
+func (g *gennaro) checkCount(messages [][]byte, err error) ([][]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) != g.n {
+		return nil, errors.New("dkg.RunGennaro: Wrong number of messages.")
+	}
+	return messages, nil
+}
+
+// Free the memory occupied by the state, wiping the shares.
+func (g *gennaro) free() {
+	freeKeys(g.feldman)
+	for j := range g.shares {
+		g.shares[j].Zeroize()
+		g.blinding[j].Zeroize()
+	}
+	for i := 0; i < g.n; i++ {
+		freeKeys(g.commitments[i])
+		freeKeys(g.revealed[i])
+	}
+	freeRecords(g.received)
+}
+
+func (r *record) free() {
+	r.secret.Zeroize()
+	r.blinding.Zeroize()
+}
+
+func freeRecords(records []*record) {
+	for _, r := range records {
+		if r != nil {
+			r.free()
+		}
+	}
+}
+
+func freeKeys(keys []bls.PublicKey) {
+	for i := range keys {
+		keys[i].Free()
+	}
+}
+
+// Encode a list of byte slices, each prefixed by its length.
+func pack(items [][]byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(items)))
+	for _, item := range items {
+		binary.Write(&buf, binary.BigEndian, uint32(len(item)))
+		buf.Write(item)
+	}
+	return buf.Bytes()
+}
+
+// Decode a list of byte slices encoded by pack.
+func unpack(data []byte) ([][]byte, error) {
+	buf := bytes.NewBuffer(data)
+	var count uint32
+	if binary.Read(buf, binary.BigEndian, &count) != nil || int(count) > buf.Len()/4 {
+		return nil, errors.New("dkg.RunGennaro: Malformed message.")
+	}
+	items := make([][]byte, count)
+	for i := range items {
+		var length uint32
+		if binary.Read(buf, binary.BigEndian, &length) != nil || int(length) > buf.Len() {
+			return nil, errors.New("dkg.RunGennaro: Malformed message.")
+		}
+		items[i] = buf.Next(int(length))
+	}
+	if buf.Len() != 0 {
+		return nil, errors.New("dkg.RunGennaro: Malformed message.")
+	}
+	return items, nil
+}
+
+func encodeKeys(keys []bls.PublicKey) []byte {
+	items := make([][]byte, len(keys))
+	for i := range keys {
+		items[i] = keys[i].ToBytes()
+	}
+	return pack(items)
+}
+
+// Decode a list of t public keys encoded by encodeKeys.
+func decodeKeys(system bls.System, data []byte, t int) ([]bls.PublicKey, error) {
+	items, err := unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != t {
+		return nil, errors.New("dkg.RunGennaro: Wrong number of commitments.")
+	}
+	keys := make([]bls.PublicKey, t)
+	for i := range items {
+		keys[i], err = bls.PublicKeyFromBytes(system, items[i])
+		if err != nil {
+			freeKeys(keys[:i])
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func encodeRecord(r record) []byte {
+	return pack([][]byte{uint32Bytes(r.id), r.secret.ToBytes(), r.blinding.ToBytes()})
+}
+
+// Decode a record from its three fields.
+func decodeRecord(system bls.System, items [][]byte) (*record, error) {
+	if len(items) != 3 || len(items[0]) != 4 {
+		return nil, errors.New("dkg.RunGennaro: Malformed record.")
+	}
+	secret, err := bls.PrivateKeyFromBytes(system, items[1])
+	if err != nil {
+		return nil, err
+	}
+	blinding, err := bls.PrivateKeyFromBytes(system, items[2])
+	if err != nil {
+		secret.Zeroize()
+		return nil, err
+	}
+	return &record{int(binary.BigEndian.Uint32(items[0])), secret, blinding}, nil
+}
+
+// Decode a list of records, skipping the malformed ones.
+func decodeRecords(system bls.System, data []byte) []*record {
+	items, err := unpack(data)
+	if err != nil {
+		return nil
+	}
+	var records []*record
+	for _, item := range items {
+		fields, err := unpack(item)
+		if err != nil {
+			continue
+		}
+		r, err := decodeRecord(system, fields)
+		if err == nil {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Find the record with the given identifier.
+func find(records []*record, id int) int {
+	for k, r := range records {
+		if r != nil && r.id == id {
+			return k
+		}
+	}
+	return -1
+}
+
+func contains(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func anySet(flags []bool) bool {
+	for _, flag := range flags {
+		if flag {
+			return true
+		}
+	}
+	return false
+}
+
+func uint32Bytes(x int) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(x))
+	return b[:]
+}
@@ -0,0 +1,207 @@
+/**
+ * File        : gennaro_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for bias-resistant distributed key
+ * generation.
+ */
+
+package dkg
+
+import (
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+// An in-memory network that delivers the messages of a round once all parties
+// have sent theirs.
+type network struct {
+	sync.Mutex
+	cond     *sync.Cond
+	n        int
+	messages map[[2]int][][][]byte
+	count    map[[2]int]int
+}
+
+type endpoint struct {
+	net *network
+	id  int
+}
+
+func newNetwork(n int) *network {
+	net := &network{n: n, messages: make(map[[2]int][][][]byte), count: make(map[[2]int]int)}
+	net.cond = sync.NewCond(net)
+	return net
+}
+
+// Deliver the messages of a party, indexed by recipient, and wait for the
+// messages of the others.
+func (net *network) exchange(key [2]int, from int, messages [][]byte) [][]byte {
+	net.Lock()
+	defer net.Unlock()
+	if net.messages[key] == nil {
+		net.messages[key] = make([][][]byte, net.n)
+		for j := range net.messages[key] {
+			net.messages[key][j] = make([][]byte, net.n)
+		}
+	}
+	for j := range messages {
+		net.messages[key][j][from] = messages[j]
+	}
+	net.count[key]++
+	net.cond.Broadcast()
+	for net.count[key] < net.n {
+		net.cond.Wait()
+	}
+	return net.messages[key][from]
+}
+
+func (e endpoint) Broadcast(round int, message []byte) ([][]byte, error) {
+	messages := make([][]byte, e.net.n)
+	for j := range messages {
+		messages[j] = message
+	}
+	return e.net.exchange([2]int{round, 0}, e.id, messages), nil
+}
+
+func (e endpoint) Send(round int, messages [][]byte) ([][]byte, error) {
+	return e.net.exchange([2]int{round, 1}, e.id, messages), nil
+}
+
+// A transport that lets a test rewrite the outgoing messages of a party.
+type tamper struct {
+	Transport
+	broadcast func(round int, message []byte) []byte
+	send      func(round int, messages [][]byte) [][]byte
+}
+
+func (e tamper) Broadcast(round int, message []byte) ([][]byte, error) {
+	if e.broadcast != nil {
+		message = e.broadcast(round, message)
+	}
+	return e.Transport.Broadcast(round, message)
+}
+
+func (e tamper) Send(round int, messages [][]byte) ([][]byte, error) {
+	if e.send != nil {
+		messages = e.send(round, messages)
+	}
+	return e.Transport.Send(round, messages)
+}
+
+func TestGennaro(test *testing.T) {
+
+	t := 3
+	n := 5
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Party 4 sends party 0 the share of party 1, but answers the complaint.
+	// Party 2 sends party 1 the share of party 3, and does not answer the
+	// complaint, so it is disqualified. Party 3 reveals its Pedersen
+	// commitments in place of its Feldman commitments, so it is exposed and
+	// its polynomial is reconstructed.
+	net := newNetwork(n)
+	transports := make([]Transport, n)
+	for i := range transports {
+		transports[i] = endpoint{net, i}
+	}
+	transports[4] = tamper{Transport: transports[4], send: func(round int, messages [][]byte) [][]byte {
+		messages[0] = messages[1]
+		return messages
+	}}
+	transports[2] = tamper{
+		Transport: transports[2],
+		send: func(round int, messages [][]byte) [][]byte {
+			messages[1] = messages[3]
+			return messages
+		},
+		broadcast: func(round int, message []byte) []byte {
+			if round == RoundAnswer {
+				return pack(nil)
+			}
+			return message
+		},
+	}
+	var pedersen []byte
+	transports[3] = tamper{Transport: transports[3], broadcast: func(round int, message []byte) []byte {
+		switch round {
+		case RoundDeal:
+			pedersen = message
+		case RoundReveal:
+			return pedersen
+		}
+		return message
+	}}
+
+	// Run the protocol.
+	results := make([]Result, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = RunGennaro(system, i, t, n, transports[i])
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			test.Fatal(errs[i])
+		}
+	}
+
+	// All parties must agree on the group key, and the key shares must match.
+	for i := 0; i < n; i++ {
+		if !results[i].GroupKey.Equal(results[0].GroupKey) {
+			test.Fatal("Group keys do not match.")
+		}
+		public := results[i].Secret.Public()
+		if !public.Equal(results[0].MemberKeys[i]) {
+			test.Fatal("Public key share does not match.")
+		}
+		public.Free()
+	}
+
+	// A threshold signature must verify under the group key.
+	hash := sha256.Sum256([]byte(message))
+	memberIds := []int{0, 1, 3}
+	signatures := make([]bls.Signature, t)
+	for i, id := range memberIds {
+		signatures[i] = bls.Sign(hash, results[id].Secret)
+	}
+	signature, err := bls.Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bls.Verify(signature, hash, results[0].GroupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	for i := range results {
+		results[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
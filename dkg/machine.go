@@ -0,0 +1,267 @@
+/**
+ * File        : machine.go
+ * Description : Transport-agnostic distributed key generation.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module exposes the protocol of RunGennaro as a state machine, for
+ * integrators whose transport does not fit the blocking Transport interface,
+ * such as gRPC streams, libp2p topics, or a consensus layer. The machine
+ * produces messages with NextMessages and consumes them with ProcessMessage.
+ * Messages are versioned and serializable, and messages that arrive early are
+ * held until their round begins.
+ */
+
+package dkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/enzoh/go-bls"
+)
+
+// The version of the message encoding.
+const MessageVersion = 1
+
+// The recipient of a broadcast message.
+const ToAll = -1
+
+// A Message is a protocol message of one party in one round.
+type Message struct {
+	Round   int
+	From    int
+	To      int
+	Payload []byte
+}
+
+// A Machine runs the protocol of RunGennaro for one party.
+type Machine struct {
+	g          *gennaro
+	rounds     []round
+	round      int
+	sent       bool
+	broadcasts [][]byte
+	received   [][]byte
+	seen       map[[2]int]bool
+	pending    []Message
+	done       bool
+	freed      bool
+	result     Result
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the
+// version, the round, the sender, the recipient, and the payload.
+func (msg Message) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(MessageVersion)
+	binary.Write(&buf, binary.BigEndian, [3]int32{int32(msg.Round), int32(msg.From), int32(msg.To)})
+	buf.Write(msg.Payload)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (msg *Message) UnmarshalBinary(data []byte) error {
+	if len(data) < 13 {
+		return errors.New("dkg.UnmarshalBinary: Data too short.")
+	}
+	if data[0] != MessageVersion {
+		return errors.New("dkg.UnmarshalBinary: Unknown version.")
+	}
+	var header [3]int32
+	binary.Read(bytes.NewReader(data[1:13]), binary.BigEndian, &header)
+	msg.Round, msg.From, msg.To = int(header[0]), int(header[1]), int(header[2])
+	msg.Payload = append([]byte{}, data[13:]...)
+	return nil
+}
+
+// Create the state machine of the party with the given identifier, in the
+// range [0, n). The group secret is shared with threshold t.
+func NewMachine(system bls.System, id int, t int, n int) (*Machine, error) {
+	if id < 0 || id >= n {
+		return nil, errors.New("dkg.NewMachine: Identifier out of range.")
+	}
+	if t < 1 || n < t {
+		return nil, errors.New("dkg.NewMachine: Bad threshold parameters.")
+	}
+	m := &Machine{g: newGennaro(system, id, t, n)}
+	m.rounds = m.g.rounds()
+	m.reset()
+	return m, nil
+}
+
+// Get the messages of this party for the current round, to be delivered to
+// their recipients. A broadcast message is addressed to ToAll. The messages
+// are produced once per round, and nil is returned until the round completes.
+func (m *Machine) NextMessages() ([]Message, error) {
+
+	// Check the state.
+	if m.done || m.sent || m.freed {
+		return nil, nil
+	}
+
+	// Produce the messages.
+	message, messages, err := m.rounds[m.round].out()
+	if err != nil {
+		return nil, err
+	}
+	m.sent = true
+	id := m.g.id
+	out := []Message{{m.round, id, ToAll, message}}
+	if m.rounds[m.round].private {
+		for j := range messages {
+			if j != id {
+				out = append(out, Message{m.round, id, j, messages[j]})
+			}
+		}
+	}
+
+	// Deliver the messages to this party.
+	m.broadcasts[id] = message
+	m.seen[[2]int{id, ToAll}] = true
+	if m.rounds[m.round].private {
+		m.received[id] = messages[id]
+		m.seen[[2]int{id, id}] = true
+	}
+
+	// Return the messages.
+	return out, m.advance()
+
+}
+
+// Process a message of another party. Messages of later rounds are held until
+// their round begins, and messages of earlier rounds are rejected.
+func (m *Machine) ProcessMessage(msg Message) error {
+
+	// Check the message.
+	g := m.g
+	if msg.From < 0 || msg.From >= g.n || msg.From == g.id {
+		return errors.New("dkg.ProcessMessage: Sender out of range.")
+	}
+	if msg.To != ToAll && msg.To != g.id {
+		return errors.New("dkg.ProcessMessage: Message is for another party.")
+	}
+	if m.done || m.freed || msg.Round < m.round {
+		return errors.New("dkg.ProcessMessage: Stale message.")
+	}
+	if msg.Round >= len(m.rounds) {
+		return errors.New("dkg.ProcessMessage: Unknown round.")
+	}
+	if msg.Round > m.round {
+		m.pending = append(m.pending, msg)
+		return nil
+	}
+	if msg.To != ToAll && !m.rounds[m.round].private {
+		return errors.New("dkg.ProcessMessage: Unexpected private message.")
+	}
+
+	// Store the message.
+	key := [2]int{msg.From, msg.To}
+	if m.seen[key] {
+		return errors.New("dkg.ProcessMessage: Duplicate message.")
+	}
+	m.seen[key] = true
+	if msg.To == ToAll {
+		m.broadcasts[msg.From] = msg.Payload
+	} else {
+		m.received[msg.From] = msg.Payload
+	}
+	return m.advance()
+
+}
+
+// Complete the current round without the messages that have not arrived, for
+// use when the deadline of the round has passed. The missing parties are
+// treated as having sent nothing.
+func (m *Machine) Timeout() error {
+	if m.done || m.freed || !m.sent {
+		return errors.New("dkg.Timeout: Round has not started.")
+	}
+	return m.complete()
+}
+
+// Determine whether the protocol has completed.
+func (m *Machine) Done() bool {
+	return m.done
+}
+
+// Get the result of the protocol once it has completed. The caller owns the
+// result.
+func (m *Machine) Result() (Result, error) {
+	if !m.done {
+		return Result{}, errors.New("dkg.Result: Protocol has not completed.")
+	}
+	return m.result, nil
+}
+
+// Free the memory occupied by the state of the protocol. The result is not
+// freed.
+func (m *Machine) Free() {
+	if !m.freed {
+		m.g.free()
+		m.freed = true
+	}
+}
+
+// Complete the current round if all messages have arrived.
+func (m *Machine) advance() error {
+	if !m.sent {
+		return nil
+	}
+	want := m.g.n
+	if m.rounds[m.round].private {
+		want *= 2
+	}
+	if len(m.seen) < want {
+		return nil
+	}
+	return m.complete()
+}
+
+// Process the messages of the current round and begin the next round.
+func (m *Machine) complete() error {
+
+	// Process the messages.
+	err := m.rounds[m.round].in(m.broadcasts, m.received)
+	if err != nil {
+		return err
+	}
+
+	// Begin the next round that is not skipped.
+	m.round++
+	for m.round < len(m.rounds) && m.rounds[m.round].skip != nil && m.rounds[m.round].skip() {
+		m.round++
+	}
+	m.reset()
+	if m.round == len(m.rounds) {
+		m.result, err = m.g.result()
+		if err != nil {
+			return err
+		}
+		m.done = true
+		m.Free()
+		return nil
+	}
+
+	// Replay the messages held for the round.
+	pending := m.pending
+	m.pending = nil
+	for _, msg := range pending {
+		err = m.ProcessMessage(msg)
+		if err != nil && msg.Round == m.round {
+			return err
+		}
+	}
+	return nil
+
+}
+
+// Clear the messages of the current round.
+func (m *Machine) reset() {
+	m.sent = false
+	m.broadcasts = make([][]byte, m.g.n)
+	m.received = make([][]byte, m.g.n)
+	m.seen = make(map[[2]int]bool)
+}
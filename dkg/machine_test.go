@@ -0,0 +1,255 @@
+/**
+ * File        : machine_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the distributed key generation state
+ * machine.
+ */
+
+package dkg
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+// Deliver serialized messages in random order until all live parties are
+// done, timing out rounds that cannot complete. The messages for which drop
+// returns true are lost.
+func runMachines(test *testing.T, machines []*Machine, live func(int) bool, drop func(Message) bool) {
+	var queue [][]byte
+	for {
+		progress := false
+		for i, m := range machines {
+			if !live(i) {
+				continue
+			}
+			messages, err := m.NextMessages()
+			if err != nil {
+				test.Fatal(err)
+			}
+			for _, msg := range messages {
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					test.Fatal(err)
+				}
+				queue = append(queue, data)
+				progress = true
+			}
+		}
+		rand.Shuffle(len(queue), func(i, j int) {
+			queue[i], queue[j] = queue[j], queue[i]
+		})
+		for len(queue) > 0 {
+			var msg Message
+			err := msg.UnmarshalBinary(queue[0])
+			if err != nil {
+				test.Fatal(err)
+			}
+			queue = queue[1:]
+			if drop != nil && drop(msg) {
+				continue
+			}
+			for i, m := range machines {
+				if live(i) && i != msg.From && (msg.To == ToAll || msg.To == i) {
+					err := m.ProcessMessage(msg)
+					if err != nil {
+						test.Fatal(err)
+					}
+					progress = true
+				}
+			}
+		}
+		finished := true
+		for i, m := range machines {
+			finished = finished && (!live(i) || m.Done())
+		}
+		if finished {
+			break
+		}
+		if !progress {
+			for i, m := range machines {
+				if live(i) && !m.Done() {
+					err := m.Timeout()
+					if err != nil {
+						test.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMachine(test *testing.T) {
+
+	t := 3
+	n := 4
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Create the machines. Party 3 crashes after dealing.
+	machines := make([]*Machine, n)
+	for i := range machines {
+		machines[i], err = NewMachine(system, i, t, n)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	crashed := 3
+	live := func(i int) bool {
+		return i != crashed || machines[i].round == RoundDeal
+	}
+
+	// Run the protocol.
+	runMachines(test, machines, live, nil)
+
+	// The live parties agree on the group key and can sign.
+	results := make([]Result, n-1)
+	for i := range results {
+		results[i], err = machines[i].Result()
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !results[i].GroupKey.Equal(results[0].GroupKey) {
+			test.Fatal("Group keys do not match.")
+		}
+	}
+	hash := sha256.Sum256([]byte(message))
+	memberIds := []int{0, 1, 2}
+	signatures := make([]bls.Signature, t)
+	for i, id := range memberIds {
+		signatures[i] = bls.Sign(hash, results[id].Secret)
+	}
+	signature, err := bls.Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bls.Verify(signature, hash, results[0].GroupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// A message of another version is rejected.
+	data, _ := Message{RoundDeal, 0, ToAll, nil}.MarshalBinary()
+	data[0]++
+	var msg Message
+	if msg.UnmarshalBinary(data) == nil {
+		test.Fatal("Accepted a message of another version.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	for i := range results {
+		results[i].Free()
+	}
+	for i := range machines {
+		machines[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestMachineDroppedMessages(test *testing.T) {
+
+	t := 3
+	n := 4
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Create the machines.
+	machines := make([]*Machine, n)
+	for i := range machines {
+		machines[i], err = NewMachine(system, i, t, n)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// The shares of party 1 for party 3 and of party 2 for party 0 are lost.
+	// Party 1 answers the complaint, but the answer of party 2 is lost as
+	// well, so party 2 is disqualified.
+	drop := func(msg Message) bool {
+		switch {
+		case msg.Round == RoundDeal && msg.From == 1 && msg.To == 3:
+			return true
+		case msg.Round == RoundDeal && msg.From == 2 && msg.To == 0:
+			return true
+		case msg.Round == RoundAnswer && msg.From == 2:
+			return true
+		}
+		return false
+	}
+	runMachines(test, machines, func(int) bool { return true }, drop)
+
+	// The other parties agree on the group key and the qualified set.
+	memberIds := []int{0, 1, 3}
+	results := make([]Result, n)
+	for i := range results {
+		results[i], err = machines[i].Result()
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	for _, id := range memberIds {
+		if !results[id].GroupKey.Equal(results[0].GroupKey) {
+			test.Fatal("Group keys do not match.")
+		}
+		if len(results[id].Qualified) != 3 || results[id].Qualified[2] != 3 {
+			test.Fatal("Qualified set does not match.")
+		}
+	}
+
+	// The other parties can sign.
+	hash := sha256.Sum256([]byte(message))
+	signatures := make([]bls.Signature, t)
+	for i, id := range memberIds {
+		signatures[i] = bls.Sign(hash, results[id].Secret)
+	}
+	signature, err := bls.Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bls.Verify(signature, hash, results[0].GroupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	for i := range results {
+		results[i].Free()
+	}
+	for i := range machines {
+		machines[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,123 @@
+/**
+ * File        : reshare.go
+ * Description : Resharing to a new group.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module converts a t-of-n sharing of the group secret into a t'-of-n'
+ * sharing held by a possibly different group, without changing the group
+ * public key. At least t old members each deal their own share to the new
+ * members with Feldman commitments. The first commitment of each deal must
+ * match the public key share of the dealer, and each new member combines the
+ * shares it receives with the Lagrange coefficients of the dealers. The group
+ * secret is never reconstructed, and the old shares are useless once the new
+ * group has taken over, provided the old members erase them.
+ */
+
+package dkg
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/enzoh/go-bls"
+)
+
+// Deal the share of an old member to n new members with threshold t. The
+// share at index j belongs to new member j. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func Reshare(secret bls.PrivateKey, t int, n int) ([]bls.PublicKey, []bls.PrivateKey, error) {
+	return bls.DealSecret(secret, t, n)
+}
+
+// Verify a deal received by a new member against the public key share of the
+// old member that dealt it. A new member should complain about a dealer whose
+// deal fails, and the new group must agree on a set of dealers that excludes
+// it.
+func VerifyReshare(dealerKey bls.PublicKey, commitments []bls.PublicKey, id int, share bls.PrivateKey) (bool, error) {
+	if len(commitments) == 0 {
+		return false, errors.New("dkg.VerifyReshare: Empty list.")
+	}
+	if !commitments[0].Equal(dealerKey) {
+		return false, nil
+	}
+	return bls.VerifyKeyShare(id, share, commitments)
+}
+
+// Compute the result of resharing for new member id of n, from the deals of
+// the old members with the given identifiers. The commitments and the share
+// at index k come from the old member at dealerIds[k], and the dealers must
+// number at least the old threshold. The recovered group key must match the
+// old group key. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+func CombineReshares(groupKey bls.PublicKey, dealerIds []int, dealerKeys []bls.PublicKey, commitments [][]bls.PublicKey, shares []bls.PrivateKey, id int, n int) (Result, error) {
+
+	// Check the list length.
+	if len(dealerIds) == 0 {
+		return Result{}, errors.New("dkg.CombineReshares: Empty list.")
+	}
+	if len(dealerKeys) != len(dealerIds) || len(commitments) != len(dealerIds) || len(shares) != len(dealerIds) {
+		return Result{}, errors.New("dkg.CombineReshares: List length mismatch.")
+	}
+	if id < 0 || id >= n {
+		return Result{}, errors.New("dkg.CombineReshares: Identifier out of range.")
+	}
+
+	// Check the deals.
+	t := len(commitments[0])
+	for k := range dealerIds {
+		if len(commitments[k]) != t {
+			return Result{}, errors.New("dkg.CombineReshares: Threshold mismatch.")
+		}
+		valid, err := VerifyReshare(dealerKeys[k], commitments[k], id, shares[k])
+		if err != nil {
+			return Result{}, err
+		}
+		if !valid {
+			return Result{}, errors.New("dkg.CombineReshares: Invalid deal.")
+		}
+	}
+
+	// Interpolate the commitments of the new group polynomial.
+	groupCommitments := make([]bls.PublicKey, t)
+	column := make([]bls.PublicKey, len(dealerIds))
+	for j := range groupCommitments {
+		for k := range commitments {
+			column[k] = commitments[k][j]
+		}
+		commitment, err := bls.RecoverPublicKey(column, dealerIds)
+		if err != nil {
+			freeKeys(groupCommitments[:j])
+			return Result{}, err
+		}
+		groupCommitments[j] = commitment
+	}
+	if !groupCommitments[0].Equal(groupKey) {
+		freeKeys(groupCommitments)
+		return Result{}, errors.New("dkg.CombineReshares: Too few dealers to recover the group key.")
+	}
+
+	// Interpolate the new private key share.
+	secret, err := bls.RecoverPrivateKey(shares, dealerIds)
+	if err != nil {
+		freeKeys(groupCommitments)
+		return Result{}, err
+	}
+
+	// Derive the group public key and the public key shares.
+	newGroupKey, _ := bls.CommitmentKey(groupCommitments[:1], 0)
+	memberKeys := make([]bls.PublicKey, n)
+	for i := range memberKeys {
+		memberKeys[i], _ = bls.CommitmentKey(groupCommitments, i)
+	}
+
+	// Return the result.
+	qualified := append([]int{}, dealerIds...)
+	sort.Ints(qualified)
+	return Result{newGroupKey, groupCommitments, memberKeys, secret, qualified}, nil
+
+}
@@ -0,0 +1,123 @@
+/**
+ * File        : reshare_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for resharing.
+ */
+
+package dkg
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+func TestReshare(test *testing.T) {
+
+	t, n := 2, 3
+	tNew, nNew := 3, 4
+	message := "This is a message."
+
+	// Generate a 2-of-3 sharing.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := bls.GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupSecret.Zeroize()
+
+	// Old members 0 and 2 reshare to a 3-of-4 group.
+	dealerIds := []int{0, 2}
+	dealerKeys := []bls.PublicKey{memberKeys[0], memberKeys[2]}
+	deals := make([][]bls.PublicKey, len(dealerIds))
+	shares := make([][]bls.PrivateKey, len(dealerIds))
+	for k, id := range dealerIds {
+		deals[k], shares[k], err = Reshare(memberSecrets[id], tNew, nNew)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// A deal that does not match the dealer is rejected.
+	valid, err := VerifyReshare(dealerKeys[0], deals[1], 0, shares[1][0])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified a deal of another dealer.")
+	}
+
+	// Each new member combines its shares.
+	results := make([]Result, nNew)
+	for j := range results {
+		received := []bls.PrivateKey{shares[0][j], shares[1][j]}
+		results[j], err = CombineReshares(groupKey, dealerIds, dealerKeys, deals, received, j, nNew)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !results[j].GroupKey.Equal(groupKey) {
+			test.Fatal("Group key changed.")
+		}
+		public := results[j].Secret.Public()
+		if !public.Equal(results[0].MemberKeys[j]) {
+			test.Fatal("Public key share does not match.")
+		}
+		public.Free()
+	}
+
+	// A single dealer is too few to recover the group key.
+	_, err = CombineReshares(groupKey, dealerIds[:1], dealerKeys[:1], deals[:1], []bls.PrivateKey{shares[0][0]}, 0, nNew)
+	if err == nil {
+		test.Fatal("Reshared with too few dealers.")
+	}
+
+	// The new group signs under the old group key.
+	hash := sha256.Sum256([]byte(message))
+	memberIds := []int{0, 1, 3}
+	signatures := make([]bls.Signature, tNew)
+	for i, id := range memberIds {
+		signatures[i] = bls.Sign(hash, results[id].Secret)
+	}
+	signature, err := bls.Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bls.Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	for i := range results {
+		results[i].Free()
+	}
+	for k := range deals {
+		freeKeys(deals[k])
+		for j := range shares[k] {
+			shares[k][j].Zeroize()
+		}
+	}
+	freeKeys(commitments)
+	freeKeys(memberKeys)
+	for i := range memberSecrets {
+		memberSecrets[i].Zeroize()
+	}
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,185 @@
+/**
+ * File        : dleq.go
+ * Description : Proofs of signature share correctness.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements Chaum-Pedersen proofs that a signature share h^x and
+ * the public key share g^x of a member have the same discrete logarithm x, made
+ * non-interactive with the Fiat-Shamir transform. A combiner can check such a
+ * proof with four exponentiations instead of the two pairings of VerifyShare.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"errors"
+	"unsafe"
+)
+
+// A proof that a signature share was produced with the private key share that
+// corresponds to a public key share. The proof is the challenge c and the
+// response z of the Chaum-Pedersen protocol.
+type ShareProof struct {
+	c PrivateKey
+	z PrivateKey
+}
+
+// Sign a message digest using a private key share and prove that the signature
+// share is correct. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+//
+// The private key and the nonce of the proof are blinded as in Sign, and
+// SignWithProof panics if the blinding factor cannot be drawn from crypto/rand.
+func SignWithProof(hash [sha256.Size]byte, secret PrivateKey) (Signature, ShareProof) {
+
+	// Calculate h and sigma.
+	system := secret.system
+	h := system.digestToPoint(hash[:])
+	sigma := SignPoint(Element{h}, secret)
+	public := secret.Public()
+
+	// Commit to a random exponent k.
+	k := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(k, system)
+	C.element_random(k)
+	a := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(a, system.pairing.get)
+	b := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(b, system.pairing.get)
+	err := powSecret(a, system.g.get, k)
+	if err == nil {
+		err = powSecret(b, h, k)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	// Calculate the challenge and the response z = k + c x.
+	c := system.dleqChallenge(public.gx, Element{h}, sigma, Element{a}, Element{b})
+	z := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_Zr(z, system.pairing.get)
+	C.element_mul(z, c, secret.x.get)
+	C.element_add(z, z, k)
+
+	// Clean up.
+	wipe(k)
+	C.element_clear(k)
+	C.element_clear(a)
+	C.element_clear(b)
+	C.element_clear(h)
+	public.Free()
+
+	// Return the signature share and the proof.
+	return sigma, ShareProof{PrivateKey{system, Element{c}}, PrivateKey{system, Element{z}}}
+
+}
+
+// Verify a signature share on the message digest using the public key share of
+// the member that provided it and the proof of the member. The proof binds the
+// exact share, so a share decoded from an encoding that drops the sign of the
+// point, such as PointXOnly, may fail to verify.
+func VerifyShareProof(share Signature, hash [sha256.Size]byte, memberKey PublicKey, proof ShareProof) bool {
+
+	// Check subgroup membership.
+	system := memberKey.system
+	if isIdentity(share, memberKey.gx) {
+		return false
+	}
+	if system.checkOnUse() && (!system.inSubgroup(share) || !system.inSubgroup(memberKey.gx)) {
+		return false
+	}
+
+	// Calculate h.
+	h := system.digestToPoint(hash[:])
+
+	// Recover the commitments a = g^z / (g^x)^c and b = h^z / sigma^c.
+	a := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(a, system.pairing.get)
+	u := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(u, system.pairing.get)
+	C.element_pow_zn(a, system.g.get, proof.z.x.get)
+	C.element_pow_zn(u, memberKey.gx.get, proof.c.x.get)
+	C.element_div(a, a, u)
+	b := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(b, system.pairing.get)
+	v := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(v, system.pairing.get)
+	C.element_pow_zn(b, h, proof.z.x.get)
+	C.element_pow_zn(v, share.get, proof.c.x.get)
+	C.element_div(b, b, v)
+
+	// Check the challenge.
+	c := system.dleqChallenge(memberKey.gx, Element{h}, share, Element{a}, Element{b})
+	result := C.element_cmp(c, proof.c.x.get) == 0
+
+	// Clean up.
+	C.element_clear(c)
+	C.element_clear(a)
+	C.element_clear(b)
+	C.element_clear(u)
+	C.element_clear(v)
+	C.element_clear(h)
+
+	// Return the result.
+	return result
+
+}
+
+// Calculate the Fiat-Shamir challenge of a proof by hashing the statement and
+// the commitments into Zr. This function allocates C structures on the C heap
+// using malloc. It is the responsibility of the caller to prevent memory leaks
+// by arranging for the C structures to be freed.
+func (system System) dleqChallenge(gx Element, h Element, sigma Element, a Element, b Element) *C.struct_element_s {
+	transcript := []byte("go-bls dleq")
+	for _, element := range []Element{system.g, gx, h, sigma, a, b} {
+		transcript = append(transcript, element.key()...)
+	}
+	hash := sha256.Sum256(transcript)
+	c := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_Zr(c, system.pairing.get)
+	C.element_from_hash(c, unsafe.Pointer(&hash[0]), sha256.Size)
+	return c
+}
+
+// ToBytes exports the proof to a byte slice.
+func (proof ShareProof) ToBytes() []byte {
+	return append(proof.c.ToBytes(), proof.z.ToBytes()...)
+}
+
+// ShareProofFromBytes imports a proof from the provided byte slice.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func ShareProofFromBytes(system System, bytes []byte) (ShareProof, error) {
+	n := int(C.pairing_length_in_bytes_Zr(system.pairing.get))
+	if len(bytes) != 2*n {
+		return ShareProof{}, errors.New("bls.ShareProofFromBytes: Proof length mismatch.")
+	}
+	c, err := PrivateKeyFromBytes(system, bytes[:n])
+	if err != nil {
+		return ShareProof{}, err
+	}
+	z, err := PrivateKeyFromBytes(system, bytes[n:])
+	if err != nil {
+		c.Free()
+		return ShareProof{}, err
+	}
+	return ShareProof{c, z}, nil
+}
+
+// Free the memory occupied by the proof. The proof cannot be used after
+// calling this function.
+func (proof ShareProof) Free() {
+	proof.c.Free()
+	proof.z.Free()
+}
@@ -0,0 +1,78 @@
+/**
+ * File        : dleq_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for proofs of signature share correctness.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestShareProof(test *testing.T) {
+
+	t := 2
+	n := 3
+	message := "This is a message."
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign with a proof and verify it after a round trip.
+	hash := sha256.Sum256([]byte(message))
+	share, proof := SignWithProof(hash, memberSecrets[1])
+	decoded, err := ShareProofFromBytes(system, proof.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !VerifyShareProof(share, hash, memberKeys[1], decoded) {
+		test.Fatal("Failed to verify proof.")
+	}
+
+	// Reject the proof for another member, message, or share.
+	if VerifyShareProof(share, hash, memberKeys[0], proof) {
+		test.Fatal("Accepted proof for another member.")
+	}
+	other := sha256.Sum256([]byte("This is another message."))
+	if VerifyShareProof(share, other, memberKeys[1], proof) {
+		test.Fatal("Accepted proof for another message.")
+	}
+	forged := Sign(hash, memberSecrets[0])
+	if VerifyShareProof(forged, hash, memberKeys[1], proof) {
+		test.Fatal("Accepted proof for another share.")
+	}
+
+	// Clean up.
+	forged.Free()
+	decoded.Free()
+	proof.Free()
+	share.Free()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
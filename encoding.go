@@ -0,0 +1,248 @@
+/**
+ * File        : encoding.go
+ * Description : Point encodings.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module translates between the compressed point encoding of the PBC
+ * library and the encodings used by other implementations, so that signatures
+ * and public keys can be exchanged without external conversion shims.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"errors"
+)
+
+// SignBit identifies where the sign of the y-coordinate is stored in a
+// compressed point.
+type SignBit int
+
+const (
+	// SignTrailingByte stores the sign in a byte following the x-coordinate,
+	// as the PBC library does.
+	SignTrailingByte SignBit = iota
+	// SignHighBit stores the sign in the most significant bit of the first
+	// field element of the x-coordinate.
+	SignHighBit
+)
+
+// PointFormat identifies which coordinates of a signature are serialized.
+type PointFormat int
+
+const (
+	// PointCompressed stores the x-coordinate and the sign of the
+	// y-coordinate. Decoding requires a square root.
+	PointCompressed PointFormat = iota
+	// PointUncompressed stores both coordinates. It is twice as large, but
+	// decodes without a square root.
+	PointUncompressed
+	// PointXOnly stores the x-coordinate alone. A decoded signature is only
+	// known up to sign, so verification accepts either sign. Such signatures
+	// cannot be combined, so aggregation, threshold recovery, and the
+	// verifiable random function reject cryptosystems that use this format.
+	PointXOnly
+)
+
+// An Encoding describes how compressed points are converted to bytes. The zero
+// value is the native encoding of the PBC library.
+type Encoding struct {
+
+	// Write each field element of the x-coordinate in little-endian order.
+	LittleEndian bool
+
+	// The placement of the sign of the y-coordinate.
+	SignBit SignBit
+
+	// Pad each field element of the x-coordinate with zeros to this many
+	// bytes. Zero means no padding.
+	FieldSize int
+
+	// The coordinates of signatures to serialize. Public keys are always
+	// compressed.
+	Format PointFormat
+}
+
+type encoding struct {
+	Encoding
+	g1 layout
+	g2 layout
+}
+
+// The layout of the x-coordinate of a compressed point.
+type layout struct {
+	count int
+	size  int
+	bits  int
+}
+
+// Select the encoding of signatures and public keys for the cryptosystem. The
+// encoding applies to SigToBytes, SigFromBytes, PublicKey.ToBytes, and
+// PublicKeyFromBytes.
+func (system System) WithEncoding(enc Encoding) (System, error) {
+	g1 := system.layout(C.pairing_length_in_bytes_x_only_G1(system.pairing.get), true)
+	g2 := system.layout(C.pairing_length_in_bytes_x_only_G2(system.pairing.get), false)
+	for i, l := range []layout{g1, g2} {
+		if enc.FieldSize != 0 && enc.FieldSize < l.size {
+			return System{}, errors.New("bls.WithEncoding: Field size is too small.")
+		}
+		size := l.size
+		if enc.FieldSize != 0 {
+			size = enc.FieldSize
+		}
+		if enc.SignBit == SignHighBit && l.bits >= 8*size && (enc.Format == PointCompressed || i == 1) {
+			return System{}, errors.New("bls.WithEncoding: No free bit for the sign.")
+		}
+	}
+	if enc.Format < PointCompressed || enc.Format > PointXOnly {
+		return System{}, errors.New("bls.WithEncoding: Unknown point format.")
+	}
+	system.encoding = encoding{enc, g1, g2}
+	return system, nil
+}
+
+// Determine the layout of the x-coordinate of a point in G1 or G2. PBC has no
+// coordinates for the point at infinity, which is where a new element starts,
+// so the layout is read from a random point instead.
+func (system System) layout(length C.int, g1 bool) layout {
+	e := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	if g1 {
+		C.element_init_G1(e, system.pairing.get)
+	} else {
+		C.element_init_G2(e, system.pairing.get)
+	}
+	C.element_random(e)
+	for C.element_is1(e) == 1 {
+		C.element_random(e)
+	}
+	x := C.element_item(e, 0)
+	count := int(C.element_item_count(x))
+	coeff := x
+	if count == 0 {
+		count = 1
+	} else {
+		coeff = C.element_item(x, 0)
+	}
+	bits := int(C.mpz_sizeinbase(&coeff.field.order[0], 2))
+	C.element_clear(e)
+	return layout{count, int(length) / count, bits}
+}
+
+// Convert a point from the native encoding of the given format.
+func (enc encoding) encode(native []byte, l layout, format PointFormat) []byte {
+	if enc.Encoding == (Encoding{}) || native == nil {
+		return native
+	}
+	size := l.size
+	if enc.FieldSize != 0 {
+		size = enc.FieldSize
+	}
+	count := format.coordinates() * l.count
+	bytes := make([]byte, 0, count*size+1)
+	for i := 0; i < count; i++ {
+		coeff := make([]byte, size)
+		copy(coeff[size-l.size:], native[i*l.size:(i+1)*l.size])
+		if enc.LittleEndian {
+			reverse(coeff)
+		}
+		bytes = append(bytes, coeff...)
+	}
+	if format != PointCompressed {
+		return bytes
+	}
+	sign := native[l.count*l.size]
+	switch enc.SignBit {
+	case SignTrailingByte:
+		bytes = append(bytes, sign)
+	case SignHighBit:
+		if sign != 0 {
+			bytes[enc.msb(size)] |= 0x80
+		}
+	}
+	return bytes
+}
+
+// Convert a point to the native encoding of the given format.
+func (enc encoding) decode(bytes []byte, l layout, format PointFormat) ([]byte, error) {
+	if enc.Encoding == (Encoding{}) {
+		return bytes, nil
+	}
+	size := l.size
+	if enc.FieldSize != 0 {
+		size = enc.FieldSize
+	}
+	count := format.coordinates() * l.count
+	n := count * size
+	if format == PointCompressed && enc.SignBit == SignTrailingByte {
+		n++
+	}
+	if len(bytes) != n {
+		return nil, errors.New("bls.FromBytes: Encoding length mismatch.")
+	}
+	bytes = append([]byte{}, bytes...)
+	var sign byte
+	switch {
+	case format != PointCompressed:
+	case enc.SignBit == SignTrailingByte:
+		sign = bytes[n-1]
+		if sign > 1 {
+			return nil, errors.New("bls.FromBytes: Invalid sign byte.")
+		}
+	case enc.SignBit == SignHighBit:
+		sign = bytes[enc.msb(size)] >> 7
+		bytes[enc.msb(size)] &= 0x7f
+	}
+	native := make([]byte, 0, count*l.size+1)
+	for i := 0; i < count; i++ {
+		coeff := bytes[i*size : (i+1)*size]
+		if enc.LittleEndian {
+			reverse(coeff)
+		}
+		for _, b := range coeff[:size-l.size] {
+			if b != 0 {
+				return nil, errors.New("bls.FromBytes: Nonzero padding.")
+			}
+		}
+		native = append(native, coeff[size-l.size:]...)
+	}
+	if format != PointCompressed {
+		return native, nil
+	}
+	return append(native, sign), nil
+}
+
+// Determine the number of coordinates serialized in the format.
+func (format PointFormat) coordinates() int {
+	if format == PointUncompressed {
+		return 2
+	}
+	return 1
+}
+
+// Determine the index of the most significant byte of the first field element.
+func (enc encoding) msb(size int) int {
+	if enc.LittleEndian {
+		return size - 1
+	}
+	return 0
+}
+
+func reverse(bytes []byte) {
+	for i, j := 0, len(bytes)-1; i < j; i, j = i+1, j-1 {
+		bytes[i], bytes[j] = bytes[j], bytes[i]
+	}
+}
+
+// Check whether signatures of the cryptosystem can be multiplied together. A
+// signature decoded without the sign of its y-coordinate may be the inverse of
+// the signature that was encoded, so products of such signatures are invalid.
+func (system System) combinable() bool {
+	return system.encoding.Format != PointXOnly
+}
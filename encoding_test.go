@@ -0,0 +1,195 @@
+/**
+ * File        : encoding_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for point encodings.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEncoding(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// The x-coordinate fills all 64 bytes, so the sign needs padding.
+	_, err = system.WithEncoding(Encoding{SignBit: SignHighBit})
+	if err == nil {
+		test.Fatal("Failed to reject encoding without a free bit.")
+	}
+	encoded, err := system.WithEncoding(Encoding{LittleEndian: true, SignBit: SignHighBit, FieldSize: 65})
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Serialize the signature and the public key.
+	hash := sha256.Sum256([]byte(message))
+	signatureOut := Sign(hash, secret)
+	sigBytes := encoded.SigToBytes(signatureOut)
+	if len(sigBytes) != 65 {
+		test.Fatal("Unexpected signature length.")
+	}
+	key.system = encoded
+	keyBytes := key.ToBytes()
+	if len(keyBytes) != 65 {
+		test.Fatal("Unexpected public key length.")
+	}
+
+	// Deserialize the signature and the public key and verify.
+	signatureIn, err := encoded.SigFromBytes(sigBytes)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keyIn, err := PublicKeyFromBytes(encoded, keyBytes)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Nonzero padding must be rejected.
+	sigBytes[64] |= 0x01
+	_, err = encoded.SigFromBytes(sigBytes)
+	if err == nil {
+		test.Fatal("Failed to reject nonzero padding.")
+	}
+
+	// Clean up.
+	signatureIn.Free()
+	signatureOut.Free()
+	keyIn.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestPointFormat(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signatureOut := Sign(hash, secret)
+
+	// Round-trip the signature in each format.
+	lengths := map[PointFormat]int{PointCompressed: 65, PointUncompressed: 128, PointXOnly: 64}
+	for format, length := range lengths {
+		encoded, err := system.WithEncoding(Encoding{Format: format})
+		if err != nil {
+			test.Fatal(err)
+		}
+		bytes := encoded.SigToBytes(signatureOut)
+		if len(bytes) != length {
+			test.Fatal("Unexpected signature length.")
+		}
+		signatureIn, err := encoded.SigFromBytes(bytes)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !Verify(signatureIn, hash, PublicKey{encoded, key.gx}) {
+			test.Fatal("Failed to verify signature.")
+		}
+		signatureIn.Free()
+	}
+
+	// Clean up.
+	signatureOut.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestPointXOnlyNotCombinable(test *testing.T) {
+
+	// Generate a key pair of a cryptosystem that drops the sign.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	xonly, err := system.WithEncoding(Encoding{Format: PointXOnly})
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(xonly)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("This is a message."))
+	signature := Sign(hash, secret)
+
+	// Every function that combines signatures must reject the cryptosystem.
+	_, err = Aggregate([]Signature{signature}, xonly)
+	if err == nil {
+		test.Fatal("Aggregate accepted signatures without a sign.")
+	}
+	_, err = AggregateVerify(signature, [][sha256.Size]byte{hash}, []PublicKey{key})
+	if err == nil {
+		test.Fatal("AggregateVerify accepted signatures without a sign.")
+	}
+	_, err = VerifySameMessage(signature, hash, []PublicKey{key})
+	if err == nil {
+		test.Fatal("VerifySameMessage accepted signatures without a sign.")
+	}
+	_, err = Threshold([]Signature{signature}, []int{0}, xonly)
+	if err == nil {
+		test.Fatal("Threshold accepted signatures without a sign.")
+	}
+	_, err = NewThresholdAccumulator(1, hash, []PublicKey{key}, xonly)
+	if err == nil {
+		test.Fatal("NewThresholdAccumulator accepted signatures without a sign.")
+	}
+	_, _, err = VRFProve(hash, secret)
+	if err == nil {
+		test.Fatal("VRFProve accepted proofs without a sign.")
+	}
+	if VRFVerify(hash, vrfOutput(signature), signature, key) {
+		test.Fatal("VRFVerify accepted a proof without a sign.")
+	}
+
+	// Clean up.
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,147 @@
+/**
+ * File        : extend.go
+ * Description : Share extension.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module adds a member to a group without a new deal. The share of the
+ * new member is the evaluation of the group polynomial at its point, which t
+ * existing members can interpolate from their shares. To keep their shares
+ * hidden from the new member and from each other, each helper splits its
+ * weighted share into random parts that sum to it and sends one part to each
+ * helper. Each helper forwards the sum of the parts it received, and the new
+ * member sums these to obtain its share, which it checks against the public
+ * key shares of the helpers.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Compute the parts of the contribution of a helper to the share of a new
+// member. The helper holds the private key share of member memberId, and the
+// helpers are the members with the given identifiers. The part at index k must
+// be sent privately to helper helperIds[k]. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func ExtensionParts(secret PrivateKey, memberId int, helperIds []int, newId int) ([]PrivateKey, error) {
+
+	// Calculate the Lagrange coefficient of the helper.
+	system := secret.system
+	coeffs, err := extensionCoefficients(helperIds, newId, system)
+	if err != nil {
+		return nil, err
+	}
+	k := -1
+	for i := range helperIds {
+		if helperIds[i] == memberId {
+			k = i
+		}
+	}
+	if k < 0 {
+		return nil, errors.New("bls.ExtensionParts: Member is not a helper.")
+	}
+
+	// Calculate the weighted share.
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	var lambda C.mpz_t
+	C.mpz_init(&lambda[0])
+	setMpz(&lambda[0], coeffs[k])
+	C.element_mul_mpz(x, secret.x.get, &lambda[0])
+	C.mpz_clear(&lambda[0])
+
+	// Split it into random parts.
+	parts := make([]PrivateKey, len(helperIds))
+	for i := 1; i < len(parts); i++ {
+		key, part, err := GenKeys(system)
+		if err != nil {
+			for j := 1; j < i; j++ {
+				parts[j].Zeroize()
+			}
+			wipe(x)
+			C.element_clear(x)
+			return nil, err
+		}
+		key.Free()
+		C.element_sub(x, x, part.x.get)
+		parts[i] = part
+	}
+	parts[0] = PrivateKey{system, Element{x}}
+
+	// Return the parts.
+	return parts, nil
+
+}
+
+// Complete the share of a new member from the values forwarded by the helpers,
+// where the value and the public key share of helper helperIds[k] are at index
+// k. Each helper forwards the sum of the parts it received, as computed by
+// AddPrivateKeys. The share is checked against the public key shares of the
+// helpers. This function allocates C structures on the C heap using malloc. It
+// is the responsibility of the caller to prevent memory leaks by arranging for
+// the C structures to be freed.
+func CompleteExtension(values []PrivateKey, helperKeys []PublicKey, helperIds []int, newId int) (PrivateKey, error) {
+
+	// Check the list length.
+	if len(values) == 0 {
+		return PrivateKey{}, errors.New("bls.CompleteExtension: Empty list.")
+	}
+	if len(values) != len(helperKeys) || len(values) != len(helperIds) {
+		return PrivateKey{}, errors.New("bls.CompleteExtension: List length mismatch.")
+	}
+
+	// Sum the values.
+	system := values[0].system
+	share, err := AddPrivateKeys(values)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+
+	// Interpolate the public key share of the new member.
+	coeffs, err := extensionCoefficients(helperIds, newId, system)
+	if err != nil {
+		share.Zeroize()
+		return PrivateKey{}, err
+	}
+	key := PublicKey{system, Element{evalCommitments(helperKeys, coeffs)}}
+	public := share.Public()
+	valid := public.Equal(key)
+	public.Free()
+	key.Free()
+	if !valid {
+		share.Zeroize()
+		return PrivateKey{}, errors.New("bls.CompleteExtension: Share does not match the helper keys.")
+	}
+
+	// Return the share.
+	return share, nil
+
+}
+
+// Calculate the Lagrange coefficients that interpolate the share of the new
+// member from the shares of the helpers.
+func extensionCoefficients(helperIds []int, newId int, system System) ([]*big.Int, error) {
+	if len(helperIds) == 0 {
+		return nil, errors.New("bls.Extension: Empty list.")
+	}
+	if newId < 0 {
+		return nil, errors.New("bls.Extension: Member identifier out of range.")
+	}
+	for _, id := range helperIds {
+		if id == newId {
+			return nil, errors.New("bls.Extension: New member is already a member.")
+		}
+	}
+	return lagrangeCoefficients(memberPoints(helperIds), big.NewInt(int64(newId)+1), system)
+}
@@ -0,0 +1,114 @@
+/**
+ * File        : extend_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for share extension.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestShareExtension(test *testing.T) {
+
+	t := 3
+	n := 4
+	newId := 4
+	message := "This is a message."
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Members 0, 2, and 3 help member 4 join.
+	helperIds := []int{0, 2, 3}
+	parts := make([][]PrivateKey, t)
+	for k, id := range helperIds {
+		parts[k], err = ExtensionParts(memberSecrets[id], id, helperIds, newId)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	values := make([]PrivateKey, t)
+	helperKeys := make([]PublicKey, t)
+	for k, id := range helperIds {
+		received := make([]PrivateKey, t)
+		for i := range parts {
+			received[i] = parts[i][k]
+		}
+		values[k], err = AddPrivateKeys(received)
+		if err != nil {
+			test.Fatal(err)
+		}
+		helperKeys[k] = memberKeys[id]
+	}
+	share, err := CompleteExtension(values, helperKeys, helperIds, newId)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// The new share matches the commitments of the dealer and can sign.
+	valid, err := VerifyKeyShare(newId, share, commitments)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("New share does not match the commitments.")
+	}
+	hash := sha256.Sum256([]byte(message))
+	signatures := []Signature{Sign(hash, memberSecrets[1]), Sign(hash, share), Sign(hash, memberSecrets[2])}
+	signature, err := Threshold(signatures, []int{1, newId, 2}, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// A tampered value is detected.
+	values[0], values[1] = values[1], values[0]
+	_, err = CompleteExtension(values, helperKeys[:2], helperIds[:2], newId)
+	if err == nil {
+		test.Fatal("Accepted a tampered share.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := range signatures {
+		signatures[i].Free()
+	}
+	share.Free()
+	for k := range parts {
+		values[k].Free()
+		for i := range parts[k] {
+			parts[k][i].Free()
+		}
+	}
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
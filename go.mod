@@ -0,0 +1,3 @@
+module github.com/enzoh/go-bls
+
+go 1.24
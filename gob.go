@@ -0,0 +1,64 @@
+/**
+ * File        : gob.go
+ * Description : Gob encoding.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements gob.GobEncoder and GobDecoder for the exported types,
+ * so that they can be sent over net/rpc. The encodings are those of the binary
+ * marshalers, so keys and signatures can only be decoded if their cryptosystem
+ * is registered, or if the receiving key already belongs to it.
+ */
+
+package bls
+
+// GobEncode implements gob.GobEncoder.
+func (params Params) GobEncode() ([]byte, error) {
+	return params.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (params *Params) GobDecode(data []byte) error {
+	return params.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (system System) GobEncode() ([]byte, error) {
+	return system.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (system *System) GobDecode(data []byte) error {
+	return system.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (key PublicKey) GobEncode() ([]byte, error) {
+	return key.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (key *PublicKey) GobDecode(data []byte) error {
+	return key.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (secret PrivateKey) GobEncode() ([]byte, error) {
+	return secret.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (secret *PrivateKey) GobDecode(data []byte) error {
+	return secret.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (element Element) GobEncode() ([]byte, error) {
+	return element.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (element *Element) GobDecode(data []byte) error {
+	return element.UnmarshalBinary(data)
+}
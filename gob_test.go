@@ -0,0 +1,73 @@
+/**
+ * File        : gob_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for gob encoding.
+ */
+
+package bls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"testing"
+)
+
+func TestGob(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	err = RegisterSystem(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer UnregisterSystem(system)
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Send the key and the signature through a gob stream.
+	type envelope struct {
+		Key       PublicKey
+		Hash      [sha256.Size]byte
+		Signature Signature
+	}
+	var buf bytes.Buffer
+	err = gob.NewEncoder(&buf).Encode(envelope{key, hash, signature})
+	if err != nil {
+		test.Fatal(err)
+	}
+	var received envelope
+	err = gob.NewDecoder(&buf).Decode(&received)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(received.Signature, received.Hash, received.Key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	received.Signature.Free()
+	received.Key.Free()
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,261 @@
+/**
+ * File        : guardian.go
+ * Description : Social key recovery.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module splits the private key of a user among guardians, so that any t
+ * of them can return their shares to restore the key. Each share is encoded
+ * with a magic prefix, the fingerprint of the public key of the user, the
+ * index of the guardian, the threshold, an expiry time, and the commitments of
+ * the dealer, and ends with a checksum. The encoding cannot be mistaken for a
+ * plain private key, and shares of another key, of another split, or past
+ * their expiry are rejected on return before they are combined.
+ */
+
+package guardian
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/enzoh/go-bls"
+)
+
+// The prefix of an encoded share, which includes the version of the encoding.
+var magic = []byte("go-bls guardian\x01")
+
+// A Share is the share of one guardian, as decoded by Open.
+type Share struct {
+
+	// The index of the guardian, in the range [0, n).
+	Guardian int
+
+	// The number of shares needed to restore the key.
+	Threshold int
+
+	// The number of guardians.
+	Guardians int
+
+	// The time after which the share is no longer accepted.
+	Expires time.Time
+
+	// The commitments of the dealer. The first commitment is the public key of
+	// the user.
+	Commitments []bls.PublicKey
+
+	// The private key share of the guardian.
+	Secret bls.PrivateKey
+}
+
+// Split the private key of a user into n shares for the guardians, such that t
+// of them can restore the key until the expiry time. The share at index i is
+// for guardian i.
+func Split(key bls.PublicKey, secret bls.PrivateKey, t int, n int, expires time.Time) ([][]byte, error) {
+
+	// Check the key pair.
+	public := secret.Public()
+	valid := public.Equal(key)
+	public.Free()
+	if !valid {
+		return nil, errors.New("guardian.Split: Private key does not match the public key.")
+	}
+
+	// Deal the shares.
+	commitments, shares, err := bls.DealSecret(secret, t, n)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		freeKeys(commitments)
+		for i := range shares {
+			shares[i].Zeroize()
+		}
+	}()
+
+	// Encode the shares.
+	data := make([][]byte, n)
+	for i := range shares {
+		data[i] = Share{i, t, n, expires, commitments, shares[i]}.encode(key)
+	}
+	return data, nil
+
+}
+
+// Open the share of a guardian and check it against the public key of the user
+// at the given time. The caller must free the share. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func Open(system bls.System, key bls.PublicKey, data []byte, now time.Time) (Share, error) {
+
+	// Check the checksum and the header.
+	if len(data) < len(magic)+sha256.Size {
+		return Share{}, errors.New("guardian.Open: Data too short.")
+	}
+	body, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	checksum := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare(checksum[:], sum) != 1 {
+		return Share{}, errors.New("guardian.Open: Checksum mismatch.")
+	}
+	if !bytes.HasPrefix(body, magic) {
+		return Share{}, errors.New("guardian.Open: Not a guardian share.")
+	}
+	buf := bytes.NewBuffer(body[len(magic):])
+	var fingerprint [sha256.Size]byte
+	var header [3]uint32
+	var expires int64
+	if copy(fingerprint[:], buf.Next(sha256.Size)) != sha256.Size ||
+		binary.Read(buf, binary.BigEndian, &header) != nil ||
+		binary.Read(buf, binary.BigEndian, &expires) != nil {
+		return Share{}, errors.New("guardian.Open: Data too short.")
+	}
+	if fingerprint != keyFingerprint(key) {
+		return Share{}, errors.New("guardian.Open: Share belongs to another key.")
+	}
+	share := Share{Guardian: int(header[0]), Threshold: int(header[1]), Guardians: int(header[2]), Expires: time.Unix(expires, 0)}
+	if share.Threshold < 1 || share.Guardians < share.Threshold || share.Guardian >= share.Guardians {
+		return Share{}, errors.New("guardian.Open: Bad threshold parameters.")
+	}
+	if now.After(share.Expires) {
+		return Share{}, errors.New("guardian.Open: Share has expired.")
+	}
+
+	// Read the commitments and the private key share.
+	kn := len(key.ToBytes())
+	if buf.Len() <= kn*share.Threshold {
+		return Share{}, errors.New("guardian.Open: Data too short.")
+	}
+	for j := 0; j < share.Threshold; j++ {
+		commitment, err := bls.PublicKeyFromBytes(system, buf.Next(kn))
+		if err != nil {
+			freeKeys(share.Commitments)
+			return Share{}, err
+		}
+		share.Commitments = append(share.Commitments, commitment)
+	}
+	var err error
+	share.Secret, err = bls.PrivateKeyFromBytes(system, buf.Bytes())
+	if err != nil {
+		freeKeys(share.Commitments)
+		return Share{}, err
+	}
+
+	// Check the share against the commitments.
+	valid, err := bls.VerifyKeyShare(share.Guardian, share.Secret, share.Commitments)
+	if err != nil || !valid || !share.Commitments[0].Equal(key) {
+		share.Free()
+		return Share{}, errors.New("guardian.Open: Share does not match the commitments.")
+	}
+
+	// Return the share.
+	return share, nil
+
+}
+
+// Restore the private key of a user from the shares returned by at least t
+// guardians at the given time. The shares must come from the same split. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func Recover(system bls.System, key bls.PublicKey, data [][]byte, now time.Time) (bls.PrivateKey, error) {
+
+	// Open the shares.
+	if len(data) == 0 {
+		return bls.PrivateKey{}, errors.New("guardian.Recover: Empty list.")
+	}
+	shares := make([]Share, 0, len(data))
+	defer func() {
+		for i := range shares {
+			shares[i].Free()
+		}
+	}()
+	for i := range data {
+		share, err := Open(system, key, data[i], now)
+		if err != nil {
+			return bls.PrivateKey{}, err
+		}
+		shares = append(shares, share)
+	}
+
+	// Check that the shares come from the same split.
+	first := shares[0]
+	guardians := make([]int, len(shares))
+	secrets := make([]bls.PrivateKey, len(shares))
+	for i, share := range shares {
+		if share.Threshold != first.Threshold || share.Guardians != first.Guardians {
+			return bls.PrivateKey{}, errors.New("guardian.Recover: Shares come from different splits.")
+		}
+		for j := range share.Commitments {
+			if !share.Commitments[j].Equal(first.Commitments[j]) {
+				return bls.PrivateKey{}, errors.New("guardian.Recover: Shares come from different splits.")
+			}
+		}
+		guardians[i] = share.Guardian
+		secrets[i] = share.Secret
+	}
+	err := bls.CheckMemberIds(guardians, first.Threshold)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+
+	// Interpolate the private key from t shares.
+	t := first.Threshold
+	secret, err := bls.RecoverPrivateKey(secrets[:t], guardians[:t])
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	public := secret.Public()
+	valid := public.Equal(key)
+	public.Free()
+	if !valid {
+		secret.Zeroize()
+		return bls.PrivateKey{}, errors.New("guardian.Recover: Restored key does not match the public key.")
+	}
+
+	// Return the private key.
+	return secret, nil
+
+}
+
+// Free the memory occupied by the share. The private key share is overwritten.
+func (share Share) Free() {
+	freeKeys(share.Commitments)
+	share.Secret.Zeroize()
+}
+
+// Free the memory occupied by the public keys.
+func freeKeys(keys []bls.PublicKey) {
+	for j := range keys {
+		keys[j].Free()
+	}
+}
+
+// Encode the share for the key of the user.
+func (share Share) encode(key bls.PublicKey) []byte {
+	var buf bytes.Buffer
+	buf.Write(magic)
+	fingerprint := keyFingerprint(key)
+	buf.Write(fingerprint[:])
+	binary.Write(&buf, binary.BigEndian, [3]uint32{uint32(share.Guardian), uint32(share.Threshold), uint32(share.Guardians)})
+	binary.Write(&buf, binary.BigEndian, share.Expires.Unix())
+	for j := range share.Commitments {
+		buf.Write(share.Commitments[j].ToBytes())
+	}
+	secret := share.Secret.ToBytes()
+	buf.Write(secret)
+	clear(secret)
+	checksum := sha256.Sum256(buf.Bytes())
+	buf.Write(checksum[:])
+	return buf.Bytes()
+}
+
+// Calculate the fingerprint of the public key of a user.
+func keyFingerprint(key bls.PublicKey) [sha256.Size]byte {
+	return sha256.Sum256(append([]byte("go-bls guardian key"), key.ToBytes()...))
+}
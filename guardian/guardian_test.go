@@ -0,0 +1,112 @@
+/**
+ * File        : guardian_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for social key recovery.
+ */
+
+package guardian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enzoh/go-bls"
+)
+
+func TestGuardian(test *testing.T) {
+
+	t := 3
+	n := 5
+	now := time.Unix(1500000000, 0)
+	expires := now.Add(365 * 24 * time.Hour)
+
+	// Split the key of a user among the guardians.
+	params := bls.GenParamsTypeA(160, 512)
+	pairing := bls.GenPairing(params)
+	system, err := bls.GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := bls.GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err := Split(key, secret, t, n, expires)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// A guardian checks its share.
+	share, err := Open(system, key, data[2], now)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if share.Guardian != 2 || share.Threshold != t || share.Guardians != n {
+		test.Fatal("Share metadata does not match.")
+	}
+	share.Free()
+
+	// Restore the key from three shares.
+	restored, err := Recover(system, key, [][]byte{data[4], data[0], data[2]}, now)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !restored.Equal(secret) {
+		test.Fatal("Restored key does not match.")
+	}
+	restored.Free()
+
+	// Too few, duplicate, expired, corrupted, and foreign shares are rejected.
+	_, err = Recover(system, key, [][]byte{data[4], data[0]}, now)
+	if err == nil {
+		test.Fatal("Accepted too few shares.")
+	}
+	_, err = Recover(system, key, [][]byte{data[4], data[0], data[4]}, now)
+	if err == nil {
+		test.Fatal("Accepted duplicate shares.")
+	}
+	_, err = Recover(system, key, [][]byte{data[4], data[0], data[2]}, expires.Add(time.Second))
+	if err == nil {
+		test.Fatal("Accepted expired shares.")
+	}
+	corrupted := append([]byte{}, data[1]...)
+	corrupted[len(magic)] ^= 1
+	_, err = Open(system, key, corrupted, now)
+	if err == nil {
+		test.Fatal("Accepted a corrupted share.")
+	}
+	other, otherSecret, err := bls.GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	otherData, err := Split(other, otherSecret, t, n, expires)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = Recover(system, key, [][]byte{data[4], data[0], otherData[2]}, now)
+	if err == nil {
+		test.Fatal("Accepted a share of another key.")
+	}
+	mixed, err := Split(key, secret, t, n, expires)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = Recover(system, key, [][]byte{data[4], data[0], mixed[2]}, now)
+	if err == nil {
+		test.Fatal("Accepted shares of different splits.")
+	}
+
+	// Clean up.
+	other.Free()
+	otherSecret.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,74 @@
+/**
+ * File        : hash.go
+ * Description : Hash functions.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides the functions used to hash messages to digests that are
+ * then mapped to group elements, and to derive the system parameter and keys.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"errors"
+)
+
+// HashType identifies the function used to hash a message to a digest.
+type HashType int
+
+const (
+	// HashSHA256 hashes messages using SHA-256.
+	HashSHA256 HashType = iota
+	// HashSHA3_256 hashes messages using SHA3-256.
+	HashSHA3_256
+	// HashSHA512_256 hashes messages using SHA-512/256.
+	HashSHA512_256
+)
+
+// Determine whether the hash function is one of the above.
+func (hash HashType) known() bool {
+	return hash >= HashSHA256 && hash <= HashSHA512_256
+}
+
+// Select the function used to hash messages to digests for the cryptosystem.
+// Keys generated by the cryptosystem are derived using the same function.
+func (system System) WithHash(hash HashType) System {
+	system.hash = hash
+	return system
+}
+
+// Hash a message to a digest using the hash function of the cryptosystem. If
+// the cryptosystem has a domain separation tag, the message is prefixed with
+// the length of the tag and the tag itself. The digest can be passed to Sign
+// and Verify.
+func (system System) Digest(message []byte) ([sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	if len(system.dst) != 0 {
+		message = append(append([]byte{byte(len(system.dst))}, system.dst...), message...)
+	}
+	switch system.hash {
+	case HashSHA256:
+		return sha256.Sum256(message), nil
+	case HashSHA3_256:
+		return sha3.Sum256(message), nil
+	case HashSHA512_256:
+		return sha512.Sum512_256(message), nil
+	default:
+		return digest, errors.New("bls.Digest: Unknown hash function.")
+	}
+}
+
+// Generate a cryptographically secure pseudorandom digest using the hash
+// function of the cryptosystem.
+func (system System) randomDigest() ([sha256.Size]byte, error) {
+	hash, err := randomHash()
+	if err != nil {
+		return hash, err
+	}
+	return system.Digest(hash[:])
+}
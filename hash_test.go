@@ -0,0 +1,91 @@
+/**
+ * File        : hash_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for hash functions.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestDigestSHA256(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// The default hash function is SHA-256.
+	digest, err := system.Digest(message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if digest != sha256.Sum256(message) {
+		test.Fatal("Digest does not match SHA-256.")
+	}
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestGenSystemWithHash(test *testing.T) {
+
+	message := []byte("This is a message.")
+	hashes := []HashType{HashSHA3_256, HashSHA512_256}
+	expected := [][sha256.Size]byte{sha3.Sum256(message), sha512.Sum512_256(message)}
+
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	for i := range hashes {
+
+		// Generate a key pair.
+		system, err := GenSystemWithHash(pairing, hashes[i])
+		if err != nil {
+			test.Fatal(err)
+		}
+		key, secret, err := GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		// Hash, sign, and verify the message.
+		digest, err := system.Digest(message)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if digest != expected[i] {
+			test.Fatal("Digest does not match the hash function.")
+		}
+		signature := Sign(digest, secret)
+		if !Verify(signature, digest, key) {
+			test.Fatal("Failed to verify signature.")
+		}
+
+		// Clean up.
+		signature.Free()
+		key.Free()
+		secret.Free()
+		system.Free()
+
+	}
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,211 @@
+/**
+ * File        : keystore.go
+ * Description : Encrypted keystores.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module reads and writes private keys in the EIP-2335 keystore format.
+ * The decryption key is derived from the password with PBKDF2-HMAC-SHA256,
+ * the secret is encrypted with AES-128-CTR, and a SHA-256 checksum detects
+ * wrong passwords. Keystores using scrypt are rejected, since scrypt is not
+ * available in the standard library. Password normalization is limited to
+ * stripping control codes, so passwords must be ASCII.
+ */
+
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/enzoh/go-bls"
+)
+
+// The iteration count used when encrypting keystores.
+const Iterations = 262144
+
+// The largest iteration count accepted when decrypting a keystore, so that a
+// planted keystore cannot make Decrypt run for hours.
+const maxIterations = 10 * Iterations
+
+// The length of the derived key, as EIP-2335 requires.
+const dkLen = 32
+
+// The keystore format version.
+const version = 4
+
+type module struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+type keystore struct {
+	Crypto struct {
+		KDF      module `json:"kdf"`
+		Checksum module `json:"checksum"`
+		Cipher   module `json:"cipher"`
+	} `json:"crypto"`
+	Description string `json:"description"`
+	Pubkey      string `json:"pubkey"`
+	Path        string `json:"path"`
+	UUID        string `json:"uuid"`
+	Version     int    `json:"version"`
+}
+
+// Encrypt a private key and its public key with the password.
+func Encrypt(key bls.PublicKey, secret bls.PrivateKey, password string) ([]byte, error) {
+
+	// Derive the decryption key.
+	pass, err := normalize(password)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	uuid := make([]byte, 16)
+	for _, b := range [][]byte{salt, iv, uuid} {
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dk, err := pbkdf2.Key(sha256.New, string(pass), salt, Iterations, 32)
+	clear(pass)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dk)
+
+	// Encrypt the secret.
+	plaintext := secret.ToBytes()
+	defer clear(plaintext)
+	message, err := crypt(dk[:16], iv, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(append(append([]byte{}, dk[16:32]...), message...))
+
+	// Build the keystore.
+	var ks keystore
+	ks.Crypto.KDF = module{"pbkdf2", map[string]interface{}{
+		"dklen": dkLen,
+		"c":     Iterations,
+		"prf":   "hmac-sha256",
+		"salt":  hex.EncodeToString(salt),
+	}, ""}
+	ks.Crypto.Checksum = module{"sha256", map[string]interface{}{}, hex.EncodeToString(checksum[:])}
+	ks.Crypto.Cipher = module{"aes-128-ctr", map[string]interface{}{"iv": hex.EncodeToString(iv)}, hex.EncodeToString(message)}
+	ks.Pubkey = key.Hex()
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	ks.UUID = fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+	ks.Version = version
+	return json.MarshalIndent(ks, "", "  ")
+
+}
+
+// Decrypt a private key of the cryptosystem with the password.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func Decrypt(system bls.System, data []byte, password string) (bls.PrivateKey, error) {
+
+	// Parse the keystore.
+	var ks keystore
+	err := json.Unmarshal(data, &ks)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	if ks.Version != version {
+		return bls.PrivateKey{}, errors.New("keystore.Decrypt: Unsupported version.")
+	}
+	kdf := ks.Crypto.KDF
+	if kdf.Function != "pbkdf2" || kdf.Params["prf"] != "hmac-sha256" {
+		return bls.PrivateKey{}, errors.New("keystore.Decrypt: Unsupported key derivation function.")
+	}
+	if ks.Crypto.Checksum.Function != "sha256" || ks.Crypto.Cipher.Function != "aes-128-ctr" {
+		return bls.PrivateKey{}, errors.New("keystore.Decrypt: Unsupported cipher.")
+	}
+	dklen, ok1 := kdf.Params["dklen"].(float64)
+	c, ok2 := kdf.Params["c"].(float64)
+	salt, err1 := hexParam(kdf.Params, "salt")
+	iv, err2 := hexParam(ks.Crypto.Cipher.Params, "iv")
+	checksum, err3 := hex.DecodeString(ks.Crypto.Checksum.Message)
+	message, err4 := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if !ok1 || !ok2 || dklen != dkLen || c < 1 || c > maxIterations || err1 != nil || err2 != nil || err3 != nil || err4 != nil || len(iv) != aes.BlockSize {
+		return bls.PrivateKey{}, errors.New("keystore.Decrypt: Malformed keystore.")
+	}
+
+	// Derive the decryption key and check the password.
+	pass, err := normalize(password)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	dk, err := pbkdf2.Key(sha256.New, string(pass), salt, int(c), dkLen)
+	clear(pass)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	defer clear(dk)
+	expected := sha256.Sum256(append(append([]byte{}, dk[16:32]...), message...))
+	if subtle.ConstantTimeCompare(checksum, expected[:]) != 1 {
+		return bls.PrivateKey{}, errors.New("keystore.Decrypt: Wrong password.")
+	}
+
+	// Decrypt the secret.
+	bytes, err := crypt(dk[:16], iv, message)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	defer clear(bytes)
+	return bls.PrivateKeyFromBytes(system, bytes)
+
+}
+
+// Encrypt or decrypt with AES-128-CTR.
+func crypt(key []byte, iv []byte, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// Decode a hex-encoded string parameter.
+func hexParam(params map[string]interface{}, name string) ([]byte, error) {
+	s, ok := params[name].(string)
+	if !ok {
+		return nil, errors.New("keystore.Decrypt: Missing parameter.")
+	}
+	return hex.DecodeString(s)
+}
+
+// Strip the control codes from the password as EIP-2335 requires. Since NFKD
+// normalization is not available, non-ASCII passwords are rejected rather than
+// being processed differently from other implementations.
+func normalize(password string) ([]byte, error) {
+	var pass []byte
+	for i := 0; i < len(password); i++ {
+		b := password[i]
+		if b >= 0x80 {
+			return nil, errors.New("keystore: Non-ASCII password.")
+		}
+		if b >= 0x20 && b != 0x7f {
+			pass = append(pass, b)
+		}
+	}
+	return pass, nil
+}
@@ -0,0 +1,76 @@
+/**
+ * File        : keystore_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for encrypted keystores.
+ */
+
+package keystore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+func TestEncryptDecrypt(test *testing.T) {
+
+	// Generate a key pair.
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := bls.GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Round-trip the private key.
+	data, err := Encrypt(key, secret, "testpassword\x7f")
+	if err != nil {
+		test.Fatal(err)
+	}
+	secretIn, err := Decrypt(system, data, "testpassword")
+	if err != nil {
+		test.Fatal(err)
+	}
+	if secretIn.Hex() != secret.Hex() {
+		test.Fatal("Private keys do not match.")
+	}
+
+	// A wrong password must be rejected.
+	_, err = Decrypt(system, data, "wrongpassword")
+	if err == nil {
+		test.Fatal("Accepted a wrong password.")
+	}
+
+	// Excessive iteration counts and nonstandard key lengths must be rejected
+	// before any key is derived.
+	for param, value := range map[string]float64{"c": 1e12, "dklen": 1 << 30} {
+		var ks map[string]interface{}
+		err = json.Unmarshal(data, &ks)
+		if err != nil {
+			test.Fatal(err)
+		}
+		kdf := ks["crypto"].(map[string]interface{})["kdf"].(map[string]interface{})
+		kdf["params"].(map[string]interface{})[param] = value
+		crafted, err := json.Marshal(ks)
+		if err != nil {
+			test.Fatal(err)
+		}
+		_, err = Decrypt(system, crafted, "testpassword")
+		if err == nil {
+			test.Fatalf("Accepted a keystore with %s = %v.", param, value)
+		}
+	}
+
+	// Clean up.
+	secretIn.Free()
+	secret.Free()
+	key.Free()
+
+}
@@ -0,0 +1,181 @@
+/**
+ * File        : manager.go
+ * Description : Keystore directories.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module manages a directory of EIP-2335 keystores of one cryptosystem.
+ * Each keystore is stored in a file named after the fingerprint of its public
+ * key, so that services holding many keys can resolve the private key for a
+ * public key without scanning the directory.
+ */
+
+package keystore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enzoh/go-bls"
+)
+
+// A Manager manages a directory of keystores.
+type Manager struct {
+	dir    string
+	system bls.System
+}
+
+// Open the keystore directory for the cryptosystem, creating it if it does
+// not exist.
+func OpenManager(dir string, system bls.System) (Manager, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return Manager{}, err
+	}
+	return Manager{dir, system}, nil
+}
+
+// Compute the fingerprint of a public key, which is the hex-encoded SHA-256
+// hash of its byte encoding.
+func Fingerprint(key bls.PublicKey) string {
+	hash := sha256.Sum256(key.ToBytes())
+	return hex.EncodeToString(hash[:])
+}
+
+// List the fingerprints of the stored keys in sorted order.
+func (manager Manager) List() ([]string, error) {
+	files, err := ioutil.ReadDir(manager.dir)
+	if err != nil {
+		return nil, err
+	}
+	var fingerprints []string
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		if !file.IsDir() && name != file.Name() && validFingerprint(name) {
+			fingerprints = append(fingerprints, name)
+		}
+	}
+	sort.Strings(fingerprints)
+	return fingerprints, nil
+}
+
+// Encrypt a key pair with the password and store it. The fingerprint of the
+// public key is returned.
+func (manager Manager) Add(key bls.PublicKey, secret bls.PrivateKey, password string) (string, error) {
+	data, err := Encrypt(key, secret, password)
+	if err != nil {
+		return "", err
+	}
+	fingerprint := Fingerprint(key)
+	return fingerprint, manager.write(fingerprint, data)
+}
+
+// Import a keystore encrypted with the password. The keystore is decrypted to
+// check the password and to derive the public key, and stored unchanged. The
+// fingerprint of the public key is returned.
+func (manager Manager) Import(data []byte, password string) (string, error) {
+	secret, err := Decrypt(manager.system, data, password)
+	if err != nil {
+		return "", err
+	}
+	key := secret.Public()
+	fingerprint := Fingerprint(key)
+	key.Free()
+	secret.Zeroize()
+	return fingerprint, manager.write(fingerprint, data)
+}
+
+// Export the keystore of the key with the given fingerprint. The keystore
+// remains encrypted.
+func (manager Manager) Export(fingerprint string) ([]byte, error) {
+	path, err := manager.path(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// Decrypt the private key with the given fingerprint.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (manager Manager) Lookup(fingerprint string, password string) (bls.PrivateKey, error) {
+	data, err := manager.Export(fingerprint)
+	if err != nil {
+		return bls.PrivateKey{}, err
+	}
+	return Decrypt(manager.system, data, password)
+}
+
+// Decrypt the private key of the public key.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (manager Manager) LookupKey(key bls.PublicKey, password string) (bls.PrivateKey, error) {
+	return manager.Lookup(Fingerprint(key), password)
+}
+
+// Remove the keystore of the key with the given fingerprint.
+func (manager Manager) Remove(fingerprint string) error {
+	path, err := manager.path(fingerprint)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Determine the path of the keystore with the given fingerprint.
+func (manager Manager) path(fingerprint string) (string, error) {
+	if !validFingerprint(fingerprint) {
+		return "", errors.New("keystore: Invalid fingerprint.")
+	}
+	return filepath.Join(manager.dir, fingerprint+".json"), nil
+}
+
+// Write a keystore atomically, readable only by the owner.
+func (manager Manager) write(fingerprint string, data []byte) error {
+	path, err := manager.path(fingerprint)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(manager.dir, fingerprint)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Check that a fingerprint is lower-case hex of the right length, which also
+// keeps it from escaping the directory.
+func validFingerprint(fingerprint string) bool {
+	if len(fingerprint) != 2*sha256.Size {
+		return false
+	}
+	for i := 0; i < len(fingerprint); i++ {
+		c := fingerprint[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
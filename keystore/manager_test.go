@@ -0,0 +1,92 @@
+/**
+ * File        : manager_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for keystore directories.
+ */
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+func TestManager(test *testing.T) {
+
+	// Open a keystore directory.
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		test.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "go-bls")
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	manager, err := OpenManager(dir, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Add a key and import another.
+	key1, secret1, err := bls.GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key2, secret2, err := bls.GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = manager.Add(key1, secret1, "password")
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err := Encrypt(key2, secret2, "other")
+	if err != nil {
+		test.Fatal(err)
+	}
+	fingerprint, err := manager.Import(data, "other")
+	if err != nil {
+		test.Fatal(err)
+	}
+	if fingerprint != Fingerprint(key2) {
+		test.Fatal("Unexpected fingerprint.")
+	}
+
+	// List the keys and resolve a private key.
+	fingerprints, err := manager.List()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(fingerprints) != 2 {
+		test.Fatal("Unexpected number of keys.")
+	}
+	secret, err := manager.LookupKey(key2, "other")
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !secret.Equal(secret2) {
+		test.Fatal("Private keys do not match.")
+	}
+
+	// Paths outside the directory must be rejected.
+	_, err = manager.Export("../" + fingerprint)
+	if err == nil {
+		test.Fatal("Accepted an invalid fingerprint.")
+	}
+
+	// Clean up.
+	secret.Free()
+	key1.Free()
+	key2.Free()
+	secret1.Free()
+	secret2.Free()
+
+}
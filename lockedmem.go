@@ -0,0 +1,260 @@
+/**
+ * File        : lockedmem.go
+ * Description : Locked memory.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module keeps secret scalars, such as private keys, key shares, and the
+ * exponents used while signing, in a locked region of memory, so that they are
+ * never swapped to disk. The region is reserved once, surrounded by
+ * inaccessible guard pages, and scrubbed as allocations are freed. The memory
+ * functions of the PBC and GMP libraries are replaced with functions that
+ * serve allocations from the region only while a secret is being allocated,
+ * and that pass every other allocation, such as the temporaries of Verify,
+ * through to the C library.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+#include <errno.h>
+#include <pthread.h>
+#include <stddef.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/mman.h>
+#include <unistd.h>
+
+// The header preceding each block of the locked region.
+typedef struct locked_block {
+	size_t size;
+	struct locked_block *next;
+} locked_block;
+
+static unsigned char *locked_base;
+static size_t locked_size;
+static size_t locked_used;
+static size_t locked_overflows;
+static locked_block *locked_free_list;
+static pthread_mutex_t locked_mutex = PTHREAD_MUTEX_INITIALIZER;
+static __thread int locked_depth;
+
+// Mark the allocations of the current thread as secret until locked_leave.
+void locked_enter(void) {
+	locked_depth++;
+}
+
+void locked_leave(void) {
+	locked_depth--;
+}
+
+static int locked_contains(void *ptr) {
+	return locked_base != NULL && (unsigned char *)ptr >= locked_base && (unsigned char *)ptr < locked_base + locked_size;
+}
+
+// Take a block from the region by first fit, or return NULL if none is large
+// enough. The caller must hold the mutex.
+static void *locked_take(size_t size) {
+	size_t need = (sizeof(locked_block) + size + 15) & ~(size_t)15;
+	locked_block **prev = &locked_free_list;
+	for (locked_block *b = locked_free_list; b != NULL; prev = &b->next, b = b->next) {
+		if (b->size < need) {
+			continue;
+		}
+		if (b->size - need >= 2 * sizeof(locked_block) + 16) {
+			locked_block *rest = (locked_block *)((unsigned char *)b + need);
+			rest->size = b->size - need;
+			rest->next = b->next;
+			b->size = need;
+			*prev = rest;
+		} else {
+			*prev = b->next;
+		}
+		locked_used += b->size;
+		return b + 1;
+	}
+	return NULL;
+}
+
+// Scrub a block and return it to the region, merging it with its neighbours.
+// The caller must hold the mutex.
+static void locked_give(void *ptr) {
+	locked_block *b = (locked_block *)ptr - 1;
+	volatile unsigned char *p = (volatile unsigned char *)ptr;
+	for (size_t i = 0; i < b->size - sizeof(locked_block); i++) {
+		p[i] = 0;
+	}
+	locked_used -= b->size;
+	locked_block **prev = &locked_free_list;
+	while (*prev != NULL && *prev < b) {
+		prev = &(*prev)->next;
+	}
+	b->next = *prev;
+	*prev = b;
+	if (b->next != NULL && (unsigned char *)b + b->size == (unsigned char *)b->next) {
+		b->size += b->next->size;
+		b->next = b->next->next;
+	}
+	if (prev != &locked_free_list) {
+		locked_block *a = (locked_block *)((unsigned char *)prev - offsetof(locked_block, next));
+		if ((unsigned char *)a + a->size == (unsigned char *)b) {
+			a->size += b->size;
+			a->next = b->next;
+		}
+	}
+}
+
+static void *locked_malloc(size_t size) {
+	if (locked_depth > 0) {
+		pthread_mutex_lock(&locked_mutex);
+		void *ptr = locked_take(size);
+		if (ptr == NULL) {
+			locked_overflows++;
+		}
+		pthread_mutex_unlock(&locked_mutex);
+		if (ptr != NULL) {
+			return ptr;
+		}
+	}
+	return malloc(size);
+}
+
+static void locked_free(void *ptr) {
+	if (!locked_contains(ptr)) {
+		free(ptr);
+		return;
+	}
+	pthread_mutex_lock(&locked_mutex);
+	locked_give(ptr);
+	pthread_mutex_unlock(&locked_mutex);
+}
+
+static void *locked_realloc(void *ptr, size_t size) {
+	if (!locked_contains(ptr)) {
+		if (ptr == NULL) {
+			return locked_malloc(size);
+		}
+		return realloc(ptr, size);
+	}
+	size_t old = ((locked_block *)ptr - 1)->size - sizeof(locked_block);
+	locked_depth++;
+	void *next = locked_malloc(size);
+	locked_depth--;
+	if (next != NULL) {
+		memcpy(next, ptr, old < size ? old : size);
+	}
+	locked_free(ptr);
+	return next;
+}
+
+static void *locked_gmp_realloc(void *ptr, size_t old, size_t size) {
+	return locked_realloc(ptr, size);
+}
+
+static void locked_gmp_free(void *ptr, size_t size) {
+	locked_free(ptr);
+}
+
+// Reserve and lock the region, and return the error number on failure.
+static int locked_reserve(size_t size) {
+	size_t page = (size_t)sysconf(_SC_PAGESIZE);
+	size = (size + page - 1) / page * page;
+	unsigned char *base = mmap(NULL, size + 2 * page, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_ANONYMOUS, -1, 0);
+	if (base == MAP_FAILED) {
+		return errno;
+	}
+	if (mprotect(base, page, PROT_NONE) != 0 || mprotect(base + page + size, page, PROT_NONE) != 0 || mlock(base + page, size) != 0) {
+		int err = errno;
+		munmap(base, size + 2 * page);
+		return err;
+	}
+#ifdef MADV_DONTDUMP
+	madvise(base + page, size, MADV_DONTDUMP);
+#endif
+	locked_free_list = (locked_block *)(base + page);
+	locked_free_list->size = size;
+	locked_free_list->next = NULL;
+	locked_size = size;
+	locked_base = base + page;
+	pbc_set_memory_functions(locked_malloc, locked_realloc, locked_free);
+	mp_set_memory_functions(locked_malloc, locked_gmp_realloc, locked_gmp_free);
+	return 0;
+}
+
+// Initialize an element of Zr in the locked region.
+static void element_init_secret(struct element_s *e, struct pairing_s *pairing) {
+	locked_enter();
+	element_init_Zr(e, pairing);
+	locked_leave();
+}
+
+static size_t locked_in_use(void) {
+	pthread_mutex_lock(&locked_mutex);
+	size_t used = locked_used;
+	pthread_mutex_unlock(&locked_mutex);
+	return used;
+}
+
+static size_t locked_overflow_count(void) {
+	pthread_mutex_lock(&locked_mutex);
+	size_t n = locked_overflows;
+	pthread_mutex_unlock(&locked_mutex);
+	return n;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+)
+
+var lockMemory struct {
+	once sync.Once
+	err  error
+}
+
+// Reserve size bytes of locked memory for the secret scalars of the package,
+// and scrub each scalar when it is freed. A private key of the default
+// cryptosystem occupies about 64 bytes. Secrets that do not fit fall back to
+// ordinary memory rather than failing, which LockedMemoryOverflows reports.
+// RLIMIT_MEMLOCK must allow the region to be locked, or an error is returned
+// and nothing changes.
+//
+// LockMemory may be called at any time, but only secrets allocated after the
+// call are locked, and only the first call has an effect. Copies of secrets
+// held by Go, such as the byte slices returned by PrivateKey.ToBytes, are not
+// covered.
+func LockMemory(size int) error {
+	lockMemory.once.Do(func() {
+		if size <= 0 {
+			lockMemory.err = errors.New("bls.LockMemory: Size must be positive.")
+			return
+		}
+		errno := C.locked_reserve(C.size_t(size))
+		if errno != 0 {
+			lockMemory.err = errors.New("bls.LockMemory: " + syscall.Errno(errno).Error() + ".")
+		}
+	})
+	return lockMemory.err
+}
+
+// Get the number of secret allocations that did not fit in the locked memory
+// reserved by LockMemory and were placed in ordinary memory instead.
+func LockedMemoryOverflows() int {
+	return int(C.locked_overflow_count())
+}
+
+// Initialize an element of Zr that holds a secret, in locked memory if
+// LockMemory was called.
+func initSecret(e *C.struct_element_s, system System) {
+	C.element_init_secret(e, system.pairing.get)
+}
+
+// Get the number of bytes of locked memory in use.
+func lockedInUse() int {
+	return int(C.locked_in_use())
+}
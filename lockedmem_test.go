@@ -0,0 +1,83 @@
+/**
+ * File        : lockedmem_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for locked memory.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// LockMemory affects the whole process, so the test runs itself in a fresh
+// process.
+func TestLockMemory(test *testing.T) {
+
+	if os.Getenv("GO_BLS_LOCK_MEMORY") != "1" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockMemory$")
+		cmd.Env = append(os.Environ(), "GO_BLS_LOCK_MEMORY=1")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			test.Fatalf("%v\n%s", err, output)
+		}
+		return
+	}
+
+	message := "This is a message."
+
+	// Generate a cryptosystem before locking memory.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Lock memory, unless the limit is too low to test.
+	err = LockMemory(1 << 16)
+	if err != nil {
+		test.Skip(err)
+	}
+	if lockedInUse() != 0 {
+		test.Fatal("Public values occupy locked memory.")
+	}
+
+	// Sign and verify with a private key in locked memory.
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if lockedInUse() == 0 {
+		test.Fatal("Private key is not in locked memory.")
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Free the private key and check that the locked memory is released.
+	secret.Zeroize()
+	if lockedInUse() != 0 {
+		test.Fatal("Locked memory was not released.")
+	}
+	if LockedMemoryOverflows() != 0 {
+		test.Fatal("Locked memory overflowed.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
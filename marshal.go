@@ -0,0 +1,259 @@
+/**
+ * File        : marshal.go
+ * Description : Binary marshaling.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements encoding.BinaryMarshaler and BinaryUnmarshaler for
+ * the exported types. Keys and elements are only meaningful within a
+ * cryptosystem, so their encodings carry the fingerprint of the system, and
+ * decoding resolves it against a registry of known systems.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+// The group of an element, as recorded in its binary encoding.
+const (
+	groupG1 byte = iota + 1
+	groupG2
+	groupGT
+	groupZr
+)
+
+var registry = struct {
+	sync.RWMutex
+	systems map[[sha256.Size]byte]System
+}{systems: make(map[[sha256.Size]byte]System)}
+
+// Register a cryptosystem, so that keys and elements belonging to it can be
+// unmarshaled without an explicit system. The system must not be freed while
+// it is registered. The registry is keyed by the fingerprint, which does not
+// cover the hash function, the domain separation tag, the encoding, or the
+// subgroup check policy, so a system that differs from a registered one only
+// in those settings is rejected rather than silently replacing it.
+func RegisterSystem(system System) error {
+	fingerprint := system.Fingerprint()
+	registry.Lock()
+	defer registry.Unlock()
+	if other, ok := registry.systems[fingerprint]; ok {
+		if !bytes.Equal(other.ToBytes(), system.ToBytes()) {
+			return errors.New("bls.RegisterSystem: Another system with the same fingerprint is registered.")
+		}
+		return nil
+	}
+	registry.systems[fingerprint] = system
+	return nil
+}
+
+// Remove a cryptosystem from the registry.
+func UnregisterSystem(system System) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.systems, system.Fingerprint())
+}
+
+// Look up a registered cryptosystem by its fingerprint.
+func lookupSystem(fingerprint []byte) (System, error) {
+	var key [sha256.Size]byte
+	if copy(key[:], fingerprint) != sha256.Size {
+		return System{}, errors.New("bls.UnmarshalBinary: Data too short.")
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	system, ok := registry.systems[key]
+	if !ok {
+		return System{}, errors.New("bls.UnmarshalBinary: Unregistered system.")
+	}
+	return system, nil
+}
+
+// Look up a registered cryptosystem whose pairing contains the element, and
+// determine the group of the element.
+func lookupElement(element Element) (System, byte, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, system := range registry.systems {
+		switch element.get.field {
+		case system.pairing.get.G1:
+			return system, groupG1, nil
+		case system.pairing.get.G2:
+			return system, groupG2, nil
+		case &system.pairing.get.GT[0]:
+			return system, groupGT, nil
+		case &system.pairing.get.Zr[0]:
+			return system, groupZr, nil
+		}
+	}
+	return System{}, 0, errors.New("bls.MarshalBinary: Unregistered system.")
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (params Params) MarshalBinary() ([]byte, error) {
+	return params.ToBytes()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (params *Params) UnmarshalBinary(data []byte) error {
+	p, err := ParamsFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*params = p
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (system System) MarshalBinary() ([]byte, error) {
+	return system.ToBytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (system *System) UnmarshalBinary(data []byte) error {
+	s, err := SystemFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*system = s
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the
+// fingerprint of the cryptosystem followed by the output of ToBytes.
+func (key PublicKey) MarshalBinary() ([]byte, error) {
+	fingerprint := key.system.Fingerprint()
+	return append(fingerprint[:], key.ToBytes()...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. If the key already
+// belongs to a cryptosystem, the data must belong to the same system.
+// Otherwise, the system is looked up in the registry.
+func (key *PublicKey) UnmarshalBinary(data []byte) error {
+	system, err := key.system.resolve(data)
+	if err != nil {
+		return err
+	}
+	k, err := PublicKeyFromBytes(system, data[sha256.Size:])
+	if err != nil {
+		return err
+	}
+	*key = k
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the
+// fingerprint of the cryptosystem followed by the output of ToBytes.
+func (secret PrivateKey) MarshalBinary() ([]byte, error) {
+	fingerprint := secret.system.Fingerprint()
+	return append(fingerprint[:], secret.ToBytes()...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. If the key already
+// belongs to a cryptosystem, the data must belong to the same system.
+// Otherwise, the system is looked up in the registry.
+func (secret *PrivateKey) UnmarshalBinary(data []byte) error {
+	system, err := secret.system.resolve(data)
+	if err != nil {
+		return err
+	}
+	s, err := PrivateKeyFromBytes(system, data[sha256.Size:])
+	if err != nil {
+		return err
+	}
+	*secret = s
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Since an element does not
+// know its cryptosystem, the system must be registered. The encoding is the
+// fingerprint of the system, the group of the element, and the uncompressed
+// element.
+func (element Element) MarshalBinary() ([]byte, error) {
+	system, group, err := lookupElement(element)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := system.Fingerprint()
+	return append(append(fingerprint[:], group), element.key()...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The system of the
+// element must be registered.
+func (element *Element) UnmarshalBinary(data []byte) error {
+
+	// Look up the cryptosystem and the group.
+	system, err := lookupSystem(data)
+	if err != nil {
+		return err
+	}
+	if len(data) < sha256.Size+1 {
+		return errors.New("bls.UnmarshalBinary: Data too short.")
+	}
+	group := data[sha256.Size]
+	data = data[sha256.Size+1:]
+
+	// Decode the element.
+	e := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	switch group {
+	case groupG1:
+		C.element_init_G1(e, system.pairing.get)
+	case groupG2:
+		C.element_init_G2(e, system.pairing.get)
+	case groupGT:
+		C.element_init_GT(e, system.pairing.get)
+	case groupZr:
+		initSecret(e, system)
+	default:
+		C.free(unsafe.Pointer(e))
+		return errors.New("bls.UnmarshalBinary: Unknown group.")
+	}
+	if int(C.element_length_in_bytes(e)) != len(data) {
+		C.element_clear(e)
+		return errors.New("bls.UnmarshalBinary: Element length mismatch.")
+	}
+	if !elementFromBytes(e, data, PointUncompressed) {
+		C.element_clear(e)
+		return errors.New("bls.UnmarshalBinary: Failed to decode element.")
+	}
+	if (group == groupG1 || group == groupG2) && !onCurve(e, data, PointUncompressed) {
+		C.element_clear(e)
+		return errors.New("bls.UnmarshalBinary: Element not on curve.")
+	}
+	if (group == groupG1 || group == groupG2) && isIdentity(Element{e}) {
+		C.element_clear(e)
+		return errors.New("bls.UnmarshalBinary: Element is the identity.")
+	}
+	if (group == groupG1 || group == groupG2) && system.checkOnDeserialize() && !system.inSubgroup(Element{e}) {
+		C.element_clear(e)
+		return errors.New("bls.UnmarshalBinary: Element not in subgroup.")
+	}
+	element.get = e
+	return nil
+
+}
+
+// Determine the cryptosystem of the encoded key. If the receiver belongs to a
+// cryptosystem, the fingerprints must match.
+func (system System) resolve(data []byte) (System, error) {
+	if system.pairing.get == nil {
+		return lookupSystem(data)
+	}
+	fingerprint := system.Fingerprint()
+	if len(data) < sha256.Size || string(data[:sha256.Size]) != string(fingerprint[:]) {
+		return System{}, errors.New("bls.UnmarshalBinary: System mismatch.")
+	}
+	return system, nil
+}
@@ -0,0 +1,146 @@
+/**
+ * File        : marshal_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for binary marshaling.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	stdencoding "encoding"
+	"testing"
+)
+
+var (
+	_ stdencoding.BinaryMarshaler   = Params{}
+	_ stdencoding.BinaryUnmarshaler = &Params{}
+	_ stdencoding.BinaryMarshaler   = System{}
+	_ stdencoding.BinaryUnmarshaler = &System{}
+	_ stdencoding.BinaryMarshaler   = PublicKey{}
+	_ stdencoding.BinaryUnmarshaler = &PublicKey{}
+	_ stdencoding.BinaryMarshaler   = PrivateKey{}
+	_ stdencoding.BinaryUnmarshaler = &PrivateKey{}
+	_ stdencoding.BinaryMarshaler   = Signature{}
+	_ stdencoding.BinaryUnmarshaler = &Signature{}
+)
+
+func TestMarshalBinary(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Elements cannot be marshaled before the system is registered.
+	_, err = signature.MarshalBinary()
+	if err == nil {
+		test.Fatal("Marshaled an element of an unregistered system.")
+	}
+	err = RegisterSystem(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer UnregisterSystem(system)
+
+	// A system that differs only in its hash function cannot be registered.
+	err = RegisterSystem(system.WithHash(HashSHA3_256))
+	if err == nil {
+		test.Fatal("Registered a second system with the same fingerprint.")
+	}
+	err = RegisterSystem(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Round-trip the key pair and the signature.
+	data, err := key.MarshalBinary()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var keyIn PublicKey
+	err = keyIn.UnmarshalBinary(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err = secret.MarshalBinary()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var secretIn PrivateKey
+	err = secretIn.UnmarshalBinary(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err = signature.MarshalBinary()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var signatureIn Signature
+	err = signatureIn.UnmarshalBinary(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+	signatureOut := Sign(hash, secretIn)
+	if !Verify(signatureOut, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Round-trip the parameters.
+	data, err = params.MarshalBinary()
+	if err != nil {
+		test.Fatal(err)
+	}
+	var paramsIn Params
+	err = paramsIn.UnmarshalBinary(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if paramsIn.String() != params.String() {
+		test.Fatal("Parameters do not match.")
+	}
+
+	// A key of another system must be rejected.
+	other, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	otherKey := PublicKey{system: other}
+	data, _ = key.MarshalBinary()
+	if otherKey.UnmarshalBinary(data) == nil {
+		test.Fatal("Unmarshaled a key of another system.")
+	}
+
+	// Clean up.
+	other.Free()
+	paramsIn.Free()
+	signatureOut.Free()
+	signatureIn.Free()
+	signature.Free()
+	secretIn.Free()
+	keyIn.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
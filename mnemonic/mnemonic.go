@@ -0,0 +1,173 @@
+/**
+ * File        : mnemonic.go
+ * Description : Mnemonic key derivation.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module generates and recovers key pairs from BIP-39 mnemonics, so that
+ * keys can be backed up on paper. The BIP-39 seed of the mnemonic and the
+ * optional passphrase is passed to bls.GenKeysFromSeed. The wordlist is not
+ * bundled: load the standard english.txt from the BIP-39 repository with
+ * ParseWordlist. Since NFKD normalization is not available, mnemonics and
+ * passphrases must be ASCII.
+ */
+
+package mnemonic
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/enzoh/go-bls"
+)
+
+// The number of words in a wordlist.
+const wordlistSize = 2048
+
+// A Wordlist is a sorted list of 2048 distinct words.
+type Wordlist []string
+
+// Parse a wordlist with one word per line.
+func ParseWordlist(data []byte) (Wordlist, error) {
+	words := Wordlist(strings.Fields(string(data)))
+	if len(words) != wordlistSize {
+		return nil, errors.New("mnemonic.ParseWordlist: Wordlist must have 2048 words.")
+	}
+	for i := range words {
+		if !ascii(words[i]) {
+			return nil, errors.New("mnemonic.ParseWordlist: Non-ASCII word.")
+		}
+		if i > 0 && words[i-1] >= words[i] {
+			return nil, errors.New("mnemonic.ParseWordlist: Words must be sorted and distinct.")
+		}
+	}
+	return words, nil
+}
+
+// Generate a mnemonic encoding the given number of bits of entropy, which must
+// be 128, 160, 192, 224, or 256.
+func New(bits int, words Wordlist) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", errors.New("mnemonic.New: Bad entropy length.")
+	}
+	entropy := make([]byte, bits/8)
+	_, err := rand.Read(entropy)
+	if err != nil {
+		return "", err
+	}
+	return FromEntropy(entropy, words)
+}
+
+// Encode the entropy as a mnemonic. The entropy is followed by the leading
+// bits of its SHA-256 hash, one for every 32 bits of entropy, and the result
+// is split into 11-bit word indices.
+func FromEntropy(entropy []byte, words Wordlist) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", errors.New("mnemonic.FromEntropy: Bad entropy length.")
+	}
+	if len(words) != wordlistSize {
+		return "", errors.New("mnemonic.FromEntropy: Bad wordlist.")
+	}
+	cs := len(entropy) / 4
+	hash := sha256.Sum256(entropy)
+	x := big.NewInt(0).SetBytes(entropy)
+	x.Lsh(x, uint(cs))
+	x.Or(x, big.NewInt(int64(hash[0]>>uint(8-cs))))
+	n := (8*len(entropy) + cs) / 11
+	out := make([]string, n)
+	mask := big.NewInt(wordlistSize - 1)
+	index := big.NewInt(0)
+	for i := n - 1; i >= 0; i-- {
+		out[i] = words[index.And(x, mask).Int64()]
+		x.Rsh(x, 11)
+	}
+	return strings.Join(out, " "), nil
+}
+
+// Decode a mnemonic to its entropy, checking the checksum.
+func ToEntropy(mnemonic string, words Wordlist) ([]byte, error) {
+	fields := strings.Fields(mnemonic)
+	n := len(fields)
+	if n < 12 || n > 24 || n%3 != 0 {
+		return nil, errors.New("mnemonic.ToEntropy: Bad mnemonic length.")
+	}
+	if len(words) != wordlistSize {
+		return nil, errors.New("mnemonic.ToEntropy: Bad wordlist.")
+	}
+	x := big.NewInt(0)
+	for _, field := range fields {
+		i := find(words, field)
+		if i < 0 {
+			return nil, errors.New("mnemonic.ToEntropy: Unknown word.")
+		}
+		x.Lsh(x, 11)
+		x.Or(x, big.NewInt(int64(i)))
+	}
+	cs := n / 3
+	checksum := byte(big.NewInt(0).And(x, big.NewInt(int64(1<<uint(cs)-1))).Int64())
+	x.Rsh(x, uint(cs))
+	entropy := x.FillBytes(make([]byte, 4*cs))
+	hash := sha256.Sum256(entropy)
+	if hash[0]>>uint(8-cs) != checksum {
+		return nil, errors.New("mnemonic.ToEntropy: Invalid checksum.")
+	}
+	return entropy, nil
+}
+
+// Compute the 64-byte BIP-39 seed of the mnemonic and the passphrase.
+func Seed(mnemonic string, passphrase string) ([]byte, error) {
+	if !ascii(mnemonic) || !ascii(passphrase) {
+		return nil, errors.New("mnemonic.Seed: Non-ASCII input.")
+	}
+	return pbkdf2.Key(sha512.New, mnemonic, []byte("mnemonic"+passphrase), 2048, 64)
+}
+
+// Derive a key pair of the cryptosystem from the mnemonic and the passphrase.
+// The mnemonic is checked against the wordlist first.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func GenKeys(system bls.System, mnemonic string, passphrase string, words Wordlist) (bls.PublicKey, bls.PrivateKey, error) {
+	_, err := ToEntropy(mnemonic, words)
+	if err != nil {
+		return bls.PublicKey{}, bls.PrivateKey{}, err
+	}
+	seed, err := Seed(strings.Join(strings.Fields(mnemonic), " "), passphrase)
+	if err != nil {
+		return bls.PublicKey{}, bls.PrivateKey{}, err
+	}
+	return bls.GenKeysFromSeed(system, seed)
+}
+
+// Find the index of a word in the sorted wordlist.
+func find(words Wordlist, word string) int {
+	lo, hi := 0, len(words)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if words[mid] < word {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(words) && words[lo] == word {
+		return lo
+	}
+	return -1
+}
+
+func ascii(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
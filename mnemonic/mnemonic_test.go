@@ -0,0 +1,126 @@
+/**
+ * File        : mnemonic_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for mnemonic key derivation.
+ */
+
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/enzoh/go-bls"
+)
+
+// Build a wordlist with the same structure as the BIP-39 English wordlist, in
+// which the words at indices 0, 3, and 2047 are abandon, about, and zoo.
+func testWordlist(test *testing.T) Wordlist {
+	var lines []string
+	for i := 0; i < wordlistSize; i++ {
+		lines = append(lines, fmt.Sprintf("b%04d", i))
+	}
+	lines[0], lines[1], lines[2], lines[3], lines[2047] = "abandon", "abc1", "abc2", "about", "zoo"
+	words, err := ParseWordlist([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		test.Fatal(err)
+	}
+	return words
+}
+
+func TestEntropy(test *testing.T) {
+
+	words := testWordlist(test)
+
+	// The all-zero entropy encodes to the BIP-39 test vector.
+	mnemonic, err := FromEntropy(make([]byte, 16), words)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if mnemonic != strings.Repeat("abandon ", 11)+"about" {
+		test.Fatal("Unexpected mnemonic.")
+	}
+
+	// Round-trip random entropy.
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		mnemonic, err := New(bits, words)
+		if err != nil {
+			test.Fatal(err)
+		}
+		entropy, err := ToEntropy(mnemonic, words)
+		if err != nil {
+			test.Fatal(err)
+		}
+		again, _ := FromEntropy(entropy, words)
+		if again != mnemonic {
+			test.Fatal("Mnemonics do not match.")
+		}
+	}
+
+	// A bad checksum must be rejected.
+	_, err = ToEntropy(strings.Repeat("abandon ", 12), words)
+	if err == nil {
+		test.Fatal("Accepted a bad checksum.")
+	}
+
+}
+
+func TestSeed(test *testing.T) {
+
+	// BIP-39 test vector.
+	seed, err := Seed(strings.Repeat("abandon ", 11)+"about", "TREZOR")
+	if err != nil {
+		test.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	if !bytes.Equal(seed, expected) {
+		test.Fatal("Unexpected seed.")
+	}
+
+}
+
+func TestGenKeys(test *testing.T) {
+
+	words := testWordlist(test)
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// The same mnemonic and passphrase recover the same key.
+	mnemonic, err := New(256, words)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key1, secret1, err := GenKeys(system, mnemonic, "passphrase", words)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key2, secret2, err := GenKeys(system, mnemonic, "passphrase", words)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key3, secret3, err := GenKeys(system, mnemonic, "other", words)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if key1.Hex() != key2.Hex() || key1.Hex() == key3.Hex() {
+		test.Fatal("Unexpected key derivation.")
+	}
+
+	// Clean up.
+	key1.Free()
+	key2.Free()
+	key3.Free()
+	secret1.Free()
+	secret2.Free()
+	secret3.Free()
+
+}
@@ -0,0 +1,121 @@
+/**
+ * File        : multiexp.go
+ * Description : Multi-exponentiation.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module computes products of powers, such as the Lagrange interpolation
+ * of signature shares, with the bucket method of Pippenger. The exponents are
+ * split into windows of c bits. For each window, the bases are sorted into
+ * buckets by their digits, and the buckets are combined with a running
+ * product, so that each base costs one multiplication per window rather than
+ * an exponentiation of its own. Short products fall back to the naive method.
+ */
+
+package bls
+
+/*
+#include <stdlib.h>
+#include <pbc/pbc.h>
+
+void multi_exp(struct element_s *out, struct element_s **bases, __mpz_struct *exps, int n) {
+	element_t t, acc, sum;
+	size_t bits = 0;
+	for (int i = 0; i < n; i++) {
+		if (mpz_sizeinbase(&exps[i], 2) > bits) {
+			bits = mpz_sizeinbase(&exps[i], 2);
+		}
+	}
+	element_set1(out);
+	if (n < 4) {
+		element_init_same_as(t, out);
+		for (int i = 0; i < n; i++) {
+			element_pow_mpz(t, bases[i], &exps[i]);
+			element_mul(out, out, t);
+		}
+		element_clear(t);
+		return;
+	}
+	int c = 2;
+	while (c < 16 && (1 << (c + 2)) <= n) {
+		c++;
+	}
+	int m = (1 << c) - 1;
+	element_t *buckets = malloc(m * sizeof(element_t));
+	for (int j = 0; j < m; j++) {
+		element_init_same_as(buckets[j], out);
+	}
+	element_init_same_as(acc, out);
+	element_init_same_as(sum, out);
+	for (int w = (bits + c - 1) / c - 1; w >= 0; w--) {
+		for (int k = 0; k < c; k++) {
+			element_square(out, out);
+		}
+		for (int j = 0; j < m; j++) {
+			element_set1(buckets[j]);
+		}
+		for (int i = 0; i < n; i++) {
+			int digit = 0;
+			for (int k = c - 1; k >= 0; k--) {
+				digit = digit << 1 | mpz_tstbit(&exps[i], w * c + k);
+			}
+			if (digit) {
+				element_mul(buckets[digit - 1], buckets[digit - 1], bases[i]);
+			}
+		}
+		element_set1(acc);
+		element_set1(sum);
+		for (int j = m - 1; j >= 0; j--) {
+			element_mul(acc, acc, buckets[j]);
+			element_mul(sum, sum, acc);
+		}
+		element_mul(out, out, sum);
+	}
+	for (int j = 0; j < m; j++) {
+		element_clear(buckets[j]);
+	}
+	free(buckets);
+	element_clear(acc);
+	element_clear(sum);
+}
+*/
+import "C"
+
+import (
+	"math/big"
+	"unsafe"
+)
+
+const sizeOfMpz = C.size_t(unsafe.Sizeof(C.__mpz_struct{}))
+
+// Set the result to the product of the bases raised to the exponents, which
+// must not be negative. The result must be initialized in the group of the
+// bases and must not be one of them.
+func multiExp(result *C.struct_element_s, bases []*C.struct_element_s, exps []*big.Int) {
+
+	// Check the list length.
+	n := len(bases)
+	if n == 0 {
+		C.element_set1(result)
+		return
+	}
+
+	// Copy the exponents into an array.
+	mem := C.malloc(C.size_t(n) * sizeOfMpz)
+	zs := unsafe.Slice((*C.__mpz_struct)(mem), n)
+	for i := range zs {
+		C.mpz_init(&zs[i])
+		setMpz(&zs[i], exps[i])
+	}
+
+	// Calculate the product in a single call into C.
+	C.multi_exp(result, &bases[0], &zs[0], C.int(n))
+
+	// Clean up.
+	for i := range zs {
+		C.mpz_clear(&zs[i])
+	}
+	C.free(mem)
+
+}
@@ -0,0 +1,84 @@
+/**
+ * File        : multiexp_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for multi-exponentiation.
+ */
+
+package bls
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestMultiExp(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	r := system.order()
+
+	// Raise signatures under random keys to random exponents, including zero,
+	// and compare the product with the signature under the weighted sum of the
+	// keys, for lists short and long enough to use either method.
+	for _, n := range []int{1, 3, 4, 17, 70} {
+		secrets := make([]PrivateKey, n)
+		shares := make([]Signature, n)
+		exps := make([]*big.Int, n)
+		sum := big.NewInt(0)
+		for i := range secrets {
+			key, secret, err := GenKeys(system)
+			if err != nil {
+				test.Fatal(err)
+			}
+			key.Free()
+			secrets[i] = secret
+			shares[i] = Sign(hash, secret)
+			exps[i], err = rand.Int(rand.Reader, r)
+			if err != nil {
+				test.Fatal(err)
+			}
+			if i == 1 {
+				exps[i].SetInt64(0)
+			}
+			x := new(big.Int).SetBytes(secret.ScalarBytes())
+			sum.Add(sum, x.Mul(x, exps[i]))
+		}
+		scalar := sum.Mod(sum, r).FillBytes(make([]byte, len(secrets[0].ScalarBytes())))
+		secret, err := PrivateKeyFromScalar(system, scalar)
+		if err != nil {
+			test.Fatal(err)
+		}
+		expected := Sign(hash, secret)
+		result := interpolateShares(shares, exps, system)
+		if !result.Equal(expected) {
+			test.Fatalf("Product of %d powers does not match.", n)
+		}
+		for i := range secrets {
+			secrets[i].Free()
+			shares[i].Free()
+		}
+		secret.Free()
+		expected.Free()
+		result.Free()
+	}
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
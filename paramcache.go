@@ -0,0 +1,123 @@
+/**
+ * File        : paramcache.go
+ * Description : Disk cache of pairing parameters.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module persists generated pairing parameters to a directory, so that
+ * test suites and services that restart often search for curves only once.
+ * Each parameter set is stored in a file named after its type and the inputs
+ * of its generator. The files are trusted as they are, so the directory must
+ * only be writable by the owner of the process.
+ */
+
+package bls
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A ParamCache stores generated pairing parameters in a directory.
+type ParamCache struct {
+	dir string
+}
+
+// Open the parameter cache in the given directory, creating it if it does not
+// exist.
+func OpenParamCache(dir string) (ParamCache, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return ParamCache{}, err
+	}
+	return ParamCache{dir}, nil
+}
+
+// Load type A pairing parameters from the cache, or generate them as by
+// GenParamsTypeA and store them. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func (cache ParamCache) GenParamsTypeA(rbits int, qbits int) (Params, error) {
+	return cache.load(fmt.Sprintf("a-%d-%d", rbits, qbits), func() (Params, error) {
+		return GenParamsTypeA(rbits, qbits), nil
+	})
+}
+
+// Load type D pairing parameters from the cache, or generate them as by
+// GenParamsTypeD and store them. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func (cache ParamCache) GenParamsTypeD(d uint, bitlimit uint) (Params, error) {
+	return cache.load(fmt.Sprintf("d-%d-%d", d, bitlimit), func() (Params, error) {
+		return GenParamsTypeD(d, bitlimit)
+	})
+}
+
+// Load type F pairing parameters from the cache, or generate them as by
+// GenParamsTypeF and store them. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func (cache ParamCache) GenParamsTypeF(bits int) (Params, error) {
+	return cache.load(fmt.Sprintf("f-%d", bits), func() (Params, error) {
+		return GenParamsTypeF(bits), nil
+	})
+}
+
+// Load the parameters with the given name, or generate and store them if they
+// are not in the cache.
+func (cache ParamCache) load(name string, gen func() (Params, error)) (Params, error) {
+
+	// Read the parameters from the cache.
+	path := filepath.Join(cache.dir, name+".param")
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		return ParamsFromBytes(data)
+	}
+	if !os.IsNotExist(err) {
+		return Params{}, err
+	}
+
+	// Generate the parameters.
+	params, err := gen()
+	if err != nil {
+		return Params{}, err
+	}
+
+	// Store the parameters.
+	data, err = params.ToBytes()
+	if err == nil {
+		err = cache.write(name, path, data)
+	}
+	if err != nil {
+		params.Free()
+		return Params{}, err
+	}
+
+	// Return the parameters.
+	return params, nil
+
+}
+
+// Write a parameter file atomically, so that concurrent processes never read a
+// partial file.
+func (cache ParamCache) write(name string, path string, data []byte) error {
+	tmp, err := ioutil.TempFile(cache.dir, name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
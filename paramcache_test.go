@@ -0,0 +1,60 @@
+/**
+ * File        : paramcache_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the disk cache of pairing parameters.
+ */
+
+package bls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParamCache(test *testing.T) {
+
+	// Open a cache in a new directory.
+	cache, err := OpenParamCache(filepath.Join(test.TempDir(), "params"))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Generate parameters and store them.
+	first, err := cache.GenParamsTypeA(160, 512)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = os.Stat(filepath.Join(cache.dir, "a-160-512.param"))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Load the same parameters from the cache.
+	second, err := cache.GenParamsTypeA(160, 512)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if first.String() != second.String() {
+		test.Fatal("Cached parameters do not match.")
+	}
+
+	// Generate other parameters for other inputs.
+	third, err := cache.GenParamsTypeF(160)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if first.String() == third.String() {
+		test.Fatal("Parameters of different inputs share a cache entry.")
+	}
+
+	// Clean up.
+	first.Free()
+	second.Free()
+	third.Free()
+
+}
@@ -0,0 +1,109 @@
+/**
+ * File        : paramsearch.go
+ * Description : Cancellable search for type D pairing parameters.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module searches for type D pairing parameters over successive
+ * discriminants, rather than the single discriminant of GenParamsTypeD, with
+ * support for cancellation and progress reports. PBC cannot interrupt the
+ * search of a discriminant, so that search runs in its own goroutine, which a
+ * cancelled caller abandons and which releases its result when it finishes.
+ */
+
+package bls
+
+/*
+#include <stdlib.h>
+#include <pbc/pbc.h>
+
+int search(pbc_param_ptr params, unsigned int d, unsigned int bitlimit);
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"math"
+	"unsafe"
+)
+
+// Search for type D pairing parameters, starting at the discriminant d and
+// moving on to the next discriminant that is 0 or 3 mod 4 whenever there is no
+// suitable curve. The search stops with the error of the context once the
+// context is done. If progress is not nil, it is called with the number of
+// discriminants tried and the last discriminant after each unsuccessful
+// discriminant. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+func SearchParamsTypeD(ctx context.Context, d uint, bitlimit uint, progress func(tried int, d uint)) (Params, error) {
+	for tried := 1; ; tried++ {
+
+		// Skip to the next valid discriminant.
+		for d == 0 || d%4 == 1 || d%4 == 2 {
+			d++
+		}
+		if !validDiscriminant(d) || uint64(bitlimit) > math.MaxUint32 {
+			return Params{}, errors.New("bls.SearchParamsTypeD: No suitable curves.")
+		}
+
+		// Search the discriminant.
+		params, found, err := searchDiscriminant(ctx, d, bitlimit)
+		if err != nil {
+			return Params{}, err
+		}
+		if found {
+			return params, nil
+		}
+
+		// Report the progress.
+		if progress != nil {
+			progress(tried, d)
+		}
+		d++
+
+	}
+}
+
+// Search a single discriminant for type D pairing parameters, abandoning the
+// search once the context is done.
+func searchDiscriminant(ctx context.Context, d uint, bitlimit uint) (Params, bool, error) {
+
+	// Check the context.
+	err := ctx.Err()
+	if err != nil {
+		return Params{}, false, err
+	}
+
+	// Search the discriminant in the background.
+	type result struct {
+		params *C.struct_pbc_param_s
+		found  bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		params := (*C.struct_pbc_param_s)(C.malloc(sizeOfParams))
+		found := C.search(params, C.uint(d), C.uint(bitlimit)) != 0
+		if !found {
+			C.free(unsafe.Pointer(params))
+			params = nil
+		}
+		done <- result{params, found}
+	}()
+
+	// Wait for the search or the context.
+	select {
+	case r := <-done:
+		return Params{r.params}, r.found, nil
+	case <-ctx.Done():
+		go func() {
+			r := <-done
+			if r.found {
+				Params{r.params}.Free()
+			}
+		}()
+		return Params{}, false, ctx.Err()
+	}
+
+}
@@ -0,0 +1,51 @@
+/**
+ * File        : paramsearch_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the search for type D pairing
+ * parameters.
+ */
+
+package bls
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchParamsTypeD(test *testing.T) {
+
+	// Search from a discriminant below one with suitable curves.
+	reported := 0
+	params, err := SearchParamsTypeD(context.Background(), 9560, 512, func(tried int, d uint) {
+		reported++
+		if tried != reported || d > 9563 || d%4 == 1 || d%4 == 2 {
+			test.Errorf("Unexpected progress report %d for discriminant %d.", tried, d)
+		}
+	})
+	if err != nil {
+		test.Fatal(err)
+	}
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Stop the search once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SearchParamsTypeD(ctx, 9560, 512, nil)
+	if err != context.Canceled {
+		test.Fatal("Failed to stop the search.")
+	}
+
+	// Clean up.
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,260 @@
+/**
+ * File        : pedersen.go
+ * Description : Pedersen verifiable secret sharing.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements the secret sharing scheme of Pedersen, in which the
+ * dealer commits to each coefficient a_j of its secret polynomial as
+ * g^{a_j} h^{b_j}, where b_j is a coefficient of a second, blinding polynomial
+ * and h is a generator whose discrete logarithm is unknown. Unlike the
+ * commitments g^{a_j} of Feldman's scheme, these reveal nothing about the
+ * secret, which distributed key generation protocols rely on to prevent a
+ * party from biasing the group key.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"unsafe"
+)
+
+// Derive the second generator h of G2 by hashing the system parameter, so that
+// nobody knows its discrete logarithm to the base g. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func (system System) pedersenBase() *C.struct_element_s {
+	hash := sha256.Sum256(append([]byte("go-bls pedersen"), PublicKey{system, system.g}.ToBytes()...))
+	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(h, system.pairing.get)
+	C.element_from_hash(h, unsafe.Pointer(&hash[0]), sha256.Size)
+	return h
+}
+
+// Deal shares of a random secret as in Pedersen's scheme. The dealer publishes
+// the commitments g^{a_j} h^{b_j}, and gives member i the private share f(i + 1)
+// and the blinding share f'(i + 1). The Feldman commitments g^{a_j} are also
+// returned, to be revealed later if a protocol requires it. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func DealPedersen(t int, n int, system System) ([]PublicKey, []PublicKey, []PrivateKey, []PrivateKey, error) {
+
+	// Deal the secret.
+	feldman, shares, err := Deal(t, n, system)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Generate the blinding polynomial.
+	blinding := make([]PrivateKey, t)
+	for j := range blinding {
+		key, secret, err := GenKeys(system)
+		if err != nil {
+			for k := 0; k < j; k++ {
+				blinding[k].Zeroize()
+			}
+			for k := range feldman {
+				feldman[k].Free()
+			}
+			for i := range shares {
+				shares[i].Zeroize()
+			}
+			return nil, nil, nil, nil, err
+		}
+		key.Free()
+		blinding[j] = secret
+	}
+
+	// Commit to the coefficients.
+	h := system.pedersenBase()
+	commitments := make([]PublicKey, t)
+	for j := range commitments {
+		c := (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G2(c, system.pairing.get)
+		err := powSecret(c, h, blinding[j].x.get)
+		if err != nil {
+			C.element_clear(c)
+			for k := 0; k < j; k++ {
+				commitments[k].Free()
+			}
+			C.element_clear(h)
+			for k := range blinding {
+				blinding[k].Zeroize()
+			}
+			for k := range feldman {
+				feldman[k].Free()
+			}
+			for i := range shares {
+				shares[i].Zeroize()
+			}
+			return nil, nil, nil, nil, err
+		}
+		C.element_mul(c, c, feldman[j].gx.get)
+		commitments[j] = PublicKey{system, Element{c}}
+	}
+
+	// Evaluate the blinding polynomial at the member points.
+	blindingShares := evalPolynomial(blinding, n)
+
+	// Clean up.
+	C.element_clear(h)
+	for j := range blinding {
+		blinding[j].Zeroize()
+	}
+
+	// Return the commitments and the shares.
+	return commitments, feldman, shares, blindingShares, nil
+
+}
+
+// Verify the private key share and the blinding share of a group member
+// against the Pedersen commitments of the dealer.
+func VerifyPedersenShare(commitments []PublicKey, memberId int, share PrivateKey, blinding PrivateKey) (bool, error) {
+
+	// Check the arguments.
+	if len(commitments) == 0 {
+		return false, errors.New("bls.VerifyPedersenShare: Empty list.")
+	}
+	if memberId < 0 {
+		return false, errors.New("bls.VerifyPedersenShare: Member identifier out of range.")
+	}
+	system := commitments[0].system
+
+	// Calculate g^s h^{s'}.
+	h := system.pedersenBase()
+	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(lhs, system.pairing.get)
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(t, system.pairing.get)
+	err := powSecret(lhs, system.g.get, share.x.get)
+	if err == nil {
+		err = powSecret(t, h, blinding.x.get)
+	}
+	C.element_mul(lhs, lhs, t)
+	C.element_clear(t)
+	if err != nil {
+		C.element_clear(lhs)
+		C.element_clear(h)
+		return false, err
+	}
+
+	// Evaluate the commitments at the member point.
+	exps := make([]*big.Int, len(commitments))
+	x := big.NewInt(int64(memberId + 1))
+	p := big.NewInt(1)
+	for j := range exps {
+		exps[j] = big.NewInt(0).Set(p)
+		p.Mul(p, x)
+	}
+	rhs := evalCommitments(commitments, exps)
+
+	// Compare.
+	valid := C.element_cmp(lhs, rhs) == 0
+
+	// Clean up.
+	C.element_clear(rhs)
+	C.element_clear(lhs)
+	C.element_clear(h)
+
+	// Return the result.
+	return valid, nil
+
+}
+
+// Recover the coefficients of a polynomial of degree t-1 from its values at the
+// points of t group members by Lagrange interpolation. A protocol uses this to
+// reconstruct the polynomial of a dealer that has been exposed. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func InterpolateCoefficients(secrets []PrivateKey, memberIds []int, system System) ([]PrivateKey, error) {
+
+	// Check the list length.
+	if len(secrets) == 0 {
+		return nil, errors.New("bls.InterpolateCoefficients: Empty list.")
+	}
+	if len(secrets) != len(memberIds) {
+		return nil, errors.New("bls.InterpolateCoefficients: List length mismatch.")
+	}
+
+	// Read the values.
+	r := system.order()
+	t := len(secrets)
+	ys := make([]*big.Int, t)
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	for i := range secrets {
+		C.element_to_mpz(&z[0], secrets[i].x.get)
+		ys[i] = getMpz(&z[0])
+	}
+
+	// Sum the Lagrange basis polynomials weighted by the values.
+	coeffs := make([]*big.Int, t)
+	for k := range coeffs {
+		coeffs[k] = big.NewInt(0)
+	}
+	for i := range memberIds {
+		basis := []*big.Int{big.NewInt(1)}
+		d := big.NewInt(1)
+		xi := big.NewInt(int64(memberIds[i] + 1))
+		for j := range memberIds {
+			if i == j {
+				continue
+			}
+			if memberIds[i] == memberIds[j] {
+				C.mpz_clear(&z[0])
+				return nil, errors.New("bls.InterpolateCoefficients: Member identifiers must be distinct.")
+			}
+			xj := big.NewInt(int64(memberIds[j] + 1))
+			next := make([]*big.Int, len(basis)+1)
+			next[len(basis)] = big.NewInt(0).Set(basis[len(basis)-1])
+			for k := len(basis) - 1; k >= 0; k-- {
+				next[k] = big.NewInt(0).Mul(basis[k], xj)
+				next[k].Neg(next[k])
+				if k > 0 {
+					next[k].Add(next[k], basis[k-1])
+				}
+				next[k].Mod(next[k], r)
+			}
+			basis = next
+			d.Mul(d, big.NewInt(0).Sub(xi, xj))
+			d.Mod(d, r)
+		}
+		if d.ModInverse(d, r) == nil {
+			C.mpz_clear(&z[0])
+			return nil, errors.New("bls.InterpolateCoefficients: Member identifiers are not invertible.")
+		}
+		d.Mul(d, ys[i])
+		for k := range basis {
+			coeffs[k].Add(coeffs[k], big.NewInt(0).Mul(basis[k], d))
+			coeffs[k].Mod(coeffs[k], r)
+		}
+	}
+
+	// Convert the coefficients to private keys.
+	result := make([]PrivateKey, t)
+	for k := range result {
+		setMpz(&z[0], coeffs[k])
+		x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+		initSecret(x, system)
+		C.element_set_mpz(x, &z[0])
+		result[k] = PrivateKey{system, Element{x}}
+	}
+
+	// Clean up.
+	C.mpz_clear(&z[0])
+
+	// Return the coefficients.
+	return result, nil
+
+}
@@ -0,0 +1,89 @@
+/**
+ * File        : pedersen_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for Pedersen verifiable secret sharing.
+ */
+
+package bls
+
+import (
+	"testing"
+)
+
+func TestPedersen(test *testing.T) {
+
+	t := 3
+	n := 5
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Deal the shares.
+	commitments, feldman, shares, blinding, err := DealPedersen(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the shares.
+	for i := 0; i < n; i++ {
+		valid, err := VerifyPedersenShare(commitments, i, shares[i], blinding[i])
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !valid {
+			test.Fatal("Failed to verify share.")
+		}
+	}
+	valid, err := VerifyPedersenShare(commitments, 0, shares[1], blinding[1])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified the share of another member.")
+	}
+	valid, err = VerifySecretShares(feldman, identityIds(n), shares)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Shares do not match the Feldman commitments.")
+	}
+
+	// Reconstruct the polynomial from any t shares.
+	memberIds := []int{4, 0, 2}
+	coeffs, err := InterpolateCoefficients([]PrivateKey{shares[4], shares[0], shares[2]}, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for j := range coeffs {
+		key := coeffs[j].Public()
+		if !key.Equal(feldman[j]) {
+			test.Fatal("Reconstructed coefficient does not match its commitment.")
+		}
+		key.Free()
+	}
+
+	// Clean up.
+	for j := 0; j < t; j++ {
+		coeffs[j].Free()
+		commitments[j].Free()
+		feldman[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		shares[i].Free()
+		blinding[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
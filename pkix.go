@@ -0,0 +1,212 @@
+/**
+ * File        : pkix.go
+ * Description : ASN.1 and PEM encodings.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module encodes keys in the SubjectPublicKeyInfo and PKCS #8 structures
+ * used by X.509 certificates, and wraps them in PEM blocks. The algorithm
+ * parameters carry the fingerprint of the cryptosystem, so that a key cannot
+ * be parsed into a system it does not belong to.
+ *
+ * Since the x509 package cannot issue certificates for BLS subject keys, a
+ * key can also be carried in a certificate extension alongside a conventional
+ * subject key.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+)
+
+// The object identifier of the algorithm. There is no registered identifier
+// for BLS signatures over PBC curves, so this one is taken from an experimental
+// private arc and may change.
+var OIDPublicKeyBLS = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55555, 1, 1}
+
+// The object identifier of the certificate extension carrying a public key.
+// Like OIDPublicKeyBLS, it is unregistered and may change.
+var OIDExtensionBLSKey = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55555, 1, 2}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters []byte
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm algorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type pkcs8PrivateKey struct {
+	Version    int
+	Algorithm  algorithmIdentifier
+	PrivateKey []byte
+}
+
+// Encode a public key as a DER SubjectPublicKeyInfo structure.
+func MarshalPKIXPublicKey(key PublicKey) ([]byte, error) {
+	bytes := key.ToBytes()
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: key.system.algorithmIdentifier(),
+		PublicKey: asn1.BitString{Bytes: bytes, BitLength: 8 * len(bytes)},
+	})
+}
+
+// Decode a public key from a DER SubjectPublicKeyInfo structure.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func ParsePKIXPublicKey(system System, der []byte) (PublicKey, error) {
+	var info subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if len(rest) != 0 {
+		return PublicKey{}, errors.New("bls.ParsePKIXPublicKey: Trailing data.")
+	}
+	err = system.checkAlgorithm(info.Algorithm)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if info.PublicKey.BitLength%8 != 0 {
+		return PublicKey{}, errors.New("bls.ParsePKIXPublicKey: Invalid bit string.")
+	}
+	return PublicKeyFromBytes(system, info.PublicKey.Bytes)
+}
+
+// Build a certificate extension carrying the public key as a DER
+// SubjectPublicKeyInfo structure. The extension can be added to the
+// ExtraExtensions of a certificate template.
+func PublicKeyExtension(key PublicKey) (pkix.Extension, error) {
+	der, err := MarshalPKIXPublicKey(key)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: OIDExtensionBLSKey, Value: der}, nil
+}
+
+// Extract a public key from a certificate. The key is taken from the subject
+// public key of the certificate if it is a BLS key, and from the extension
+// built by PublicKeyExtension otherwise.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PublicKeyFromCertificate(system System, cert *x509.Certificate) (PublicKey, error) {
+	var info subjectPublicKeyInfo
+	_, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &info)
+	if err == nil && info.Algorithm.Algorithm.Equal(OIDPublicKeyBLS) {
+		return ParsePKIXPublicKey(system, cert.RawSubjectPublicKeyInfo)
+	}
+	for _, extension := range cert.Extensions {
+		if extension.Id.Equal(OIDExtensionBLSKey) {
+			return ParsePKIXPublicKey(system, extension.Value)
+		}
+	}
+	return PublicKey{}, errors.New("bls.PublicKeyFromCertificate: No BLS public key.")
+}
+
+// Encode a private key as a DER PKCS #8 structure.
+func MarshalPKCS8PrivateKey(secret PrivateKey) ([]byte, error) {
+	return asn1.Marshal(pkcs8PrivateKey{
+		Algorithm:  secret.system.algorithmIdentifier(),
+		PrivateKey: secret.ToBytes(),
+	})
+}
+
+// Decode a private key from a DER PKCS #8 structure.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func ParsePKCS8PrivateKey(system System, der []byte) (PrivateKey, error) {
+	var info pkcs8PrivateKey
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	if len(rest) != 0 {
+		return PrivateKey{}, errors.New("bls.ParsePKCS8PrivateKey: Trailing data.")
+	}
+	if info.Version != 0 {
+		return PrivateKey{}, errors.New("bls.ParsePKCS8PrivateKey: Unknown version.")
+	}
+	err = system.checkAlgorithm(info.Algorithm)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	return PrivateKeyFromBytes(system, info.PrivateKey)
+}
+
+// Encode a public key as a PEM block of type PUBLIC KEY.
+func EncodePublicKeyPEM(key PublicKey) ([]byte, error) {
+	der, err := MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Decode a public key from a PEM block of type PUBLIC KEY.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func DecodePublicKeyPEM(system System, data []byte) (PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return PublicKey{}, errors.New("bls.DecodePublicKeyPEM: No public key block.")
+	}
+	return ParsePKIXPublicKey(system, block.Bytes)
+}
+
+// Encode a private key as a PEM block of type PRIVATE KEY.
+func EncodePrivateKeyPEM(secret PrivateKey) ([]byte, error) {
+	der, err := MarshalPKCS8PrivateKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Decode a private key from a PEM block of type PRIVATE KEY.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func DecodePrivateKeyPEM(system System, data []byte) (PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return PrivateKey{}, errors.New("bls.DecodePrivateKeyPEM: No private key block.")
+	}
+	return ParsePKCS8PrivateKey(system, block.Bytes)
+}
+
+// Build the algorithm identifier of the cryptosystem. The parameters are the
+// fingerprint of the system encoded as an octet string.
+func (system System) algorithmIdentifier() algorithmIdentifier {
+	fingerprint := system.Fingerprint()
+	return algorithmIdentifier{OIDPublicKeyBLS, fingerprint[:]}
+}
+
+// Check that the algorithm identifier matches the cryptosystem.
+func (system System) checkAlgorithm(algorithm algorithmIdentifier) error {
+	if !algorithm.Algorithm.Equal(OIDPublicKeyBLS) {
+		return errors.New("bls.FromDER: Unknown algorithm.")
+	}
+	fingerprint := system.Fingerprint()
+	if len(algorithm.Parameters) != sha256.Size || string(algorithm.Parameters) != string(fingerprint[:]) {
+		return errors.New("bls.FromDER: Key belongs to another system.")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/**
+ * File        : pkix_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for ASN.1 and PEM encodings.
+ */
+
+package bls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestPEM(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Round-trip the key pair through PEM.
+	data, err := EncodePublicKeyPEM(key)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keyIn, err := DecodePublicKeyPEM(system, data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	data, err = EncodePrivateKeyPEM(secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	secretIn, err := DecodePrivateKeyPEM(system, data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secretIn)
+	if !Verify(signature, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// A key of another system must be rejected.
+	other, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	der, err := MarshalPKIXPublicKey(key)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = ParsePKIXPublicKey(other, der)
+	if err == nil {
+		test.Fatal("Parsed a key of another system.")
+	}
+	_, err = DecodePublicKeyPEM(system, []byte("not a pem block"))
+	if err == nil {
+		test.Fatal("Decoded an invalid PEM block.")
+	}
+
+	// Clean up.
+	other.Free()
+	signature.Free()
+	secretIn.Free()
+	keyIn.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestCertificate(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Issue a self-signed certificate carrying the key in an extension.
+	extension, err := PublicKeyExtension(key)
+	if err != nil {
+		test.Fatal(err)
+	}
+	issuer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		test.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "go-bls"},
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{extension},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &issuer.PublicKey, issuer)
+	if err != nil {
+		test.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Extract the key.
+	keyIn, err := PublicKeyFromCertificate(system, cert)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if keyIn.Hex() != key.Hex() {
+		test.Fatal("Public keys do not match.")
+	}
+
+	// Clean up.
+	keyIn.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
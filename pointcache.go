@@ -0,0 +1,110 @@
+/**
+ * File        : pointcache.go
+ * Description : Cache of hashed message points.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module caches the points in G1 of recently verified message digests.
+ * In threshold settings every share of a signature is verified against the
+ * same digest, and the cache maps the digest to G1 once rather than once per
+ * share. The cache is optional, holds a bounded number of points, and evicts
+ * the least recently used point when it is full.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"container/list"
+	"sync"
+	"unsafe"
+)
+
+type pointCache struct {
+	sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type pointEntry struct {
+	digest string
+	point  *C.struct_element_s
+}
+
+// Enable a cache of the points in G1 of up to size message digests, which
+// Verify and the functions built on it consult before hashing a digest to G1.
+// A size of zero disables the cache. The cache is shared by the copies of the
+// returned system and is released by Free.
+func (system System) WithPointCache(size int) System {
+	if size <= 0 {
+		system.points = nil
+		return system
+	}
+	system.points = &pointCache{size: size, entries: make(map[string]*list.Element), order: list.New()}
+	return system
+}
+
+// Set h to the point in G1 of the nonempty digest, consulting the cache of the
+// cryptosystem if it has one.
+func (system System) hashToPoint(h *C.struct_element_s, digest []byte) {
+
+	// Hash the digest if there is no cache.
+	cache := system.points
+	if cache == nil {
+		C.element_from_hash(h, unsafe.Pointer(&digest[0]), C.int(len(digest)))
+		return
+	}
+
+	// Look up the digest.
+	cache.Lock()
+	if entry, ok := cache.entries[string(digest)]; ok {
+		cache.order.MoveToFront(entry)
+		C.element_set(h, entry.Value.(*pointEntry).point)
+		cache.Unlock()
+		return
+	}
+	cache.Unlock()
+
+	// Hash the digest without holding the lock, so that concurrent lookups of
+	// other digests are not blocked.
+	C.element_from_hash(h, unsafe.Pointer(&digest[0]), C.int(len(digest)))
+
+	// Insert the point unless another goroutine has inserted it meanwhile,
+	// evicting the least recently used point if the cache is full.
+	cache.Lock()
+	defer cache.Unlock()
+	if entry, ok := cache.entries[string(digest)]; ok {
+		cache.order.MoveToFront(entry)
+		return
+	}
+	var point *C.struct_element_s
+	if cache.order.Len() == cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*pointEntry).digest)
+		point = oldest.Value.(*pointEntry).point
+	} else {
+		point = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_same_as(point, h)
+	}
+	C.element_set(point, h)
+	cache.entries[string(digest)] = cache.order.PushFront(&pointEntry{string(digest), point})
+
+}
+
+// Release the points held by the cache.
+func (cache *pointCache) free() {
+	cache.Lock()
+	defer cache.Unlock()
+	for entry := cache.order.Front(); entry != nil; entry = entry.Next() {
+		C.element_clear(entry.Value.(*pointEntry).point)
+	}
+	cache.entries = make(map[string]*list.Element)
+	cache.order.Init()
+}
@@ -0,0 +1,74 @@
+/**
+ * File        : pointcache_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the cache of hashed message points.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestPointCache(test *testing.T) {
+
+	t := 2
+	n := 3
+	messages := 3
+
+	// Generate the key shares in a system with a cache of two points.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	system = system.WithPointCache(2)
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Verify the shares of several messages twice, so that points are both
+	// found in the cache and evicted from it.
+	for round := 0; round < 2; round++ {
+		for j := 0; j < messages; j++ {
+			hash := sha256.Sum256([]byte(fmt.Sprintf("This is message %d.", j)))
+			other := sha256.Sum256([]byte(fmt.Sprintf("This is message %d.", j+1)))
+			for i := 0; i < n; i++ {
+				share := Sign(hash, memberSecrets[i])
+				if !VerifyShare(share, hash, memberKeys[i]) {
+					test.Fatal("Failed to verify signature share.")
+				}
+				if VerifyShare(share, other, memberKeys[i]) {
+					test.Fatal("Accepted a signature share on another message.")
+				}
+				share.Free()
+			}
+		}
+	}
+	if system.points.order.Len() != 2 || len(system.points.entries) != 2 {
+		test.Fatal("Cache exceeds its size.")
+	}
+
+	// Clean up.
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
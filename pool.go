@@ -0,0 +1,99 @@
+/**
+ * File        : pool.go
+ * Description : Element pool.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module keeps the scratch elements of Sign and Verify for reuse, so that
+ * hot loops do not allocate and initialize several C elements per call. The
+ * elements are kept per pairing and group, and are released when the pairing
+ * is freed. A sync.Pool is not used, since it drops its items without notice,
+ * which would leak their C memory.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"sync"
+)
+
+// The groups of the pooled elements.
+const (
+	poolG1 = iota
+	poolGT
+)
+
+// The maximum number of idle elements kept per pairing and group.
+const maxPooledElements = 64
+
+type poolKey struct {
+	pairing *C.struct_pairing_s
+	group   int
+}
+
+var elementPool struct {
+	sync.Mutex
+	idle map[poolKey][]*C.struct_element_s
+}
+
+// Take an element of the group from the pool, or allocate one if the pool is
+// empty. The value of the element is undefined.
+func getElement(pairing Pairing, group int) *C.struct_element_s {
+
+	// Reuse an idle element.
+	key := poolKey{pairing.get, group}
+	elementPool.Lock()
+	idle := elementPool.idle[key]
+	if len(idle) > 0 {
+		element := idle[len(idle)-1]
+		elementPool.idle[key] = idle[:len(idle)-1]
+		elementPool.Unlock()
+		return element
+	}
+	elementPool.Unlock()
+
+	// Allocate an element.
+	element := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	switch group {
+	case poolG1:
+		C.element_init_G1(element, pairing.get)
+	case poolGT:
+		C.element_init_GT(element, pairing.get)
+	}
+	return element
+
+}
+
+// Return an element taken with getElement to the pool.
+func putElement(pairing Pairing, group int, element *C.struct_element_s) {
+	key := poolKey{pairing.get, group}
+	elementPool.Lock()
+	defer elementPool.Unlock()
+	if len(elementPool.idle[key]) == maxPooledElements {
+		C.element_clear(element)
+		return
+	}
+	if elementPool.idle == nil {
+		elementPool.idle = make(map[poolKey][]*C.struct_element_s)
+	}
+	elementPool.idle[key] = append(elementPool.idle[key], element)
+}
+
+// Release the idle elements of a pairing.
+func drainElements(pairing Pairing) {
+	elementPool.Lock()
+	defer elementPool.Unlock()
+	for _, group := range []int{poolG1, poolGT} {
+		key := poolKey{pairing.get, group}
+		for _, element := range elementPool.idle[key] {
+			C.element_clear(element)
+		}
+		delete(elementPool.idle, key)
+	}
+}
@@ -0,0 +1,74 @@
+/**
+ * File        : pool_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the element pool.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"sync"
+	"testing"
+)
+
+func TestElementPool(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify concurrently.
+	hash := sha256.Sum256([]byte(message))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 8; j++ {
+				signature := Sign(hash, secret)
+				if !Verify(signature, hash, key) {
+					test.Error("Failed to verify signature.")
+				}
+				signature.Free()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The pool holds the idle elements of the pairing until it is freed.
+	elementPool.Lock()
+	idle := len(elementPool.idle[poolKey{pairing.get, poolG1}])
+	elementPool.Unlock()
+	if idle == 0 || idle > maxPooledElements {
+		test.Fatal("Unexpected number of idle elements:", idle)
+	}
+
+	// Clean up.
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+	elementPool.Lock()
+	idle = len(elementPool.idle[poolKey{pairing.get, poolG1}])
+	elementPool.Unlock()
+	if idle != 0 {
+		test.Fatal("Idle elements were not released.")
+	}
+
+}
@@ -0,0 +1,230 @@
+/**
+ * File        : robust.go
+ * Description : Robust threshold signatures.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module recovers a threshold signature in the presence of invalid
+ * signature shares and identifies the members that provided them. The shares
+ * are first combined optimistically. Only if the result fails to verify are
+ * they checked, by bisecting the set with randomized batch verification, so
+ * that a few bad shares among many cost few pairings. The batch verification
+ * is also available on its own, for combiners that check shares before
+ * recovery.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// Recover a threshold signature on the message digest from the signature shares
+// provided by the group members, identifying invalid shares. The public key
+// share of the member that provided each share is at the same index. If the
+// combined signature does not verify under the group key, the invalid shares
+// are located and excluded, and the identifiers of their members are returned
+// along with the signature recovered from the remaining shares. An error is
+// returned if too few valid shares remain. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the caller
+// to prevent memory leaks by arranging for the C structures to be freed.
+func ThresholdRobust(shares []Signature, memberIds []int, memberKeys []PublicKey, hash [sha256.Size]byte, groupKey PublicKey) (Signature, []int, error) {
+
+	// Check the list length.
+	if len(shares) == 0 {
+		return Element{}, nil, errors.New("bls.ThresholdRobust: Empty list.")
+	}
+	if len(shares) != len(memberIds) || len(shares) != len(memberKeys) {
+		return Element{}, nil, errors.New("bls.ThresholdRobust: List length mismatch.")
+	}
+	system := groupKey.system
+
+	// Try the optimistic path.
+	signature, err := Threshold(shares, memberIds, system)
+	if err != nil {
+		return Element{}, nil, err
+	}
+	if Verify(signature, hash, groupKey) {
+		return signature, nil, nil
+	}
+	signature.Free()
+
+	// Locate the invalid shares.
+	indices := make([]int, len(shares))
+	for i := range indices {
+		indices[i] = i
+	}
+	invalid, err := findInvalidShares(shares, memberKeys, hash, indices)
+	if err != nil {
+		return Element{}, nil, err
+	}
+	bad := make([]int, len(invalid))
+	excluded := make(map[int]bool)
+	for k, i := range invalid {
+		bad[k] = memberIds[i]
+		excluded[i] = true
+	}
+
+	// Recover the threshold signature from the valid shares.
+	var validShares []Signature
+	var validIds []int
+	for i := range shares {
+		if !excluded[i] {
+			validShares = append(validShares, shares[i])
+			validIds = append(validIds, memberIds[i])
+		}
+	}
+	if len(validShares) == 0 {
+		return Element{}, bad, errors.New("bls.ThresholdRobust: Too few valid shares.")
+	}
+	signature, err = Threshold(validShares, validIds, system)
+	if err != nil {
+		return Element{}, bad, err
+	}
+	if !Verify(signature, hash, groupKey) {
+		signature.Free()
+		return Element{}, bad, errors.New("bls.ThresholdRobust: Too few valid shares.")
+	}
+
+	// Return the threshold signature and the invalid members.
+	return signature, bad, nil
+
+}
+
+// Verify signature shares on the message digest, where the public key share of
+// the member that provided each share is at the same index. The shares are
+// checked at once with a random linear combination, which costs two pairings
+// regardless of their number. Only if the combination fails is each share
+// checked individually. The result reports the validity of each share.
+func BatchVerifyShares(shares []Signature, hash [sha256.Size]byte, memberKeys []PublicKey) ([]bool, error) {
+
+	// Check the list length.
+	if len(shares) == 0 {
+		return nil, errors.New("bls.BatchVerifyShares: Empty list.")
+	}
+	if len(shares) != len(memberKeys) {
+		return nil, errors.New("bls.BatchVerifyShares: List length mismatch.")
+	}
+
+	// Verify the shares at once.
+	indices := make([]int, len(shares))
+	for i := range indices {
+		indices[i] = i
+	}
+	valid, err := batchVerifyShares(shares, memberKeys, hash, indices)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]bool, len(shares))
+	for i := range result {
+		result[i] = valid
+	}
+
+	// Fall back to verifying the shares one at a time.
+	if !valid {
+		for i := range shares {
+			result[i] = VerifyShare(shares[i], hash, memberKeys[i])
+		}
+	}
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Find the invalid shares among those at the given indices. A set that passes
+// batch verification is valid with overwhelming probability. A set that fails
+// is split in half and each half is searched.
+func findInvalidShares(shares []Signature, keys []PublicKey, hash [sha256.Size]byte, indices []int) ([]int, error) {
+	if len(indices) == 1 {
+		if Verify(shares[indices[0]], hash, keys[indices[0]]) {
+			return nil, nil
+		}
+		return indices, nil
+	}
+	valid, err := batchVerifyShares(shares, keys, hash, indices)
+	if err != nil {
+		return nil, err
+	}
+	if valid {
+		return nil, nil
+	}
+	left, err := findInvalidShares(shares, keys, hash, indices[:len(indices)/2])
+	if err != nil {
+		return nil, err
+	}
+	right, err := findInvalidShares(shares, keys, hash, indices[len(indices)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// Verify the shares at the given indices at once by checking a random linear
+// combination of them against the same combination of the public key shares.
+// The random weights prevent invalid shares from cancelling each other out.
+func batchVerifyShares(shares []Signature, keys []PublicKey, hash [sha256.Size]byte, indices []int) (bool, error) {
+
+	// Signatures without the sign of the y-coordinate cannot be combined, so
+	// check them one at a time.
+	system := keys[indices[0]].system
+	if system.encoding.Format == PointXOnly {
+		for _, i := range indices {
+			if !Verify(shares[i], hash, keys[i]) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	// Combine the shares and the keys.
+	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(sigma, system.pairing.get)
+	C.element_set1(sigma)
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	C.element_set1(gx)
+	s := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(s, system.pairing.get)
+	k := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(k, system.pairing.get)
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	bound := big.NewInt(0).Lsh(big.NewInt(1), batchWeightBits)
+	var err error
+	for _, i := range indices {
+		var weight *big.Int
+		weight, err = rand.Int(rand.Reader, bound)
+		if err != nil {
+			break
+		}
+		setMpz(&z[0], weight)
+		C.element_pow_mpz(s, shares[i].get, &z[0])
+		C.element_mul(sigma, sigma, s)
+		C.element_pow_mpz(k, keys[i].gx.get, &z[0])
+		C.element_mul(gx, gx, k)
+	}
+
+	// Verify the combination.
+	result := err == nil && Verify(Element{sigma}, hash, PublicKey{system, Element{gx}})
+
+	// Clean up.
+	C.mpz_clear(&z[0])
+	C.element_clear(k)
+	C.element_clear(s)
+	C.element_clear(gx)
+	C.element_clear(sigma)
+
+	// Return the result.
+	return result, err
+
+}
@@ -0,0 +1,116 @@
+/**
+ * File        : robust_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for robust threshold signatures.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"testing"
+)
+
+func TestThresholdRobust(test *testing.T) {
+
+	t := 3
+	n := 7
+	message := "This is a message."
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message with every member. Members 2 and 5 sign another
+	// message.
+	hash := sha256.Sum256([]byte(message))
+	other := sha256.Sum256([]byte("This is another message."))
+	memberIds := identityIds(n)
+	shares := make([]Signature, n)
+	for i := range shares {
+		if i == 2 || i == 5 {
+			shares[i] = Sign(other, memberSecrets[i])
+		} else {
+			shares[i] = Sign(hash, memberSecrets[i])
+		}
+	}
+
+	// Batch verification reports the invalid shares.
+	valid, err := BatchVerifyShares(shares, hash, memberKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for i := range valid {
+		if valid[i] != (i != 2 && i != 5) {
+			test.Fatal("Batch verification misreported a share.")
+		}
+	}
+	valid, err = BatchVerifyShares(shares[:2], hash, memberKeys[:2])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid[0] || !valid[1] {
+		test.Fatal("Batch verification rejected valid shares.")
+	}
+
+	// Recover the threshold signature and identify the invalid shares.
+	signature, bad, err := ThresholdRobust(shares, memberIds, memberKeys, hash, groupKey)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !reflect.DeepEqual(bad, []int{2, 5}) {
+		test.Fatal("Failed to identify the invalid shares.")
+	}
+	if !Verify(signature, hash, groupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+	signature.Free()
+
+	// Too few valid shares cannot be recovered, even if none is invalid.
+	_, bad, err = ThresholdRobust(shares[:2], memberIds[:2], memberKeys[:2], hash, groupKey)
+	if err == nil || len(bad) != 0 {
+		test.Fatal("Recovered a signature from too few shares.")
+	}
+
+	// Valid shares need no identification.
+	validIds := []int{0, 3, 6}
+	validShares := []Signature{shares[0], shares[3], shares[6]}
+	validKeys := []PublicKey{memberKeys[0], memberKeys[3], memberKeys[6]}
+	signature, bad, err = ThresholdRobust(validShares, validIds, validKeys, hash, groupKey)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(bad) != 0 {
+		test.Fatal("Reported a valid share as invalid.")
+	}
+	signature.Free()
+
+	// Clean up.
+	for i := range shares {
+		shares[i].Free()
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
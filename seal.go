@@ -0,0 +1,133 @@
+/**
+ * File        : seal.go
+ * Description : Password-encrypted private keys.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module seals private keys under a passphrase, so that keys at rest are
+ * never stored in plaintext. The encryption key is derived with PBKDF2-HMAC-
+ * SHA256, and the private key is encrypted with AES-256-GCM. The header and
+ * the fingerprint of the cryptosystem are authenticated, so a sealed key
+ * cannot be opened into another system or have its parameters altered.
+ */
+
+package bls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// The version of the sealed key format.
+const sealVersion byte = 1
+
+// The iteration count used when sealing private keys.
+const SealIterations = 600000
+
+// The largest iteration count accepted when opening a sealed key, so that a
+// crafted key cannot make OpenPrivateKey run for hours.
+const sealMaxIterations = 10 * SealIterations
+
+// The lengths of the salt and the header of a sealed key.
+const (
+	sealSaltSize   = 16
+	sealHeaderSize = 1 + 4 + sealSaltSize
+)
+
+// Seal the private key under the passphrase. The sealed key consists of a
+// version byte, the iteration count as a 32-bit big-endian integer, the salt,
+// the nonce, and the ciphertext.
+func (secret PrivateKey) Seal(passphrase []byte) ([]byte, error) {
+
+	// Build the header.
+	header := make([]byte, sealHeaderSize)
+	header[0] = sealVersion
+	binary.BigEndian.PutUint32(header[1:5], SealIterations)
+	_, err := rand.Read(header[5:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the private key.
+	aead, err := secret.system.sealCipher(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := secret.ToBytes()
+	defer clear(plaintext)
+	sealed := append(header, nonce...)
+	return aead.Seal(sealed, nonce, plaintext, secret.system.sealData(header)), nil
+
+}
+
+// OpenPrivateKey opens a private key of the cryptosystem sealed under the
+// passphrase.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func OpenPrivateKey(system System, sealed []byte, passphrase []byte) (PrivateKey, error) {
+
+	// Read the header.
+	if len(sealed) < sealHeaderSize {
+		return PrivateKey{}, errors.New("bls.OpenPrivateKey: Sealed key too short.")
+	}
+	header := sealed[:sealHeaderSize]
+	if header[0] != sealVersion {
+		return PrivateKey{}, errors.New("bls.OpenPrivateKey: Unsupported version.")
+	}
+	iterations := binary.BigEndian.Uint32(header[1:5])
+	if iterations == 0 || iterations > sealMaxIterations {
+		return PrivateKey{}, errors.New("bls.OpenPrivateKey: Bad iteration count.")
+	}
+
+	// Decrypt the private key.
+	aead, err := system.sealCipher(passphrase, header)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	rest := sealed[sealHeaderSize:]
+	if len(rest) < aead.NonceSize() {
+		return PrivateKey{}, errors.New("bls.OpenPrivateKey: Sealed key too short.")
+	}
+	bytes, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], system.sealData(header))
+	if err != nil {
+		return PrivateKey{}, errors.New("bls.OpenPrivateKey: Wrong passphrase or corrupted key.")
+	}
+	defer clear(bytes)
+	return PrivateKeyFromBytes(system, bytes)
+
+}
+
+// Derive the cipher from the passphrase and the parameters in the header.
+func (system System) sealCipher(passphrase []byte, header []byte) (cipher.AEAD, error) {
+	iterations := int(binary.BigEndian.Uint32(header[1:5]))
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), header[5:], iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Build the additional authenticated data from the header and the fingerprint
+// of the cryptosystem.
+func (system System) sealData(header []byte) []byte {
+	fingerprint := system.Fingerprint()
+	return append(append([]byte{}, header...), fingerprint[:]...)
+}
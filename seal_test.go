@@ -0,0 +1,110 @@
+/**
+ * File        : seal_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for password-encrypted private keys.
+ */
+
+package bls
+
+import (
+	"testing"
+)
+
+func TestSealOpen(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Seal and open the private key.
+	sealed, err := secret.Seal([]byte("passphrase"))
+	if err != nil {
+		test.Fatal(err)
+	}
+	secretIn, err := OpenPrivateKey(system, sealed, []byte("passphrase"))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if secretIn.Hex() != secret.Hex() {
+		test.Fatal("Private keys do not match.")
+	}
+
+	// A wrong passphrase, a tampered header, an excessive iteration count, and
+	// another system must be rejected.
+	_, err = OpenPrivateKey(system, sealed, []byte("wrong"))
+	if err == nil {
+		test.Fatal("Accepted a wrong passphrase.")
+	}
+	sealed[5] ^= 1
+	_, err = OpenPrivateKey(system, sealed, []byte("passphrase"))
+	if err == nil {
+		test.Fatal("Accepted a tampered header.")
+	}
+	sealed[5] ^= 1
+	crafted := append([]byte{}, sealed...)
+	copy(crafted[1:5], []byte{0xff, 0xff, 0xff, 0xff})
+	_, err = OpenPrivateKey(system, crafted, []byte("passphrase"))
+	if err == nil {
+		test.Fatal("Accepted an excessive iteration count.")
+	}
+	other, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = OpenPrivateKey(other, sealed, []byte("passphrase"))
+	if err == nil {
+		test.Fatal("Opened a key into another system.")
+	}
+
+	// Clean up.
+	other.Free()
+	secretIn.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestZeroize(test *testing.T) {
+
+	// Generate a key share set.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	set, err := GenKeyShareSet(2, 3, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Wiping overwrites the scalar with zero before it is released.
+	wipe(set.MemberSecrets[0].x.get)
+	for _, b := range set.MemberSecrets[0].ToBytes() {
+		if b != 0 {
+			test.Fatal("Private key was not overwritten.")
+		}
+	}
+
+	// Clean up.
+	set.Zeroize()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,276 @@
+/**
+ * File        : shareset.go
+ * Description : Key share sets.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module bundles the complete output of threshold key generation, that
+ * is, the threshold, the group public key, and the key shares of all members,
+ * into a single blob for backup and distribution by a trusted dealer.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// A KeyShareSet holds the threshold configuration of a group. The shares of
+// member i are at index i.
+type KeyShareSet struct {
+	Threshold     int
+	GroupKey      PublicKey
+	MemberKeys    []PublicKey
+	MemberSecrets []PrivateKey
+}
+
+// Generate a key pair from the given cryptosystem and divide it into n shares
+// such that t members can combine signatures to recover a threshold signature.
+// The group private key is discarded. This function allocates C structures on
+// the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func GenKeyShareSet(t int, n int, system System) (KeyShareSet, error) {
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		return KeyShareSet{}, err
+	}
+	groupSecret.Zeroize()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	return KeyShareSet{t, groupKey, memberKeys, memberSecrets}, nil
+}
+
+// Generate a key pair from the given cryptosystem and divide it twice, once
+// into n1 shares with threshold t1 and once into n2 shares with threshold t2,
+// so that either set of members can recover a threshold signature under the
+// same group key, for example a fast path of hot signers and a recovery path of
+// cold signers. The shares of the two sets are independent, so shares from
+// different sets cannot be combined. The group private key is discarded. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func GenDualKeyShareSets(t1 int, n1 int, t2 int, n2 int, system System) (KeyShareSet, KeyShareSet, error) {
+
+	// Generate the key pair.
+	groupKey, groupSecret, err := GenKeys(system)
+	if err != nil {
+		return KeyShareSet{}, KeyShareSet{}, err
+	}
+	defer groupSecret.Zeroize()
+
+	// Divide the key pair into each share set.
+	params := [2][2]int{{t1, n1}, {t2, n2}}
+	var sets [2]KeyShareSet
+	for k := range params {
+		commitments, memberSecrets, err := DealSecret(groupSecret, params[k][0], params[k][1])
+		if err != nil {
+			if k > 0 {
+				sets[0].Zeroize()
+			}
+			groupKey.Free()
+			return KeyShareSet{}, KeyShareSet{}, err
+		}
+		for j := range commitments {
+			commitments[j].Free()
+		}
+		memberKeys := make([]PublicKey, len(memberSecrets))
+		for i := range memberSecrets {
+			memberKeys[i] = memberSecrets[i].Public()
+		}
+		sets[k] = KeyShareSet{params[k][0], groupKey.copy(), memberKeys, memberSecrets}
+	}
+
+	// Clean up.
+	groupKey.Free()
+
+	// Return the share sets.
+	return sets[0], sets[1], nil
+
+}
+
+// Export the key share set to a byte slice.
+func (set KeyShareSet) ToBytes() []byte {
+	var buf bytes.Buffer
+	fingerprint := set.GroupKey.system.Fingerprint()
+	buf.Write(fingerprint[:])
+	binary.Write(&buf, binary.BigEndian, [2]uint32{uint32(set.Threshold), uint32(len(set.MemberKeys))})
+	buf.Write(set.GroupKey.ToBytes())
+	for i := range set.MemberKeys {
+		buf.Write(set.MemberKeys[i].ToBytes())
+	}
+	for i := range set.MemberSecrets {
+		buf.Write(set.MemberSecrets[i].ToBytes())
+	}
+	return buf.Bytes()
+}
+
+// KeyShareSetFromBytes imports a key share set from the provided byte slice and
+// checks that each private key share matches its public key share.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func KeyShareSetFromBytes(system System, data []byte) (KeyShareSet, error) {
+
+	// Read the header.
+	var fingerprint [sha256.Size]byte
+	var header [2]uint32
+	buf := bytes.NewBuffer(data)
+	if copy(fingerprint[:], buf.Next(sha256.Size)) != sha256.Size || binary.Read(buf, binary.BigEndian, &header) != nil {
+		return KeyShareSet{}, errors.New("bls.KeyShareSetFromBytes: Data too short.")
+	}
+	if fingerprint != system.Fingerprint() {
+		return KeyShareSet{}, errors.New("bls.KeyShareSetFromBytes: Set belongs to another system.")
+	}
+	t, n := int(header[0]), int(header[1])
+	if t < 1 || n < t {
+		return KeyShareSet{}, errors.New("bls.KeyShareSetFromBytes: Bad threshold parameters.")
+	}
+	kn := len(PublicKey{system, system.g}.ToBytes())
+	xn := int(C.pairing_length_in_bytes_Zr(system.pairing.get))
+	if buf.Len() != kn*(1+n)+xn*n {
+		return KeyShareSet{}, errors.New("bls.KeyShareSetFromBytes: Set length mismatch.")
+	}
+
+	// Read the keys.
+	set := KeyShareSet{Threshold: t}
+	var err error
+	set.GroupKey, err = PublicKeyFromBytes(system, buf.Next(kn))
+	if err != nil {
+		return KeyShareSet{}, err
+	}
+	for i := 0; i < n; i++ {
+		key, err := PublicKeyFromBytes(system, buf.Next(kn))
+		if err != nil {
+			set.Free()
+			return KeyShareSet{}, err
+		}
+		set.MemberKeys = append(set.MemberKeys, key)
+	}
+	for i := 0; i < n; i++ {
+		secret, err := PrivateKeyFromBytes(system, buf.Next(xn))
+		if err != nil {
+			set.Free()
+			return KeyShareSet{}, err
+		}
+		set.MemberSecrets = append(set.MemberSecrets, secret)
+	}
+
+	// Check the key shares.
+	gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(gx, system.pairing.get)
+	match := true
+	for i := 0; match && i < n; i++ {
+		err := powSecret(gx, system.g.get, set.MemberSecrets[i].x.get)
+		if err != nil {
+			C.element_clear(gx)
+			set.Free()
+			return KeyShareSet{}, err
+		}
+		match = C.element_cmp(gx, set.MemberKeys[i].gx.get) == 0
+	}
+	C.element_clear(gx)
+	if !match {
+		set.Free()
+		return KeyShareSet{}, errors.New("bls.KeyShareSetFromBytes: Key shares are inconsistent.")
+	}
+
+	// Return the set.
+	return set, nil
+
+}
+
+// Get the number of members of the group.
+func (set KeyShareSet) Members() int {
+	return len(set.MemberKeys)
+}
+
+// Get the identifiers of all members of the group.
+func (set KeyShareSet) MemberIds() []int {
+	return identityIds(len(set.MemberKeys))
+}
+
+// Validate the key share set. The threshold configuration must be consistent,
+// each private key share present must match its public key share, and the
+// public key shares must interpolate the group public key.
+func (set KeyShareSet) Validate() error {
+
+	// Check the threshold parameters.
+	n := len(set.MemberKeys)
+	if set.Threshold < 1 || n < set.Threshold {
+		return errors.New("bls.Validate: Bad threshold parameters.")
+	}
+	if len(set.MemberSecrets) != 0 && len(set.MemberSecrets) != n {
+		return errors.New("bls.Validate: Member count mismatch.")
+	}
+
+	// Check the private key shares.
+	for i := range set.MemberSecrets {
+		public := set.MemberSecrets[i].Public()
+		match := public.Equal(set.MemberKeys[i])
+		public.Free()
+		if !match {
+			return errors.New("bls.Validate: Key shares are inconsistent.")
+		}
+	}
+
+	// Check that the public key shares lie on the polynomial of degree t - 1
+	// through the group public key and the first t - 1 public key shares.
+	t := set.Threshold
+	system := set.GroupKey.system
+	basis := append([]PublicKey{set.GroupKey}, set.MemberKeys[:t-1]...)
+	points := append([]*big.Int{big.NewInt(0)}, memberPoints(identityIds(t-1))...)
+	for i := t - 1; i < n; i++ {
+		coeffs, err := lagrangeCoefficients(points, big.NewInt(int64(i)+1), system)
+		if err != nil {
+			return err
+		}
+		key := PublicKey{system, Element{evalCommitments(basis, coeffs)}}
+		match := key.Equal(set.MemberKeys[i])
+		key.Free()
+		if !match {
+			return errors.New("bls.Validate: Key shares do not match the group key.")
+		}
+	}
+	return nil
+
+}
+
+// Describe the threshold configuration of the set.
+func (set KeyShareSet) String() string {
+	return fmt.Sprintf("%d-of-%d group %s", set.Threshold, len(set.MemberKeys), keyTag(set.GroupKey))
+}
+
+// Overwrite the private key shares and free the memory occupied by the key
+// share set. The set cannot be used after calling this function.
+func (set KeyShareSet) Zeroize() {
+	for i := range set.MemberSecrets {
+		set.MemberSecrets[i].Zeroize()
+	}
+	set.MemberSecrets = nil
+	set.Free()
+}
+
+// Free the memory occupied by the key share set. The set cannot be used after
+// calling this function.
+func (set KeyShareSet) Free() {
+	set.GroupKey.Free()
+	for i := range set.MemberKeys {
+		set.MemberKeys[i].Free()
+	}
+	for i := range set.MemberSecrets {
+		set.MemberSecrets[i].Free()
+	}
+}
@@ -0,0 +1,168 @@
+/**
+ * File        : shareset_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for key share sets.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestKeyShareSet(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key share set.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	set, err := GenKeyShareSet(2, 3, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Restore the set from a backup.
+	bytes := set.ToBytes()
+	restored, err := KeyShareSetFromBytes(system, bytes)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if restored.Threshold != 2 || len(restored.MemberSecrets) != 3 {
+		test.Fatal("Threshold configuration does not match.")
+	}
+
+	// Recover a threshold signature from the restored shares.
+	hash := sha256.Sum256([]byte(message))
+	shares := []Signature{Sign(hash, restored.MemberSecrets[0]), Sign(hash, restored.MemberSecrets[2])}
+	signature, err := Threshold(shares, []int{0, 2}, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, set.GroupKey) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Corrupted data must be rejected.
+	bytes[len(bytes)-1] ^= 1
+	_, err = KeyShareSetFromBytes(system, bytes)
+	if err == nil {
+		test.Fatal("Failed to reject inconsistent key shares.")
+	}
+
+	// Clean up.
+	signature.Free()
+	shares[0].Free()
+	shares[1].Free()
+	restored.Free()
+	set.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestDualKeyShareSets(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a 2-of-3 and a 5-of-7 share set of the same key.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	fast, recovery, err := GenDualKeyShareSets(2, 3, 5, 7, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !fast.GroupKey.Equal(recovery.GroupKey) {
+		test.Fatal("Group keys do not match.")
+	}
+
+	// Recover a threshold signature from either set.
+	hash := sha256.Sum256([]byte(message))
+	for _, c := range []struct {
+		set       KeyShareSet
+		memberIds []int
+	}{
+		{fast, []int{2, 0}},
+		{recovery, []int{6, 1, 3, 4, 0}},
+	} {
+		shares := make([]Signature, len(c.memberIds))
+		for i, id := range c.memberIds {
+			shares[i] = Sign(hash, c.set.MemberSecrets[id])
+		}
+		signature, err := Threshold(shares, c.memberIds, system)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !Verify(signature, hash, fast.GroupKey) {
+			test.Fatal("Failed to verify signature.")
+		}
+		signature.Free()
+		for i := range shares {
+			shares[i].Free()
+		}
+	}
+
+	// Clean up.
+	recovery.Free()
+	fast.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestKeyShareSetIntrospection(test *testing.T) {
+
+	// Generate a key share set.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	set, err := GenKeyShareSet(3, 5, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Report the threshold configuration.
+	if set.Members() != 5 || len(set.MemberIds()) != 5 || set.MemberIds()[4] != 4 {
+		test.Fatal("Member identifiers do not match.")
+	}
+	if !strings.HasPrefix(set.String(), "3-of-5 group ") {
+		test.Fatal("Unexpected description:", set.String())
+	}
+	err = set.Validate()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Swapped public key shares must be rejected.
+	set.MemberKeys[3], set.MemberKeys[4] = set.MemberKeys[4], set.MemberKeys[3]
+	set.MemberSecrets[3], set.MemberSecrets[4] = set.MemberSecrets[4], set.MemberSecrets[3]
+	if set.Validate() == nil {
+		test.Fatal("Accepted swapped key shares.")
+	}
+
+	// Clean up.
+	set.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
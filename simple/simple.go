@@ -0,0 +1,79 @@
+/**
+ * File        : simple.go
+ * Description : One-shot signature API.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides an API in the style of crypto/ed25519 on top of the
+ * default cryptosystem of the bls package, a type A pairing with a 256-bit
+ * group order and a 3072-bit embedding field, which offers about 128 bits of
+ * security. Keys and signatures are byte slices, and no C structures outlive a
+ * call, so applications can sign and verify messages without managing
+ * parameters, pairings, or memory.
+ */
+
+package simple
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/enzoh/go-bls"
+)
+
+// PublicKey is the type of public keys in this package.
+type PublicKey []byte
+
+// PrivateKey is the type of private keys in this package.
+type PrivateKey []byte
+
+// Generate a key pair in the default cryptosystem.
+func GenerateKey() (PublicKey, PrivateKey, error) {
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, secret, err := bls.GenKeys(system)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer key.Free()
+	defer secret.Free()
+	return key.ToBytes(), secret.ToBytes(), nil
+}
+
+// Sign the SHA-256 digest of a message using a private key.
+func Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := bls.PrivateKeyFromBytes(system, privateKey)
+	if err != nil {
+		return nil, errors.New("simple.Sign: Bad private key.")
+	}
+	defer secret.Free()
+	signature := bls.Sign(sha256.Sum256(message), secret)
+	defer signature.Free()
+	return system.SigToBytes(signature), nil
+}
+
+// Verify a signature on a message using the public key of the signer.
+func Verify(publicKey PublicKey, message []byte, sig []byte) bool {
+	system, err := bls.DefaultSystem()
+	if err != nil {
+		return false
+	}
+	key, err := bls.PublicKeyFromBytes(system, publicKey)
+	if err != nil {
+		return false
+	}
+	defer key.Free()
+	signature, err := system.SigFromBytes(sig)
+	if err != nil {
+		return false
+	}
+	defer signature.Free()
+	return bls.Verify(signature, sha256.Sum256(message), key)
+}
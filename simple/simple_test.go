@@ -0,0 +1,48 @@
+/**
+ * File        : simple_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the one-shot signature API.
+ */
+
+package simple
+
+import (
+	"testing"
+)
+
+func TestSignVerify(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Generate a key pair.
+	publicKey, privateKey, err := GenerateKey()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign and verify the message.
+	signature, err := Sign(privateKey, message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(publicKey, message, signature) {
+		test.Fatal("Failed to verify signature.")
+	}
+	if Verify(publicKey, []byte("This is another message."), signature) {
+		test.Fatal("Verified signature on wrong message.")
+	}
+
+	// Malformed inputs must be rejected.
+	_, err = Sign(privateKey[1:], message)
+	if err == nil {
+		test.Fatal("Failed to reject malformed private key.")
+	}
+	if Verify(publicKey[1:], message, signature) {
+		test.Fatal("Verified signature with malformed public key.")
+	}
+
+}
@@ -0,0 +1,247 @@
+/**
+ * File        : slashing.go
+ * Description : Slashing protection.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides a signer that records what each local key has signed
+ * and refuses to produce conflicting signatures, protecting validators from
+ * accidental double-signing. The file store appends one JSON record per line
+ * and syncs it before the signature is released. On Unix systems it also
+ * holds an exclusive lock on the file, so that two processes cannot sign with
+ * the same store.
+ */
+
+package bls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A Duty identifies the slot for which a key produces at most one signature.
+type Duty struct {
+	Epoch uint64 `json:"epoch"`
+	Round uint64 `json:"round"`
+	Type  string `json:"type"`
+}
+
+// A SlashingStore records the message digest signed by each key for each duty.
+// Keys are identified by their fingerprints.
+type SlashingStore interface {
+
+	// Look up the message digest signed by the key for the duty. The boolean
+	// result reports whether a record exists.
+	Lookup(key []byte, duty Duty) ([sha256.Size]byte, bool, error)
+
+	// Record that the key signed the message digest for the duty.
+	Record(key []byte, duty Duty, hash [sha256.Size]byte) error
+}
+
+// A Signer signs message digests using a signing backend, consulting a slashing
+// store before each signature.
+type Signer struct {
+	backend SignerBackend
+	key     []byte
+	store   SlashingStore
+	lock    *sync.Mutex
+}
+
+// Create a signer from the key pair and the slashing store.
+func NewSigner(key PublicKey, secret PrivateKey, store SlashingStore) Signer {
+	return NewBackendSigner(LocalBackend(key, secret), store)
+}
+
+// Create a signer from the signing backend and the slashing store.
+func NewBackendSigner(backend SignerBackend, store SlashingStore) Signer {
+	fingerprint := backend.PublicKey().Fingerprint()
+	return Signer{backend, fingerprint[:], store, &sync.Mutex{}}
+}
+
+// Sign a message digest for the duty. Signing the same digest for the same
+// duty again is permitted, but signing a different digest is refused. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func (signer Signer) Sign(duty Duty, hash [sha256.Size]byte) (Signature, error) {
+
+	signer.lock.Lock()
+	defer signer.lock.Unlock()
+
+	// Check for a conflicting signature.
+	prev, ok, err := signer.store.Lookup(signer.key, duty)
+	if err != nil {
+		return Element{}, err
+	}
+	if ok && prev != hash {
+		return Element{}, errors.New("bls.Sign: Conflicting signature for duty.")
+	}
+
+	// Record the signature before releasing it.
+	if !ok {
+		err = signer.store.Record(signer.key, duty, hash)
+		if err != nil {
+			return Element{}, err
+		}
+	}
+
+	// Return the signature.
+	return SignWithBackend(hash, signer.backend)
+
+}
+
+// A FileSlashingStore is a slashing store persisted as an append-only file of
+// JSON records, one per line.
+type FileSlashingStore struct {
+	file    *os.File
+	lock    *sync.Mutex
+	records map[slashingDuty][sha256.Size]byte
+}
+
+type slashingDuty struct {
+	key  string
+	duty Duty
+}
+
+type slashingRecord struct {
+	Key  string `json:"key"`
+	Duty Duty   `json:"duty"`
+	Hash string `json:"hash"`
+}
+
+// Open the slashing store at the given path, creating it if it does not exist.
+// On Unix systems the store is locked against other processes until it is
+// closed. Elsewhere it is not locked, and the caller must make sure that only
+// one process opens it.
+func OpenFileSlashingStore(path string) (FileSlashingStore, error) {
+
+	// Open and lock the file.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return FileSlashingStore{}, err
+	}
+	err = lockFile(file)
+	if err != nil {
+		file.Close()
+		return FileSlashingStore{}, err
+	}
+
+	// Make sure that the file itself survives a crash.
+	err = syncDir(filepath.Dir(path))
+	if err != nil {
+		file.Close()
+		return FileSlashingStore{}, err
+	}
+
+	// Read the records. A last line without a newline was interrupted before it
+	// was synced, so its signature was never released, and it is dropped.
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return FileSlashingStore{}, err
+	}
+	end := bytes.LastIndexByte(data, '\n') + 1
+	store := FileSlashingStore{file, &sync.Mutex{}, make(map[slashingDuty][sha256.Size]byte)}
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var record slashingRecord
+		err = json.Unmarshal(line, &record)
+		if err != nil {
+			file.Close()
+			return FileSlashingStore{}, err
+		}
+		hash, err := hex.DecodeString(record.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			file.Close()
+			return FileSlashingStore{}, errors.New("bls.OpenFileSlashingStore: Corrupt slashing record.")
+		}
+		var digest [sha256.Size]byte
+		copy(digest[:], hash)
+		store.records[slashingDuty{record.Key, record.Duty}] = digest
+	}
+	if end < len(data) {
+		err = store.truncate(int64(end))
+		if err != nil {
+			file.Close()
+			return FileSlashingStore{}, err
+		}
+	}
+
+	// Return the store.
+	return store, nil
+
+}
+
+// Look up the message digest signed by the key for the duty.
+func (store FileSlashingStore) Lookup(key []byte, duty Duty) ([sha256.Size]byte, bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	hash, ok := store.records[slashingDuty{hex.EncodeToString(key), duty}]
+	return hash, ok, nil
+}
+
+// Record that the key signed the message digest for the duty. The record is
+// appended to the file and synced before this function returns. A failed write
+// is truncated, so that it cannot corrupt the records that follow.
+func (store FileSlashingStore) Record(key []byte, duty Duty, hash [sha256.Size]byte) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	id := slashingDuty{hex.EncodeToString(key), duty}
+	if _, ok := store.records[id]; ok {
+		return errors.New("bls.Record: Duty already recorded.")
+	}
+	line, err := json.Marshal(slashingRecord{id.key, duty, hex.EncodeToString(hash[:])})
+	if err != nil {
+		return err
+	}
+	offset, err := store.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	_, err = store.file.Write(append(line, '\n'))
+	if err == nil {
+		err = store.file.Sync()
+	}
+	if err != nil {
+		store.truncate(offset)
+		return err
+	}
+	store.records[id] = hash
+	return nil
+}
+
+// Close the store and release the lock on the file. The store cannot be used
+// after calling this function.
+func (store FileSlashingStore) Close() error {
+	return store.file.Close()
+}
+
+func (store FileSlashingStore) truncate(size int64) error {
+	err := store.file.Truncate(size)
+	if err != nil {
+		return err
+	}
+	return store.file.Sync()
+}
+
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+/**
+ * File        : slashing_other.go
+ * Description : Slashing store locking on other systems.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides the fallback for systems without flock, on which the
+ * file of a slashing store is not locked.
+ */
+
+package bls
+
+import (
+	"os"
+)
+
+// Leave the file unlocked.
+func lockFile(file *os.File) error {
+	return nil
+}
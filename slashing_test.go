@@ -0,0 +1,130 @@
+/**
+ * File        : slashing_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for slashing protection.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlashingProtection(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Open a slashing store.
+	dir, err := os.MkdirTemp("", "bls")
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slashing.json")
+	store, err := OpenFileSlashingStore(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signer := NewSigner(key, secret, store)
+
+	// Sign a message for a duty, and sign it again.
+	duty := Duty{Epoch: 1, Round: 2, Type: "proposal"}
+	hash := sha256.Sum256([]byte("This is a message."))
+	signature, err := signer.Sign(duty, hash)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	signature.Free()
+	signature, err = signer.Sign(duty, hash)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signature.Free()
+
+	// A conflicting signature must be refused.
+	other := sha256.Sum256([]byte("This is another message."))
+	_, err = signer.Sign(duty, other)
+	if err == nil {
+		test.Fatal("Failed to refuse conflicting signature.")
+	}
+
+	// The store must not be opened twice.
+	_, err = OpenFileSlashingStore(path)
+	if err == nil {
+		test.Fatal("Opened a store that is in use.")
+	}
+	err = store.Close()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of a record.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = file.WriteString(`{"key":"00","duty":`)
+	if err != nil {
+		test.Fatal(err)
+	}
+	file.Close()
+
+	// A conflicting signature must be refused, even after a restart.
+	store, err = OpenFileSlashingStore(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signer = NewSigner(key, secret, store)
+	_, err = signer.Sign(duty, other)
+	if err == nil {
+		test.Fatal("Failed to refuse conflicting signature after restart.")
+	}
+
+	// The interrupted record must be dropped, so that new records follow a
+	// complete line.
+	duty = Duty{Epoch: 2, Round: 1, Type: "proposal"}
+	signature, err = signer.Sign(duty, other)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signature.Free()
+	store.Close()
+	store, err = OpenFileSlashingStore(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signer = NewSigner(key, secret, store)
+	_, err = signer.Sign(duty, hash)
+	if err == nil {
+		test.Fatal("Failed to refuse conflicting signature after recovery.")
+	}
+
+	// Clean up.
+	store.Close()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
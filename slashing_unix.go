@@ -0,0 +1,30 @@
+//go:build unix
+
+/**
+ * File        : slashing_unix.go
+ * Description : Slashing store locking on Unix systems.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module locks the file of a slashing store with flock, so that two
+ * processes cannot sign with the same store. The lock is released when the
+ * file is closed.
+ */
+
+package bls
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Take an exclusive lock on the file without blocking.
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return errors.New("bls.OpenFileSlashingStore: Store is in use by another process.")
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+/**
+ * File        : sortition.go
+ * Description : Cryptographic sortition.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides committee selection helpers built on the verifiable
+ * random function. Each participant privately evaluates the function on a
+ * seed bound to a round and role, and is selected a number of times that
+ * follows a binomial distribution weighted by its stake. Selection remains
+ * secret until the participant reveals its ticket.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/big"
+)
+
+// A Ticket proves that a participant was selected for a role in a round.
+type Ticket struct {
+	Round  uint64
+	Role   string
+	Output [sha256.Size]byte
+	Proof  Signature
+	Count  int
+}
+
+// Run the sortition for the role in the round. The participant holds stake out
+// of a total stake, and the committee has the expected size. The count of the
+// ticket is the number of committee seats won, which may be zero. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func Sortition(seed []byte, round uint64, role string, stake uint64, total uint64, expected float64, secret PrivateKey) (Ticket, error) {
+	output, proof, err := VRFProve(sortitionInput(seed, round, role), secret)
+	if err != nil {
+		return Ticket{}, err
+	}
+	count := sortitionCount(output, stake, total, expected)
+	return Ticket{round, role, output, proof, count}, nil
+}
+
+// Verify a ticket against the seed, the stake distribution, and the public key
+// of the participant.
+func VerifyTicket(ticket Ticket, seed []byte, stake uint64, total uint64, expected float64, key PublicKey) bool {
+	input := sortitionInput(seed, ticket.Round, ticket.Role)
+	if !VRFVerify(input, ticket.Output, ticket.Proof, key) {
+		return false
+	}
+	return ticket.Count == sortitionCount(ticket.Output, stake, total, expected)
+}
+
+// Elect a leader among the verified tickets. The leader is the selected ticket
+// with the lowest output. The index of the leader is returned, or -1 if no
+// ticket was selected.
+func Leader(tickets []Ticket) int {
+	leader := -1
+	for i := range tickets {
+		if tickets[i].Count == 0 {
+			continue
+		}
+		if leader == -1 || compare(tickets[i].Output, tickets[leader].Output) == -1 {
+			leader = i
+		}
+	}
+	return leader
+}
+
+// Free the memory occupied by the ticket proof. The ticket cannot be used after
+// calling this function.
+func (ticket Ticket) Free() {
+	ticket.Proof.Free()
+}
+
+// Bind the seed to the round and role.
+func sortitionInput(seed []byte, round uint64, role string) [sha256.Size]byte {
+	var bytes [8]byte
+	binary.BigEndian.PutUint64(bytes[:], round)
+	data := append([]byte("go-bls sortition"), bytes[:]...)
+	binary.BigEndian.PutUint64(bytes[:], uint64(len(role)))
+	data = append(data, bytes[:]...)
+	data = append(data, role...)
+	data = append(data, seed...)
+	return sha256.Sum256(data)
+}
+
+// The precision, in bits, of the arithmetic that inverts the binomial
+// distribution.
+const sortitionPrec = 128
+
+// Determine the number of seats won. Each unit of stake wins a seat with
+// probability expected/total, so the number of seats follows the binomial
+// distribution B(stake, expected/total). The output, read as a fraction of
+// 2^256, selects the seat count by inverting the cumulative distribution. The
+// distribution is computed with correctly rounded arbitrary-precision floats,
+// whose exponent range does not underflow for realistic stakes and whose
+// results are the same on every architecture, so that every node verifying a
+// ticket derives the same count.
+func sortitionCount(output [sha256.Size]byte, stake uint64, total uint64, expected float64) int {
+	if stake == 0 || total == 0 || !(expected > 0) || math.IsInf(expected, 1) {
+		return 0
+	}
+	newFloat := func() *big.Float {
+		return new(big.Float).SetPrec(sortitionPrec)
+	}
+
+	// Calculate p = expected/total and q = 1-p.
+	p := newFloat().SetFloat64(expected)
+	p.Quo(p, newFloat().SetUint64(total))
+	one := newFloat().SetInt64(1)
+	if p.Cmp(one) >= 0 {
+		return int(stake)
+	}
+	q := newFloat().Sub(one, p)
+
+	// Read the output as a fraction of 2^256, rounded down so that it stays
+	// below one.
+	x := newFloat().SetMode(big.ToZero).SetInt(new(big.Int).SetBytes(output[:]))
+	x.SetMantExp(x, -8*sha256.Size)
+
+	// Calculate the probability (1-p)^stake of winning no seats by repeated
+	// squaring. It is zero only if its exponent underflows, which requires
+	// billions of expected seats, and then the count is the mean.
+	pmf := newFloat().Set(one)
+	base := newFloat().Set(q)
+	for e := stake; e != 0; e >>= 1 {
+		if e&1 == 1 {
+			pmf.Mul(pmf, base)
+		}
+		base.Mul(base, base)
+	}
+	mean, _ := newFloat().Mul(p, newFloat().SetUint64(stake)).Uint64()
+	if pmf.Sign() == 0 {
+		return int(mean)
+	}
+
+	// Accumulate the distribution until it exceeds the output, using the
+	// recurrence pmf(j+1) = pmf(j) * (stake-j)/(j+1) * p/(1-p). Past the mean,
+	// the accumulation stops once the remaining terms no longer change the
+	// rounded distribution.
+	ratio := newFloat().Quo(p, q)
+	cdf := newFloat().Set(pmf)
+	t := newFloat()
+	j := uint64(0)
+	for x.Cmp(cdf) >= 0 && j < stake {
+		t.SetUint64(stake - j)
+		pmf.Mul(pmf, t)
+		t.SetUint64(j + 1)
+		pmf.Quo(pmf, t)
+		pmf.Mul(pmf, ratio)
+		if j > mean && pmf.MantExp(nil) < cdf.MantExp(nil)-sortitionPrec {
+			break
+		}
+		cdf.Add(cdf, pmf)
+		j++
+	}
+	return int(j)
+}
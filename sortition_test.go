@@ -0,0 +1,120 @@
+/**
+ * File        : sortition_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for cryptographic sortition.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSortition(test *testing.T) {
+
+	seed := []byte("This is a seed.")
+	n := 10
+	stake := uint64(100)
+	total := stake * uint64(n)
+
+	// Generate key pairs.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	keys := make([]PublicKey, n)
+	secrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		keys[i], secrets[i], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Run the sortition with everyone selected.
+	tickets := make([]Ticket, n)
+	for i := 0; i < n; i++ {
+		tickets[i], err = Sortition(seed, 1, "proposer", stake, total, float64(total), secrets[i])
+		if err != nil {
+			test.Fatal(err)
+		}
+		if tickets[i].Count != int(stake) {
+			test.Fatal("Expected every unit of stake to be selected.")
+		}
+		if !VerifyTicket(tickets[i], seed, stake, total, float64(total), keys[i]) {
+			test.Fatal("Failed to verify ticket.")
+		}
+	}
+
+	// The leader has the lowest output.
+	leader := Leader(tickets)
+	for i := range tickets {
+		if compare(tickets[i].Output, tickets[leader].Output) == -1 {
+			test.Fatal("Leader does not have the lowest output.")
+		}
+	}
+
+	// A ticket is bound to its round and its holder.
+	tickets[0].Round++
+	if VerifyTicket(tickets[0], seed, stake, total, float64(total), keys[0]) {
+		test.Fatal("Verified ticket for wrong round.")
+	}
+	tickets[0].Round--
+	if VerifyTicket(tickets[0], seed, stake, total, float64(total), keys[1]) {
+		test.Fatal("Verified ticket for wrong key.")
+	}
+
+	// Clean up.
+	for i := 0; i < n; i++ {
+		tickets[i].Free()
+		keys[i].Free()
+		secrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSortitionCount(test *testing.T) {
+	var low, high [sha256.Size]byte
+	for i := range high {
+		high[i] = 0xff
+	}
+	if sortitionCount(low, 1000, 1000000, 10) != 0 {
+		test.Fatal("Expected no seats for the lowest output.")
+	}
+	if sortitionCount(high, 1000, 1000000, 10) == 0 {
+		test.Fatal("Expected seats for the highest output.")
+	}
+	if sortitionCount(high, 0, 1000000, 10) != 0 {
+		test.Fatal("Expected no seats without stake.")
+	}
+}
+
+func TestSortitionCountLargeStake(test *testing.T) {
+
+	// With a million units of stake and two thousand expected seats, the count
+	// must still depend on the output and stay near the mean.
+	stake := uint64(1000000)
+	counts := make(map[int]bool)
+	for i := 0; i < 16; i++ {
+		output := sha256.Sum256([]byte{byte(i)})
+		count := sortitionCount(output, stake, stake, 2000)
+		if count < 1700 || count > 2300 {
+			test.Fatalf("Count %d is far from the mean.", count)
+		}
+		counts[count] = true
+	}
+	if len(counts) < 2 {
+		test.Fatal("Count does not depend on the output.")
+	}
+
+}
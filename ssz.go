@@ -0,0 +1,94 @@
+/**
+ * File        : ssz.go
+ * Description : SimpleSerialize encoding.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module encodes public keys and signatures as SSZ byte vectors, as
+ * Ethereum consensus data structures do. The serialization of a fixed-length
+ * byte vector is the bytes themselves, and its hash tree root is the Merkle
+ * root of the bytes packed into 32-byte chunks. The method set of PublicKey
+ * matches the interfaces of common SSZ libraries.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// The size of an SSZ chunk.
+const sszChunkSize = 32
+
+// MarshalSSZ returns the SSZ encoding of the public key.
+func (key PublicKey) MarshalSSZ() ([]byte, error) {
+	return key.ToBytes(), nil
+}
+
+// SizeSSZ returns the length of the SSZ encoding of the public key.
+func (key PublicKey) SizeSSZ() int {
+	return len(key.ToBytes())
+}
+
+// UnmarshalSSZ decodes a public key from SSZ. Since the encoding does not
+// identify the cryptosystem, the key must already belong to one, for example
+// by being the zero key PublicKey{system, Element{}} of the system.
+func (key *PublicKey) UnmarshalSSZ(data []byte) error {
+	if key.system.pairing.get == nil {
+		return errors.New("bls.UnmarshalSSZ: Unknown system.")
+	}
+	k, err := PublicKeyFromBytes(key.system, data)
+	if err != nil {
+		return err
+	}
+	*key = k
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the public key.
+func (key PublicKey) HashTreeRoot() ([sha256.Size]byte, error) {
+	return sszRoot(key.ToBytes()), nil
+}
+
+// Convert a signature to SSZ.
+func (system System) SigToSSZ(signature Signature) []byte {
+	return system.SigToBytes(signature)
+}
+
+// Convert SSZ to a signature.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (system System) SigFromSSZ(data []byte) (Signature, error) {
+	return system.SigFromBytes(data)
+}
+
+// Compute the SSZ hash tree root of a signature.
+func (system System) SigHashTreeRoot(signature Signature) [sha256.Size]byte {
+	return sszRoot(system.SigToBytes(signature))
+}
+
+// Compute the hash tree root of a fixed-length byte vector. The bytes are
+// packed into zero-padded chunks, the number of chunks is padded with zero
+// chunks to a power of two, and the chunks are hashed pairwise up to the root.
+func sszRoot(bytes []byte) [sha256.Size]byte {
+	n := (len(bytes) + sszChunkSize - 1) / sszChunkSize
+	width := 1
+	for width < n {
+		width *= 2
+	}
+	chunks := make([][sha256.Size]byte, width)
+	for i := 0; i < n; i++ {
+		copy(chunks[i][:], bytes[i*sszChunkSize:])
+	}
+	for len(chunks) > 1 {
+		for i := range chunks[:len(chunks)/2] {
+			chunks[i] = sha256.Sum256(append(chunks[2*i][:], chunks[2*i+1][:]...))
+		}
+		chunks = chunks[:len(chunks)/2]
+	}
+	return chunks[0]
+}
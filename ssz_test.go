@@ -0,0 +1,94 @@
+/**
+ * File        : ssz_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for SimpleSerialize encoding.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSSZ(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Round-trip the key and the signature.
+	data, err := key.MarshalSSZ()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(data) != key.SizeSSZ() {
+		test.Fatal("Unexpected SSZ length.")
+	}
+	keyIn := PublicKey{system: system}
+	err = keyIn.UnmarshalSSZ(data)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signatureIn, err := system.SigFromSSZ(system.SigToSSZ(signature))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// The roots of equal values are equal.
+	root1, _ := key.HashTreeRoot()
+	root2, _ := keyIn.HashTreeRoot()
+	if root1 != root2 || system.SigHashTreeRoot(signature) != system.SigHashTreeRoot(signatureIn) {
+		test.Fatal("Hash tree roots do not match.")
+	}
+
+	// Clean up.
+	signatureIn.Free()
+	keyIn.Free()
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestSSZRoot(test *testing.T) {
+
+	// A single chunk is its own root.
+	var chunk [sha256.Size]byte
+	chunk[0] = 1
+	if sszRoot([]byte{1}) != chunk {
+		test.Fatal("Unexpected root of a single chunk.")
+	}
+
+	// Three chunks are padded to four.
+	bytes := make([]byte, 65)
+	var zero [sha256.Size]byte
+	left := sha256.Sum256(append(zero[:], zero[:]...))
+	expected := sha256.Sum256(append(left[:], left[:]...))
+	if sszRoot(bytes) != expected {
+		test.Fatal("Unexpected root of three chunks.")
+	}
+
+}
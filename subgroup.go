@@ -0,0 +1,133 @@
+/**
+ * File        : subgroup.go
+ * Description : Subgroup membership checks.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module checks that group elements lie in the prime-order subgroup of
+ * the cryptosystem, according to a policy chosen per cryptosystem. It also
+ * checks that decoded points lie on the curve and were encoded canonically,
+ * which is cheap and therefore done regardless of the policy.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// SubgroupCheck identifies when elements are checked for membership in the
+// prime-order subgroup. Each check costs one exponentiation by the group order.
+type SubgroupCheck int
+
+const (
+	// SubgroupCheckAlways checks elements when they are deserialized and
+	// again before they are used to aggregate or verify signatures. This is
+	// the default.
+	SubgroupCheckAlways SubgroupCheck = iota
+	// SubgroupCheckOnDeserialize checks elements only when they are
+	// deserialized. Elements that are constructed in memory by other means
+	// are trusted.
+	SubgroupCheckOnDeserialize
+	// SubgroupCheckNever skips all checks. It is only safe when every element
+	// comes from a trusted source.
+	SubgroupCheckNever
+)
+
+// Select the subgroup check policy of the cryptosystem.
+func (system System) WithSubgroupCheck(policy SubgroupCheck) System {
+	system.subgroup = policy
+	return system
+}
+
+// Check whether the elements lie in the prime-order subgroup. The elements must
+// belong to the same group. The scratch space is shared by all checks, so a
+// batch costs one exponentiation per element and no further allocation.
+//
+// The elements are not checked together as a random linear combination, which
+// would cost one exponentiation in total. That check misses a point outside
+// the subgroup with probability up to 1/p, where p is the smallest prime
+// factor of the cofactor. The cofactor of a type A curve is divisible by 12,
+// so a batch would accept an element with a component of order two half the
+// time.
+func (system System) inSubgroup(elements ...Element) bool {
+	if len(elements) == 0 {
+		return true
+	}
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_same_as(t, elements[0].get)
+	result := true
+	for i := range elements {
+		C.element_pow_mpz(t, elements[i].get, &system.pairing.get.r[0])
+		if C.element_is1(t) != 1 {
+			result = false
+			break
+		}
+	}
+	C.element_clear(t)
+	return result
+}
+
+// Check whether any of the elements is the identity, which no valid public key
+// or signature is. A signature at infinity verifies under a public key at
+// infinity for every message, and aggregates to the same signature it is
+// added to.
+func isIdentity(elements ...Element) bool {
+	for i := range elements {
+		if C.element_is1(elements[i].get) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Check whether elements must be checked when they are deserialized.
+func (system System) checkOnDeserialize() bool {
+	return system.subgroup != SubgroupCheckNever
+}
+
+// Check whether elements must be checked before they are used.
+func (system System) checkOnUse() bool {
+	return system.subgroup == SubgroupCheckAlways
+}
+
+// Check whether a point decoded from the given bytes in the given format lies
+// on the curve and re-encodes to the same bytes. PBC decodes points without
+// checking the curve equation, and reduces coordinates that exceed the field
+// modulus. Decompressing the point recomputes its y-coordinate from the curve
+// equation, so only points on the curve survive the round trip.
+func onCurve(point *C.struct_element_s, data []byte, format PointFormat) bool {
+
+	// Re-encode the point.
+	encoded := make([]byte, len(data))
+	switch format {
+	case PointUncompressed:
+		C.element_to_bytes((*C.uchar)(unsafe.Pointer(&encoded[0])), point)
+	case PointXOnly:
+		C.element_to_bytes_x_only((*C.uchar)(unsafe.Pointer(&encoded[0])), point)
+	default:
+		C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&encoded[0])), point)
+	}
+	if string(encoded) != string(data) {
+		return false
+	}
+
+	// Decompress the point.
+	compressed := make([]byte, int(C.element_length_in_bytes_compressed(point)))
+	C.element_to_bytes_compressed((*C.uchar)(unsafe.Pointer(&compressed[0])), point)
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_same_as(t, point)
+	C.element_from_bytes_compressed(t, (*C.uchar)(unsafe.Pointer(&compressed[0])))
+	result := C.element_cmp(t, point) == 0
+	C.element_clear(t)
+
+	// Return the result.
+	return result
+
+}
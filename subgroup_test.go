@@ -0,0 +1,163 @@
+/**
+ * File        : subgroup_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for subgroup membership checks.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestSubgroupCheck(test *testing.T) {
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	trusting := system.WithSubgroupCheck(SubgroupCheckNever)
+
+	// Find an encoded point outside of the prime-order subgroup, and one that
+	// is not on the curve at all.
+	n := len(system.SigToBytes(system.g))
+	var bytes, offCurve []byte
+	for i := 0; i < 100; i++ {
+		hash := sha256.Sum256([]byte{byte(i)})
+		bytes = make([]byte, n)
+		copy(bytes[1:], hash[:])
+		sigma, err := trusting.SigFromBytes(bytes)
+		if err != nil {
+			offCurve = bytes
+			bytes = nil
+			continue
+		}
+		outside := !system.inSubgroup(sigma)
+		sigma.Free()
+		if outside {
+			break
+		}
+		bytes = nil
+	}
+	if bytes == nil {
+		test.Fatal("Failed to find a point outside of the subgroup.")
+	}
+	if offCurve == nil {
+		for i := 100; offCurve == nil && i < 200; i++ {
+			hash := sha256.Sum256([]byte{byte(i)})
+			data := make([]byte, n)
+			copy(data[1:], hash[:])
+			sigma, err := trusting.SigFromBytes(data)
+			if err != nil {
+				offCurve = data
+			} else {
+				sigma.Free()
+			}
+		}
+	}
+	if offCurve == nil {
+		test.Fatal("Failed to find a point off the curve.")
+	}
+
+	// The default policy must reject the point.
+	_, err = system.SigFromBytes(bytes)
+	if err == nil {
+		test.Fatal("Failed to reject point outside of the subgroup.")
+	}
+
+	// Even the trusting policy must reject points off the curve and points that
+	// are not encoded canonically.
+	_, err = trusting.SigFromBytes(offCurve)
+	if err == nil {
+		test.Fatal("Failed to reject point off the curve.")
+	}
+	bytes[n-1] = 2
+	_, err = trusting.SigFromBytes(bytes)
+	if err == nil {
+		test.Fatal("Failed to reject point with a non-canonical sign.")
+	}
+
+	// Signatures must lie in the subgroup.
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("This is a message."))
+	signature := Sign(hash, secret)
+	if !system.inSubgroup(signature) {
+		test.Fatal("Signature not in subgroup.")
+	}
+
+	// Clean up.
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestRejectIdentity(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Build the identities of G1 and G2.
+	hash := sha256.Sum256([]byte("This is a message."))
+	signature := Sign(hash, secret)
+	zero := []*big.Int{big.NewInt(0)}
+	identity := interpolateShares([]Signature{signature}, zero, system)
+	nullKey := PublicKey{system, Element{evalCommitments([]PublicKey{key}, zero)}}
+
+	// Verification and aggregation must reject the identities.
+	if Verify(identity, hash, nullKey) {
+		test.Fatal("Failed to reject the identity under a null key.")
+	}
+	if Verify(identity, hash, key) {
+		test.Fatal("Failed to reject the identity as a signature.")
+	}
+	_, err = Aggregate([]Signature{signature, identity}, system)
+	if err == nil {
+		test.Fatal("Failed to reject the identity in an aggregate.")
+	}
+	valid, err := VerifySameMessage(signature, hash, []PublicKey{key, nullKey})
+	if err != nil || valid {
+		test.Fatal("Failed to reject a null key in an aggregate key.")
+	}
+
+	// Deserialization must reject a zero private key.
+	_, err = PrivateKeyFromBytes(system, make([]byte, len(secret.ToBytes())))
+	if err == nil {
+		test.Fatal("Failed to reject a zero private key.")
+	}
+
+	// Clean up.
+	identity.Free()
+	nullKey.Free()
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
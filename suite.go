@@ -0,0 +1,118 @@
+/**
+ * File        : suite.go
+ * Description : Ciphersuites.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module maintains a registry of named ciphersuites. A ciphersuite fixes
+ * the pairing parameters, the hash function, and the domain separation tag,
+ * and determines the system parameter, so that peers which agree on a suite
+ * identifier during a handshake share the same cryptosystem.
+ */
+
+package bls
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// A Suite describes a cryptosystem. Signatures are always in G1 and public keys
+// in G2. The identifier must be stable across releases, since peers exchange
+// it on the wire.
+type Suite struct {
+	ID     string
+	Params string
+	Hash   HashType
+	DST    string
+}
+
+var suites = struct {
+	sync.RWMutex
+	byID map[string]Suite
+}{byID: make(map[string]Suite)}
+
+func init() {
+	for _, suite := range []Suite{
+		{"BLS_SIG_PBC-A1536_G1_SHA256", defaultParams, HashSHA256, "BLS_SIG_PBC-A1536_G1_SHA256"},
+		{"BLS_SIG_PBC-A1536_G1_SHA3-256", defaultParams, HashSHA3_256, "BLS_SIG_PBC-A1536_G1_SHA3-256"},
+		{"BLS_SIG_PBC-A1536_G1_SHA512-256", defaultParams, HashSHA512_256, "BLS_SIG_PBC-A1536_G1_SHA512-256"},
+	} {
+		err := RegisterSuite(suite)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register a ciphersuite. An identifier cannot be registered twice.
+func RegisterSuite(suite Suite) error {
+	if suite.ID == "" {
+		return errors.New("bls.RegisterSuite: Empty identifier.")
+	}
+	if len(suite.DST) == 0 || len(suite.DST) > 255 {
+		return errors.New("bls.RegisterSuite: Domain separation tag must have 1 to 255 bytes.")
+	}
+	suites.Lock()
+	defer suites.Unlock()
+	if _, ok := suites.byID[suite.ID]; ok {
+		return errors.New("bls.RegisterSuite: Duplicate identifier.")
+	}
+	suites.byID[suite.ID] = suite
+	return nil
+}
+
+// Look up a registered ciphersuite by its identifier.
+func LookupSuite(id string) (Suite, bool) {
+	suites.RLock()
+	defer suites.RUnlock()
+	suite, ok := suites.byID[id]
+	return suite, ok
+}
+
+// List the identifiers of the registered ciphersuites in lexical order.
+func Supported() []string {
+	suites.RLock()
+	defer suites.RUnlock()
+	ids := make([]string, 0, len(suites.byID))
+	for id := range suites.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Select the first ciphersuite in the local preference list that the remote
+// peer also offers and that is registered.
+func Negotiate(local []string, remote []string) (string, error) {
+	offered := make(map[string]bool, len(remote))
+	for _, id := range remote {
+		offered[id] = true
+	}
+	for _, id := range local {
+		if _, ok := LookupSuite(id); ok && offered[id] {
+			return id, nil
+		}
+	}
+	return "", errors.New("bls.Negotiate: No common ciphersuite.")
+}
+
+// Build the cryptosystem of the ciphersuite. The system parameter is derived
+// from the identifier, so every peer builds the same system. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the cryptosystem and
+// its pairing to be freed.
+func (suite Suite) System() (System, error) {
+	params, err := ParamsFromBytes([]byte(suite.Params))
+	if err != nil {
+		return System{}, err
+	}
+	pairing := GenPairing(params)
+	params.Free()
+	system := genSystemFromSeed(pairing, []byte(suite.ID))
+	system.hash = suite.Hash
+	system.dst = []byte(suite.DST)
+	return system, nil
+}
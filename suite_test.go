@@ -0,0 +1,105 @@
+/**
+ * File        : suite_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for ciphersuites.
+ */
+
+package bls
+
+import (
+	"testing"
+)
+
+func TestNegotiate(test *testing.T) {
+
+	// The built-in suites must be supported.
+	if len(Supported()) < 3 {
+		test.Fatal("Missing built-in ciphersuites.")
+	}
+
+	// The local preference wins among the common suites.
+	local := []string{"BLS_SIG_UNKNOWN", "BLS_SIG_PBC-A1536_G1_SHA3-256", "BLS_SIG_PBC-A1536_G1_SHA256"}
+	remote := []string{"BLS_SIG_PBC-A1536_G1_SHA256", "BLS_SIG_UNKNOWN", "BLS_SIG_PBC-A1536_G1_SHA3-256"}
+	id, err := Negotiate(local, remote)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if id != "BLS_SIG_PBC-A1536_G1_SHA3-256" {
+		test.Fatal("Unexpected ciphersuite.")
+	}
+	_, err = Negotiate(local[:1], remote)
+	if err == nil {
+		test.Fatal("Negotiated an unregistered ciphersuite.")
+	}
+
+	// Identifiers cannot be registered twice.
+	suite, _ := LookupSuite(id)
+	if RegisterSuite(suite) == nil {
+		test.Fatal("Registered a duplicate ciphersuite.")
+	}
+
+}
+
+func TestSuiteSystem(test *testing.T) {
+
+	message := []byte("This is a message.")
+
+	// Two peers build the same cryptosystem.
+	suite, ok := LookupSuite("BLS_SIG_PBC-A1536_G1_SHA256")
+	if !ok {
+		test.Fatal("Missing built-in ciphersuite.")
+	}
+	alice, err := suite.System()
+	if err != nil {
+		test.Fatal(err)
+	}
+	bob, err := suite.System()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if alice.Fingerprint() != bob.Fingerprint() {
+		test.Fatal("Peers built different systems.")
+	}
+
+	// A signature by one peer verifies for the other.
+	key, secret, err := GenKeys(alice)
+	if err != nil {
+		test.Fatal(err)
+	}
+	digest, err := alice.Digest(message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	signature := Sign(digest, secret)
+	imported, err := PublicKeyFromBytes(bob, key.ToBytes())
+	if err != nil {
+		test.Fatal(err)
+	}
+	received, err := bob.SigFromBytes(alice.SigToBytes(signature))
+	if err != nil {
+		test.Fatal(err)
+	}
+	digest, err = bob.Digest(message)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(received, digest, imported) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	received.Free()
+	imported.Free()
+	signature.Free()
+	key.Free()
+	secret.Free()
+	alice.Free()
+	alice.Pairing().Free()
+	bob.Free()
+	bob.Pairing().Free()
+
+}
@@ -0,0 +1,100 @@
+/**
+ * File        : table.go
+ * Description : Fixed-base signing.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module signs the same message digest with many keys, as a simulation or
+ * a party holding many key shares does, by exponentiating the hashed message
+ * with a precomputed table of its powers rather than with a generic
+ * exponentiation for each key.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+
+void locked_enter(void);
+void locked_leave(void);
+
+// The table only covers exponents as long as the group order, so instead of
+// adding a multiple of the order, split the private key into two random
+// shares and multiply their powers.
+void pp_pow_secret(struct element_s *out, struct element_pp_s *pp, struct element_s *x) {
+	element_t a, b, t;
+	mpz_t e;
+	locked_enter();
+	element_init_same_as(a, x);
+	element_init_same_as(b, x);
+	mpz_init(e);
+	locked_leave();
+	element_random(a);
+	element_sub(b, x, a);
+	element_init_same_as(t, out);
+	locked_enter();
+	element_to_mpz(e, a);
+	locked_leave();
+	element_pp_pow(out, e, pp);
+	locked_enter();
+	element_to_mpz(e, b);
+	locked_leave();
+	element_pp_pow(t, e, pp);
+	element_mul(out, out, t);
+	element_set0(a);
+	element_set0(b);
+	mpz_set_ui(e, 0);
+	element_clear(a);
+	element_clear(b);
+	element_clear(t);
+	mpz_clear(e);
+}
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"unsafe"
+)
+
+const sizeOfElementPP = C.size_t(unsafe.Sizeof(C.struct_element_pp_s{}))
+
+// A PointTable holds the precomputed powers of the point in G1 of a message
+// digest.
+type PointTable struct {
+	system System
+	pp     *C.struct_element_pp_s
+}
+
+// Precompute the powers of the point of the message digest. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func NewPointTable(hash [sha256.Size]byte, system System) PointTable {
+	h := system.digestToPoint(hash[:])
+	pp := (*C.struct_element_pp_s)(C.malloc(sizeOfElementPP))
+	C.element_pp_init(pp, h)
+	C.element_clear(h)
+	return PointTable{system, pp}
+}
+
+// Sign the message digest of the table using a private key, with the same
+// result as Sign. The private key is split into two random shares, which are
+// exponentiated separately, so that the timing does not depend on the key
+// alone. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+func (table PointTable) Sign(secret PrivateKey) Signature {
+	sigma := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(sigma, table.system.pairing.get)
+	C.pp_pow_secret(sigma, table.pp, secret.x.get)
+	return Element{sigma}
+}
+
+// Free the memory occupied by the table. The table cannot be used after calling
+// this function.
+func (table PointTable) Free() {
+	C.element_pp_clear(table.pp)
+	C.free(unsafe.Pointer(table.pp))
+}
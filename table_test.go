@@ -0,0 +1,67 @@
+/**
+ * File        : table_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for fixed-base signing.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestPointTable(test *testing.T) {
+
+	t := 3
+	n := 5
+	message := "This is a message."
+
+	// Generate the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message with every share using the table.
+	hash := sha256.Sum256([]byte(message))
+	table := NewPointTable(hash, system)
+	for i := 0; i < n; i++ {
+		share := table.Sign(memberSecrets[i])
+		expected := Sign(hash, memberSecrets[i])
+		if !share.Equal(expected) {
+			test.Fatal("Signature shares do not match.")
+		}
+		if !VerifyShare(share, hash, memberKeys[i]) {
+			test.Fatal("Failed to verify signature share.")
+		}
+		share.Free()
+		expected.Free()
+	}
+
+	// Clean up.
+	table.Free()
+	for j := range commitments {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,144 @@
+/**
+ * File        : text.go
+ * Description : Textual encodings.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides canonical hex and base64 forms of signatures and keys
+ * for log output, command-line tools, and configuration files. Hex is lower
+ * case and base64 is the padded standard alphabet. Anything else, including
+ * upper-case hex and non-canonical padding bits, is rejected.
+ */
+
+package bls
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+var strictBase64 = base64.StdEncoding.Strict()
+
+// Convert a signature to lower-case hex.
+func (system System) SigToHex(signature Signature) string {
+	return hex.EncodeToString(system.SigToBytes(signature))
+}
+
+// Convert lower-case hex to a signature.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (system System) SigFromHex(s string) (Signature, error) {
+	bytes, err := decodeHex(s)
+	if err != nil {
+		return Signature{}, err
+	}
+	return system.SigFromBytes(bytes)
+}
+
+// Convert a signature to base64.
+func (system System) SigToBase64(signature Signature) string {
+	return strictBase64.EncodeToString(system.SigToBytes(signature))
+}
+
+// Convert base64 to a signature.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (system System) SigFromBase64(s string) (Signature, error) {
+	bytes, err := strictBase64.DecodeString(s)
+	if err != nil {
+		return Signature{}, errors.New("bls.FromBase64: Invalid base64.")
+	}
+	return system.SigFromBytes(bytes)
+}
+
+// Convert the public key to lower-case hex.
+func (key PublicKey) Hex() string {
+	return hex.EncodeToString(key.ToBytes())
+}
+
+// PublicKeyFromHex converts lower-case hex to a public key.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PublicKeyFromHex(system System, s string) (PublicKey, error) {
+	bytes, err := decodeHex(s)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKeyFromBytes(system, bytes)
+}
+
+// Convert the public key to base64.
+func (key PublicKey) Base64() string {
+	return strictBase64.EncodeToString(key.ToBytes())
+}
+
+// PublicKeyFromBase64 converts base64 to a public key.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PublicKeyFromBase64(system System, s string) (PublicKey, error) {
+	bytes, err := strictBase64.DecodeString(s)
+	if err != nil {
+		return PublicKey{}, errors.New("bls.FromBase64: Invalid base64.")
+	}
+	return PublicKeyFromBytes(system, bytes)
+}
+
+// Convert the private key to lower-case hex.
+func (secret PrivateKey) Hex() string {
+	return hex.EncodeToString(secret.ToBytes())
+}
+
+// PrivateKeyFromHex converts lower-case hex to a private key.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PrivateKeyFromHex(system System, s string) (PrivateKey, error) {
+	bytes, err := decodeHex(s)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	return PrivateKeyFromBytes(system, bytes)
+}
+
+// Convert the private key to base64.
+func (secret PrivateKey) Base64() string {
+	return strictBase64.EncodeToString(secret.ToBytes())
+}
+
+// PrivateKeyFromBase64 converts base64 to a private key.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PrivateKeyFromBase64(system System, s string) (PrivateKey, error) {
+	bytes, err := strictBase64.DecodeString(s)
+	if err != nil {
+		return PrivateKey{}, errors.New("bls.FromBase64: Invalid base64.")
+	}
+	return PrivateKeyFromBytes(system, bytes)
+}
+
+// Decode lower-case hex. The lengths of the decoded values are checked by the
+// byte decoders.
+func decodeHex(s string) ([]byte, error) {
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f') {
+			return nil, errors.New("bls.FromHex: Invalid character.")
+		}
+	}
+	if len(s)%2 != 0 {
+		return nil, errors.New("bls.FromHex: Odd length.")
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,73 @@
+/**
+ * File        : text_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for textual encodings.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestText(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Round-trip through hex and base64.
+	keyIn, err := PublicKeyFromHex(system, key.Hex())
+	if err != nil {
+		test.Fatal(err)
+	}
+	signatureIn, err := system.SigFromBase64(system.SigToBase64(signature))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Non-canonical text must be rejected.
+	s := system.SigToHex(signature)
+	for _, bad := range []string{strings.ToUpper(s), s[:len(s)-2], s + "0", " " + s} {
+		_, err = system.SigFromHex(bad)
+		if err == nil {
+			test.Fatal("Accepted non-canonical hex.")
+		}
+	}
+	_, err = PublicKeyFromBase64(system, strings.TrimRight(key.Base64(), "="))
+	if err == nil {
+		test.Fatal("Accepted unpadded base64.")
+	}
+
+	// Clean up.
+	signatureIn.Free()
+	keyIn.Free()
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
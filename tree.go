@@ -0,0 +1,206 @@
+/**
+ * File        : tree.go
+ * Description : Nested threshold structures.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module composes threshold groups, so that the share of a member can
+ * itself be held by a threshold group. For example, a key can require two of
+ * three organizations, each of which signs with three of its five employees.
+ * The share of each inner node is dealt to its children in turn, and a
+ * signature is recovered from the bottom up, each inner node recovering its
+ * share of the signature from the shares of its children.
+ */
+
+package bls
+
+import (
+	"errors"
+	"sort"
+)
+
+// A TreeSpec describes a nested threshold structure. A node without children
+// is held by a single signer. A node with children is held by its children,
+// any Threshold of whom can sign on its behalf.
+type TreeSpec struct {
+	Threshold int
+	Children  []TreeSpec
+}
+
+// A KeyTree holds the keys of a nested threshold structure. The key of each
+// node is its public key share within its parent, and the key of the root is
+// the group public key. Only the leaves hold private keys.
+type KeyTree struct {
+	Threshold int
+	Key       PublicKey
+	Secret    PrivateKey
+	Children  []KeyTree
+}
+
+// A TreeShare is a signature share of a leaf, identified by the path of child
+// indices from the root.
+type TreeShare struct {
+	Path  []int
+	Share Signature
+}
+
+// Generate a key pair from the given cryptosystem and divide it according to
+// the threshold structure. The private keys of the root and the inner nodes
+// are discarded. This function allocates C structures on the C heap using
+// malloc. It is the responsibility of the caller to prevent memory leaks by
+// arranging for the C structures to be freed.
+func GenKeyTree(spec TreeSpec, system System) (KeyTree, error) {
+	err := spec.check()
+	if err != nil {
+		return KeyTree{}, err
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		return KeyTree{}, err
+	}
+	return genKeyTree(spec, key, secret)
+}
+
+// Divide the private key of a node among its children, and recurse.
+func genKeyTree(spec TreeSpec, key PublicKey, secret PrivateKey) (KeyTree, error) {
+
+	// A leaf keeps its private key.
+	if len(spec.Children) == 0 {
+		return KeyTree{Key: key, Secret: secret}, nil
+	}
+
+	// Deal the private key to the children.
+	commitments, shares, err := DealSecret(secret, spec.Threshold, len(spec.Children))
+	secret.Zeroize()
+	if err != nil {
+		key.Free()
+		return KeyTree{}, err
+	}
+	tree := KeyTree{Threshold: spec.Threshold, Key: key, Children: make([]KeyTree, len(spec.Children))}
+	for i := range spec.Children {
+		childKey, _ := CommitmentKey(commitments, i)
+		tree.Children[i], err = genKeyTree(spec.Children[i], childKey, shares[i])
+		if err != nil {
+			for j := i + 1; j < len(shares); j++ {
+				shares[j].Zeroize()
+			}
+			tree.Children = tree.Children[:i]
+			tree.Free()
+			break
+		}
+	}
+
+	// Clean up.
+	for j := range commitments {
+		commitments[j].Free()
+	}
+
+	// Return the tree.
+	if err != nil {
+		return KeyTree{}, err
+	}
+	return tree, nil
+
+}
+
+// Check the threshold parameters of every inner node.
+func (spec TreeSpec) check() error {
+	if len(spec.Children) == 0 {
+		return nil
+	}
+	if spec.Threshold < 1 || len(spec.Children) < spec.Threshold {
+		return errors.New("bls.GenKeyTree: Bad threshold parameters.")
+	}
+	for i := range spec.Children {
+		err := spec.Children[i].check()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Look up the node at the given path of child indices.
+func (tree KeyTree) Node(path []int) (KeyTree, error) {
+	for _, i := range path {
+		if i < 0 || i >= len(tree.Children) {
+			return KeyTree{}, errors.New("bls.Node: Invalid path.")
+		}
+		tree = tree.Children[i]
+	}
+	return tree, nil
+}
+
+// Recover the signature of the tree from the signature shares of its leaves.
+// Each inner node recovers its share from the shares of the first Threshold of
+// its children that can sign, in order of their indices. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func (tree KeyTree) Recover(shares []TreeShare) (Signature, error) {
+
+	// A leaf signs directly, so its share is copied.
+	system := tree.Key.system
+	if len(tree.Children) == 0 {
+		for _, share := range shares {
+			if len(share.Path) == 0 {
+				return Aggregate([]Signature{share.Share}, system)
+			}
+		}
+		return Element{}, errors.New("bls.Recover: Missing share.")
+	}
+
+	// Group the shares by child.
+	byChild := make(map[int][]TreeShare)
+	for _, share := range shares {
+		if len(share.Path) == 0 || share.Path[0] < 0 || share.Path[0] >= len(tree.Children) {
+			return Element{}, errors.New("bls.Recover: Invalid path.")
+		}
+		byChild[share.Path[0]] = append(byChild[share.Path[0]], TreeShare{share.Path[1:], share.Share})
+	}
+	children := make([]int, 0, len(byChild))
+	for i := range byChild {
+		children = append(children, i)
+	}
+	sort.Ints(children)
+
+	// Recover the shares of the children.
+	var sigs []Signature
+	var memberIds []int
+	defer func() {
+		for i := range sigs {
+			sigs[i].Free()
+		}
+	}()
+	for _, i := range children {
+		if len(sigs) == tree.Threshold {
+			break
+		}
+		sig, err := tree.Children[i].Recover(byChild[i])
+		if err != nil {
+			continue
+		}
+		sigs = append(sigs, sig)
+		memberIds = append(memberIds, i)
+	}
+	if len(sigs) < tree.Threshold {
+		return Element{}, errors.New("bls.Recover: Too few shares.")
+	}
+
+	// Recover the share of this node.
+	return Threshold(sigs, memberIds, system)
+
+}
+
+// Free the memory occupied by the key tree, wiping the private keys of the
+// leaves.
+func (tree KeyTree) Free() {
+	tree.Key.Free()
+	if len(tree.Children) == 0 {
+		tree.Secret.Zeroize()
+	}
+	for i := range tree.Children {
+		tree.Children[i].Free()
+	}
+}
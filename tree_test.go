@@ -0,0 +1,80 @@
+/**
+ * File        : tree_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for nested threshold structures.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestKeyTree(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key for two of three organizations, each of which signs
+	// with three of its five employees.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	employees := make([]TreeSpec, 5)
+	organization := TreeSpec{Threshold: 3, Children: employees}
+	spec := TreeSpec{Threshold: 2, Children: []TreeSpec{organization, organization, organization}}
+	tree, err := GenKeyTree(spec, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Organization 0 has three signers, organization 1 has two, and
+	// organization 2 has three.
+	hash := sha256.Sum256([]byte(message))
+	paths := [][]int{{0, 0}, {0, 2}, {0, 4}, {1, 1}, {1, 3}, {2, 0}, {2, 1}, {2, 3}}
+	shares := make([]TreeShare, len(paths))
+	for i, path := range paths {
+		leaf, err := tree.Node(path)
+		if err != nil {
+			test.Fatal(err)
+		}
+		shares[i] = TreeShare{path, Sign(hash, leaf.Secret)}
+	}
+	signature, err := tree.Recover(shares)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, tree.Key) {
+		test.Fatal("Failed to verify signature.")
+	}
+	signature.Free()
+
+	// Organizations 0 and 1 alone do not suffice.
+	_, err = tree.Recover(shares[:5])
+	if err == nil {
+		test.Fatal("Recovered a signature from too few organizations.")
+	}
+
+	// Bad specifications are rejected.
+	_, err = GenKeyTree(TreeSpec{Threshold: 4, Children: employees[:3]}, system)
+	if err == nil {
+		test.Fatal("Accepted a bad threshold.")
+	}
+
+	// Clean up.
+	for i := range shares {
+		shares[i].Share.Free()
+	}
+	tree.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
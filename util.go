@@ -21,41 +21,6 @@ func randomHash() ([sha256.Size]byte, error) {
 	return hash, err
 }
 
-func randomHashes(n int) ([][sha256.Size]byte, error) {
-	hashes := make([][sha256.Size]byte, n)
-	var err error
-	for i := range hashes {
-		hashes[i], err = randomHash()
-		if err != nil {
-			return nil, err
-		}
-	}
-	return hashes, nil
-}
-
-func sortHashes(hashes [][sha256.Size]byte) {
-	n := len(hashes)
-	quicksort(hashes, 0, n-1)
-}
-
-func quicksort(hashes [][sha256.Size]byte, l int, r int) {
-	if l < r {
-		pivot := hashes[(l+r)/2]
-		var i, j int
-		for i, j = l, r; i <= j; i, j = i+1, j-1 {
-			for ; compare(hashes[i], pivot) == -1; i++ {}
-			for ; compare(hashes[j], pivot) == 1; j-- {}
-			if i < j {
-				hashes[i], hashes[j] = hashes[j], hashes[i]
-			} else if i != j {
-				break
-			}
-		}
-		quicksort(hashes, l, j)
-		quicksort(hashes, i, r)
-	}
-}
-
 func compare(a, b [sha256.Size]byte) int {
 	for i := 0; i < sha256.Size; i++ {
 		if a[i] > b[i] {
@@ -68,15 +33,15 @@ func compare(a, b [sha256.Size]byte) int {
 	return 0
 }
 
-func uniqueHashes(hashes [][sha256.Size]byte) bool {
-	n := len(hashes)
-	c := make([][sha256.Size]byte, n)
-	copy(c, hashes)
-	sortHashes(c)
-	for i := 0; i < n-1; i++ {
-		if c[i] == c[i+1] {
+// Check whether the hashes are distinct. The check stops at the first
+// duplicate.
+func UniqueHashes(hashes [][sha256.Size]byte) bool {
+	seen := make(map[[sha256.Size]byte]struct{}, len(hashes))
+	for i := range hashes {
+		if _, ok := seen[hashes[i]]; ok {
 			return false
 		}
+		seen[hashes[i]] = struct{}{}
 	}
 	return true
 }
@@ -12,33 +12,27 @@ package bls
 
 import (
 	"crypto/sha256"
-	"math/rand"
 	"testing"
-	"time"
 )
 
-func TestSortHashes(test *testing.T) {
-	rand.Seed(time.Now().UnixNano())
-	n := rand.Intn(100)
-	hashes, err := randomHashes(n)
-	if err != nil {
-		test.Fatal(err)
+func TestUniqueHashes(test *testing.T) {
+	words := []string{"Apple", "Bananna", "Kiwi", "Mango", "Orange", "Pineapple", "Tangerine"}
+	hashes := make([][sha256.Size]byte, len(words))
+	for i := range words {
+		hashes[i] = sha256.Sum256([]byte(words[i]))
 	}
-	sortHashes(hashes)
-	for i := 0; i < n-1; i++ {
-		if compare(hashes[i], hashes[i+1]) == 1 {
-			test.Fatal(hashes)
-		}
+	if !UniqueHashes(hashes) {
+		test.Fatal("unexpected duplicate hash")
 	}
 }
 
-func TestUniqueHashes(test *testing.T) {
-	words := []string{"Apple", "Bananna", "Kiwi", "Mango", "Orange", "Pineapple", "Tangerine"}
+func TestUniqueHashesDuplicate(test *testing.T) {
+	words := []string{"Apple", "Bananna", "Kiwi", "Apple"}
 	hashes := make([][sha256.Size]byte, len(words))
 	for i := range words {
 		hashes[i] = sha256.Sum256([]byte(words[i]))
 	}
-	if !uniqueHashes(hashes) {
-		test.Fatal("unexpected duplicate hash")
+	if UniqueHashes(hashes) {
+		test.Fatal("undetected duplicate hash")
 	}
 }
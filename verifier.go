@@ -0,0 +1,148 @@
+/**
+ * File        : verifier.go
+ * Description : Verification with preprocessed pairings.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module speeds up repeated verification by preprocessing the fixed
+ * inputs of the pairings that Verify computes, which are the system parameter
+ * and, optionally, the public key of a signer. The system parameter is
+ * preprocessed once when the cryptosystem is generated or imported. PBC only
+ * preprocesses the first input of a pairing, which must lie in G1, while the
+ * system parameter and the public keys lie in G2. The inputs can therefore
+ * only be preprocessed for symmetric pairings, such as type A, where the two
+ * inputs can be exchanged. For other pairings, a Verifier computes the
+ * pairings without preprocessing.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"unsafe"
+)
+
+const sizeOfPairingPP = C.size_t(unsafe.Sizeof(C.struct_pairing_pp_s{}))
+
+// A Verifier verifies signatures with preprocessed pairings.
+type Verifier struct {
+	system System
+	key    PublicKey
+	g      *C.struct_pairing_pp_s
+	gx     *C.struct_pairing_pp_s
+}
+
+// Preprocess the system parameter as the first input of a pairing, or return
+// nil if the pairing is not symmetric. This function allocates C structures on
+// the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func (system System) preprocessG() *C.struct_pairing_pp_s {
+	if C.pairing_is_symmetric(system.pairing.get) == 0 {
+		return nil
+	}
+	pp := (*C.struct_pairing_pp_s)(C.malloc(sizeOfPairingPP))
+	C.pairing_pp_init(pp, system.g.get, system.pairing.get)
+	return pp
+}
+
+// Create a verifier of signatures under any public key of the cryptosystem.
+// The verifier shares the preprocessed system parameter of the cryptosystem,
+// so it must not outlive it.
+func NewVerifier(system System) Verifier {
+	return Verifier{system: system, g: system.gpp}
+}
+
+// Create a verifier that also preprocesses the public key of a signer, so that
+// signatures under that key are verified faster still. Signatures under other
+// keys are verified as by NewVerifier. This function allocates C structures on
+// the C heap using malloc. It is the responsibility of the caller to prevent
+// memory leaks by arranging for the C structures to be freed.
+func NewKeyVerifier(key PublicKey) Verifier {
+	verifier := NewVerifier(key.system)
+	verifier.key = key.copy()
+	if verifier.g != nil {
+		verifier.gx = (*C.struct_pairing_pp_s)(C.malloc(sizeOfPairingPP))
+		C.pairing_pp_init(verifier.gx, key.gx.get, key.system.pairing.get)
+	}
+	return verifier
+}
+
+// Verify a signature on the message digest using the public key of the signer,
+// with the same result as Verify.
+func (verifier Verifier) Verify(signature Signature, hash [sha256.Size]byte, key PublicKey) bool {
+
+	// Check subgroup membership.
+	system := verifier.system
+	if isIdentity(signature, key.gx) {
+		return false
+	}
+	if system.checkOnUse() {
+		if !system.inSubgroup(signature) || !system.inSubgroup(key.gx) {
+			return false
+		}
+	}
+
+	// Calculate h.
+	h := system.digestToPoint(hash[:])
+
+	// Calculate the left-hand side.
+	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_GT(lhs, system.pairing.get)
+	if verifier.g != nil {
+		C.pairing_pp_apply(lhs, signature.get, verifier.g)
+	} else {
+		C.element_pairing(lhs, signature.get, system.g.get)
+	}
+
+	// Calculate the right-hand side.
+	rhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_GT(rhs, system.pairing.get)
+	preprocessed := verifier.gx != nil && verifier.key.gx.Equal(key.gx)
+	if preprocessed {
+		C.pairing_pp_apply(rhs, h, verifier.gx)
+	} else {
+		C.element_pairing(rhs, h, key.gx.get)
+	}
+
+	// Equate the left and right-hand side. If signatures are serialized
+	// without the sign of the y-coordinate, either sign is accepted.
+	C.element_invert(rhs, rhs)
+	C.element_mul(rhs, lhs, rhs)
+	result := C.element_is1(rhs) == 1
+	if !result && system.encoding.Format == PointXOnly {
+		if preprocessed {
+			C.pairing_pp_apply(rhs, h, verifier.gx)
+		} else {
+			C.element_pairing(rhs, h, key.gx.get)
+		}
+		C.element_mul(lhs, lhs, rhs)
+		result = C.element_is1(lhs) == 1
+	}
+
+	// Clean up.
+	C.element_clear(h)
+	C.element_clear(lhs)
+	C.element_clear(rhs)
+
+	// Return the result.
+	return result
+
+}
+
+// Free the memory occupied by the verifier. The verifier cannot be used after
+// calling this function.
+func (verifier Verifier) Free() {
+	if verifier.gx != nil {
+		C.pairing_pp_clear(verifier.gx)
+		C.free(unsafe.Pointer(verifier.gx))
+	}
+	if verifier.key.gx.get != nil {
+		verifier.key.Free()
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * File        : verifier_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for verification with preprocessed
+ * pairings.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifier(test *testing.T) {
+
+	message := "This is a message."
+
+	// Test a symmetric and an asymmetric pairing.
+	for _, params := range []Params{GenParamsTypeA(160, 512), GenParamsTypeF(160)} {
+
+		// Generate two key pairs.
+		pairing := GenPairing(params)
+		system, err := GenSystem(pairing)
+		if err != nil {
+			test.Fatal(err)
+		}
+		key, secret, err := GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+		other, otherSecret, err := GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		// Sign the message with both keys.
+		hash := sha256.Sum256([]byte(message))
+		signature := Sign(hash, secret)
+		otherSignature := Sign(hash, otherSecret)
+
+		// The verifiers agree with Verify.
+		verifiers := []Verifier{NewVerifier(system), NewKeyVerifier(key)}
+		for _, verifier := range verifiers {
+			if !verifier.Verify(signature, hash, key) || !verifier.Verify(otherSignature, hash, other) {
+				test.Fatal("Failed to verify signature.")
+			}
+			if verifier.Verify(otherSignature, hash, key) || verifier.Verify(signature, hash, other) {
+				test.Fatal("Accepted a signature under the wrong key.")
+			}
+			verifier.Free()
+		}
+
+		// Clean up.
+		signature.Free()
+		otherSignature.Free()
+		key.Free()
+		secret.Free()
+		other.Free()
+		otherSecret.Free()
+		system.Free()
+		pairing.Free()
+		params.Free()
+
+	}
+
+}
+
+func BenchmarkVerifier(benchmark *testing.B) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		benchmark.Fatal(err)
+	}
+
+	// Sign the message.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Verify the signature.
+	verifier := NewKeyVerifier(key)
+	benchmark.ResetTimer()
+	for i := 0; i < benchmark.N; i++ {
+		verifier.Verify(signature, hash, key)
+	}
+	benchmark.StopTimer()
+
+	// Clean up.
+	verifier.Free()
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
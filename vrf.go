@@ -0,0 +1,50 @@
+/**
+ * File        : vrf.go
+ * Description : Verifiable random function.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module implements a verifiable random function from BLS signatures.
+ * Since a BLS signature is unique for a given key and message, its hash is a
+ * pseudorandom output that anyone holding the public key can verify.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// Evaluate the verifiable random function on the input using a private key.
+// The proof is the signature on the input digest, and the output is the hash
+// of the proof. Cryptosystems that serialize signatures with PointXOnly are
+// rejected, since a decoded proof would have two valid outputs. This function
+// allocates C structures on the C heap using malloc. It is the responsibility
+// of the caller to prevent memory leaks by arranging for the C structures to
+// be freed.
+func VRFProve(input [sha256.Size]byte, secret PrivateKey) ([sha256.Size]byte, Signature, error) {
+	if !secret.system.combinable() {
+		return [sha256.Size]byte{}, Element{}, errors.New("bls.VRFProve: Proofs without a sign are not unique.")
+	}
+	proof := Sign(input, secret)
+	return vrfOutput(proof), proof, nil
+}
+
+// Verify the output of the verifiable random function on the input using the
+// public key of the prover. Proofs of cryptosystems that serialize signatures
+// with PointXOnly never verify.
+func VRFVerify(input [sha256.Size]byte, output [sha256.Size]byte, proof Signature, key PublicKey) bool {
+	if !key.system.combinable() {
+		return false
+	}
+	return Verify(proof, input, key) && vrfOutput(proof) == output
+}
+
+// Derive the output of the verifiable random function from the proof. The
+// uncompressed encoding is used, since it does not depend on the encoding
+// selected for the cryptosystem.
+func vrfOutput(proof Signature) [sha256.Size]byte {
+	return sha256.Sum256(append([]byte("go-bls vrf"), proof.key()...))
+}
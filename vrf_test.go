@@ -0,0 +1,64 @@
+/**
+ * File        : vrf_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the verifiable random function.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVRF(test *testing.T) {
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Evaluate the function twice.
+	input := sha256.Sum256([]byte("This is an input."))
+	output, proof, err := VRFProve(input, secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	again, other, err := VRFProve(input, secret)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if output != again {
+		test.Fatal("Output is not unique.")
+	}
+
+	// Verify the output.
+	if !VRFVerify(input, output, proof, key) {
+		test.Fatal("Failed to verify output.")
+	}
+	output[0] ^= 1
+	if VRFVerify(input, output, proof, key) {
+		test.Fatal("Verified wrong output.")
+	}
+
+	// Clean up.
+	proof.Free()
+	other.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,412 @@
+/**
+ * File        : vss.go
+ * Description : Verifiable secret sharing.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module checks key shares against the commitments g^{a_j} of a dealer to
+ * the coefficients of its secret polynomial. A share for member i is valid if
+ * it is the evaluation of the polynomial at i + 1.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+
+void eval_shares(struct element_s **secrets, struct element_s **keys, struct element_s **commits, struct element_s **xs, int n, struct element_s **coeff, int t, struct element_s *g);
+*/
+import "C"
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// The bit length of the random weights used in batch verification. A batch
+// containing an invalid share passes with probability at most 2^-128.
+const batchWeightBits = 128
+
+// Verify the public key shares of all group members against the commitments of
+// the dealer in one pass. The share of member i is at index i. Rather than
+// evaluating the committed polynomial for each member, the check compares a
+// random linear combination of the shares with the same combination of the
+// evaluations, which costs one exponentiation per share and per commitment.
+func VerifyShares(commitments []PublicKey, memberKeys []PublicKey) (bool, error) {
+
+	// Check the list length.
+	if len(commitments) == 0 || len(memberKeys) == 0 {
+		return false, errors.New("bls.VerifyShares: Empty list.")
+	}
+
+	// Choose the random weights and combine the evaluation points.
+	weights, exps, err := batchWeights(len(memberKeys), len(commitments), identityIds(len(memberKeys)), commitments[0].system.order())
+	if err != nil {
+		return false, err
+	}
+
+	// Calculate the left-hand side.
+	system := commitments[0].system
+	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(lhs, system.pairing.get)
+	C.element_set1(lhs)
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(t, system.pairing.get)
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	for i := range memberKeys {
+		setMpz(&z[0], weights[i])
+		C.element_pow_mpz(t, memberKeys[i].gx.get, &z[0])
+		C.element_mul(lhs, lhs, t)
+	}
+
+	// Calculate the right-hand side.
+	rhs := evalCommitments(commitments, exps)
+
+	// Equate the left and right-hand side.
+	result := C.element_cmp(lhs, rhs) == 0
+
+	// Clean up.
+	C.mpz_clear(&z[0])
+	C.element_clear(t)
+	C.element_clear(lhs)
+	C.element_clear(rhs)
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Verify the private key share of member i against the commitments of the
+// dealer, by checking that g^{share} equals the product of the commitments
+// raised to the powers of i + 1. A member calls this on receipt of its share to
+// detect a malicious or faulty dealer.
+func VerifyKeyShare(memberId int, secret PrivateKey, commitments []PublicKey) (bool, error) {
+
+	// Evaluate the commitments at the member point.
+	key, err := CommitmentKey(commitments, memberId)
+	if err != nil {
+		return false, err
+	}
+
+	// Calculate g^{share}.
+	gx := secret.Public()
+
+	// Compare.
+	result := gx.Equal(key)
+
+	// Clean up.
+	gx.Free()
+	key.Free()
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Verify private key shares held by one party against the commitments of the
+// dealer in one pass. The shares belong to the members with the given
+// identifiers. Since the shares are scalars, the check needs a single
+// exponentiation of the system parameter in addition to one per commitment.
+func VerifySecretShares(commitments []PublicKey, memberIds []int, secrets []PrivateKey) (bool, error) {
+
+	// Check the list length.
+	if len(commitments) == 0 || len(secrets) == 0 {
+		return false, errors.New("bls.VerifySecretShares: Empty list.")
+	}
+	if len(secrets) != len(memberIds) {
+		return false, errors.New("bls.VerifySecretShares: List length mismatch.")
+	}
+
+	// Choose the random weights and combine the evaluation points.
+	system := commitments[0].system
+	r := system.order()
+	weights, exps, err := batchWeights(len(secrets), len(commitments), memberIds, r)
+	if err != nil {
+		return false, err
+	}
+
+	// Combine the shares.
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	C.element_set0(x)
+	t := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(t, system)
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	for i := range secrets {
+		setMpz(&z[0], weights[i])
+		C.element_mul_mpz(t, secrets[i].x.get, &z[0])
+		C.element_add(x, x, t)
+	}
+
+	// Calculate the left-hand side.
+	lhs := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(lhs, system.pairing.get)
+	err = powSecret(lhs, system.g.get, x)
+	if err != nil {
+		C.mpz_clear(&z[0])
+		C.element_clear(x)
+		C.element_clear(t)
+		C.element_clear(lhs)
+		return false, err
+	}
+
+	// Calculate the right-hand side.
+	rhs := evalCommitments(commitments, exps)
+
+	// Equate the left and right-hand side.
+	result := C.element_cmp(lhs, rhs) == 0
+
+	// Clean up.
+	C.mpz_clear(&z[0])
+	C.element_clear(x)
+	C.element_clear(t)
+	C.element_clear(lhs)
+	C.element_clear(rhs)
+
+	// Return the result.
+	return result, nil
+
+}
+
+// Deal shares of a random secret as in Feldman's scheme. The dealer chooses a
+// random polynomial f of degree t-1, publishes the commitments g^{a_j} to its
+// coefficients, and gives member i the private share f(i + 1). The secret f(0)
+// itself is never computed. This function allocates C structures on the C heap
+// using malloc. It is the responsibility of the caller to prevent memory leaks
+// by arranging for the C structures to be freed.
+func Deal(t int, n int, system System) ([]PublicKey, []PrivateKey, error) {
+
+	// Check the threshold parameters.
+	if t < 1 || n < t {
+		return nil, nil, errors.New("bls.Deal: Bad threshold parameters.")
+	}
+
+	// Generate the polynomial and the commitments.
+	coeff := make([]PrivateKey, t)
+	commitments := make([]PublicKey, t)
+	for j := range coeff {
+		key, secret, err := GenKeys(system)
+		if err != nil {
+			for k := 0; k < j; k++ {
+				coeff[k].Zeroize()
+				commitments[k].Free()
+			}
+			return nil, nil, err
+		}
+		coeff[j], commitments[j] = secret, key
+	}
+
+	// Evaluate the polynomial at the member points.
+	shares := evalPolynomial(coeff, n)
+
+	// Clean up.
+	for j := range coeff {
+		coeff[j].Zeroize()
+	}
+
+	// Return the commitments and the shares.
+	return commitments, shares, nil
+
+}
+
+// Deal shares of the given secret as in Feldman's scheme, so that the secret
+// can be passed to a new group. The dealer chooses a random polynomial f of
+// degree t-1 with f(0) equal to the secret, publishes the commitments g^{a_j}
+// to its coefficients, and gives member i the private share f(i + 1). The
+// first commitment is the public key of the secret. This function allocates C
+// structures on the C heap using malloc. It is the responsibility of the
+// caller to prevent memory leaks by arranging for the C structures to be
+// freed.
+func DealSecret(secret PrivateKey, t int, n int) ([]PublicKey, []PrivateKey, error) {
+
+	// Check the threshold parameters.
+	if t < 1 || n < t {
+		return nil, nil, errors.New("bls.DealSecret: Bad threshold parameters.")
+	}
+	system := secret.system
+
+	// Generate the polynomial and the commitments.
+	coeff := make([]PrivateKey, t)
+	commitments := make([]PublicKey, t)
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	C.element_set(x, secret.x.get)
+	coeff[0] = PrivateKey{system, Element{x}}
+	commitments[0] = secret.Public()
+	for j := 1; j < t; j++ {
+		key, c, err := GenKeys(system)
+		if err != nil {
+			for k := 0; k < j; k++ {
+				coeff[k].Zeroize()
+				commitments[k].Free()
+			}
+			return nil, nil, err
+		}
+		coeff[j], commitments[j] = c, key
+	}
+
+	// Evaluate the polynomial at the member points.
+	shares := evalPolynomial(coeff, n)
+
+	// Clean up.
+	for j := range coeff {
+		coeff[j].Zeroize()
+	}
+
+	// Return the commitments and the shares.
+	return commitments, shares, nil
+
+}
+
+// Derive the public key share g^{f(i + 1)} of member i from the commitments of
+// a dealer. This function allocates C structures on the C heap using malloc. It
+// is the responsibility of the caller to prevent memory leaks by arranging for
+// the C structures to be freed.
+func CommitmentKey(commitments []PublicKey, memberId int) (PublicKey, error) {
+	if len(commitments) == 0 {
+		return PublicKey{}, errors.New("bls.CommitmentKey: Empty list.")
+	}
+	if memberId < 0 {
+		return PublicKey{}, errors.New("bls.CommitmentKey: Member identifier out of range.")
+	}
+	exps := make([]*big.Int, len(commitments))
+	x := big.NewInt(int64(memberId + 1))
+	p := big.NewInt(1)
+	for j := range exps {
+		exps[j] = big.NewInt(0).Set(p)
+		p.Mul(p, x)
+	}
+	return PublicKey{commitments[0].system, Element{evalCommitments(commitments, exps)}}, nil
+}
+
+// Add private keys, such as the shares a member receives from several dealers.
+// This function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func AddPrivateKeys(secrets []PrivateKey) (PrivateKey, error) {
+	if len(secrets) == 0 {
+		return PrivateKey{}, errors.New("bls.AddPrivateKeys: Empty list.")
+	}
+	system := secrets[0].system
+	x := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	initSecret(x, system)
+	C.element_set0(x)
+	for i := range secrets {
+		C.element_add(x, x, secrets[i].x.get)
+	}
+	return PrivateKey{system, Element{x}}, nil
+}
+
+// Combine the commitments of several dealers into the commitments to the sum
+// of their polynomials, by multiplying them position by position. All lists
+// must have the same length. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func AddCommitments(commitments [][]PublicKey) ([]PublicKey, error) {
+	if len(commitments) == 0 || len(commitments[0]) == 0 {
+		return nil, errors.New("bls.AddCommitments: Empty list.")
+	}
+	t := len(commitments[0])
+	for i := range commitments {
+		if len(commitments[i]) != t {
+			return nil, errors.New("bls.AddCommitments: List length mismatch.")
+		}
+	}
+	system := commitments[0][0].system
+	result := make([]PublicKey, t)
+	for j := range result {
+		gx := (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_G2(gx, system.pairing.get)
+		C.element_set1(gx)
+		for i := range commitments {
+			C.element_mul(gx, gx, commitments[i][j].gx.get)
+		}
+		result[j] = PublicKey{system, Element{gx}}
+	}
+	return result, nil
+}
+
+// Evaluate the polynomial with the given coefficients at the member points
+// 1, ..., n using Horner's rule. This function allocates C structures on the C
+// heap using malloc. It is the responsibility of the caller to prevent memory
+// leaks by arranging for the C structures to be freed.
+func evalPolynomial(coeff []PrivateKey, n int) []PrivateKey {
+	system := coeff[0].system
+	values := make([]PrivateKey, n)
+	valuePtrs := make([]*C.struct_element_s, n)
+	xPtrs := make([]*C.struct_element_s, n)
+	coeffPtrs := make([]*C.struct_element_s, len(coeff))
+	for j := range coeff {
+		coeffPtrs[j] = coeff[j].x.get
+	}
+	for i := range values {
+		valuePtrs[i] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		initSecret(valuePtrs[i], system)
+		values[i] = PrivateKey{system, Element{valuePtrs[i]}}
+		xPtrs[i] = (*C.struct_element_s)(C.malloc(sizeOfElement))
+		C.element_init_Zr(xPtrs[i], system.pairing.get)
+		C.element_set_si(xPtrs[i], C.long(i+1))
+	}
+	C.eval_shares(&valuePtrs[0], nil, nil, &xPtrs[0], C.int(n), &coeffPtrs[0], C.int(len(coeff)), nil)
+	for i := range xPtrs {
+		C.element_clear(xPtrs[i])
+	}
+	return values
+}
+
+// Choose a random weight for each of n shares, and combine the powers of the
+// evaluation points of the shares with the weights. The exponent of the j-th
+// commitment is the sum over the shares of weight_i * (id_i + 1)^j mod r.
+func batchWeights(n int, t int, memberIds []int, r *big.Int) ([]*big.Int, []*big.Int, error) {
+	weights := make([]*big.Int, n)
+	exps := make([]*big.Int, t)
+	for j := range exps {
+		exps[j] = big.NewInt(0)
+	}
+	bound := big.NewInt(0).Lsh(big.NewInt(1), batchWeightBits)
+	var err error
+	for i := range weights {
+		weights[i], err = rand.Int(rand.Reader, bound)
+		if err != nil {
+			return nil, nil, err
+		}
+		x := big.NewInt(int64(memberIds[i] + 1))
+		p := big.NewInt(0).Set(weights[i])
+		for j := range exps {
+			exps[j].Add(exps[j], p)
+			exps[j].Mod(exps[j], r)
+			p.Mul(p, x)
+			p.Mod(p, r)
+		}
+	}
+	return weights, exps, nil
+}
+
+// Calculate the product of the commitments raised to the given exponents. This
+// function allocates C structures on the C heap using malloc. It is the
+// responsibility of the caller to prevent memory leaks by arranging for the C
+// structures to be freed.
+func evalCommitments(commitments []PublicKey, exps []*big.Int) *C.struct_element_s {
+	system := commitments[0].system
+	result := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G2(result, system.pairing.get)
+	bases := make([]*C.struct_element_s, len(commitments))
+	for j := range commitments {
+		bases[j] = commitments[j].gx.get
+	}
+	multiExp(result, bases, exps)
+	return result
+}
+
+// Return the member identifiers 0, ..., n-1.
+func identityIds(n int) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i
+	}
+	return ids
+}
@@ -0,0 +1,252 @@
+/**
+ * File        : vss_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for verifiable secret sharing.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyShares(test *testing.T) {
+
+	t := 3
+	n := 5
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	r := system.order()
+
+	// Commit to a random polynomial.
+	commitments := make([]PublicKey, t)
+	coeffs := make([]PrivateKey, t)
+	for j := 0; j < t; j++ {
+		commitments[j], coeffs[j], err = GenKeys(system)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// Evaluate the polynomial for each member.
+	memberKeys := make([]PublicKey, n)
+	memberSecrets := make([]PrivateKey, n)
+	for i := 0; i < n; i++ {
+		x := big.NewInt(0)
+		exps := make([]*big.Int, t)
+		for j := t - 1; j >= 0; j-- {
+			x.Mul(x, big.NewInt(int64(i+1)))
+			x.Add(x, big.NewInt(0).SetBytes(coeffs[j].ToBytes()))
+			x.Mod(x, r)
+			exps[j] = big.NewInt(0).Exp(big.NewInt(int64(i+1)), big.NewInt(int64(j)), nil)
+		}
+		bytes := make([]byte, len(coeffs[0].ToBytes()))
+		memberSecrets[i], err = PrivateKeyFromBytes(system, x.FillBytes(bytes))
+		if err != nil {
+			test.Fatal(err)
+		}
+		memberKeys[i] = PublicKey{system, Element{evalCommitments(commitments, exps)}}
+	}
+
+	// Verify the shares.
+	valid, err := VerifyShares(commitments, memberKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify public key shares.")
+	}
+	valid, err = VerifySecretShares(commitments, []int{1, 3}, []PrivateKey{memberSecrets[1], memberSecrets[3]})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify private key shares.")
+	}
+
+	// Swapped shares must be rejected.
+	memberKeys[0], memberKeys[1] = memberKeys[1], memberKeys[0]
+	valid, err = VerifyShares(commitments, memberKeys)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified swapped public key shares.")
+	}
+	valid, err = VerifySecretShares(commitments, []int{1, 3}, []PrivateKey{memberSecrets[3], memberSecrets[1]})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if valid {
+		test.Fatal("Verified swapped private key shares.")
+	}
+
+	// Clean up.
+	for j := 0; j < t; j++ {
+		commitments[j].Free()
+		coeffs[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestDeal(test *testing.T) {
+
+	t := 3
+	n := 5
+	message := "This is a message."
+
+	// Generate a cryptosystem.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Deal shares from two dealers and combine them.
+	commitments1, shares1, err := Deal(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	commitments2, shares2, err := Deal(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	memberIds := []int{0, 1, 2, 3, 4}
+	valid, err := VerifySecretShares(commitments1, memberIds, shares1)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !valid {
+		test.Fatal("Failed to verify dealt shares.")
+	}
+	commitments, err := AddCommitments([][]PublicKey{commitments1, commitments2})
+	if err != nil {
+		test.Fatal(err)
+	}
+	memberSecrets := make([]PrivateKey, n)
+	memberKeys := make([]PublicKey, n)
+	for i := 0; i < n; i++ {
+		memberSecrets[i], err = AddPrivateKeys([]PrivateKey{shares1[i], shares2[i]})
+		if err != nil {
+			test.Fatal(err)
+		}
+		memberKeys[i], err = CommitmentKey(commitments, i)
+		if err != nil {
+			test.Fatal(err)
+		}
+		public := memberSecrets[i].Public()
+		if !public.Equal(memberKeys[i]) {
+			test.Fatal("Public key share does not match the commitments.")
+		}
+		public.Free()
+	}
+
+	// A threshold signature must verify under the combined commitment.
+	hash := sha256.Sum256([]byte(message))
+	memberIds = []int{1, 3, 4}
+	signatures := make([]Signature, t)
+	for i := 0; i < t; i++ {
+		signatures[i] = Sign(hash, memberSecrets[memberIds[i]])
+	}
+	signature, err := Threshold(signatures, memberIds, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signature, hash, commitments[0]) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Clean up.
+	signature.Free()
+	for i := 0; i < t; i++ {
+		signatures[i].Free()
+		commitments1[i].Free()
+		commitments2[i].Free()
+		commitments[i].Free()
+	}
+	for i := 0; i < n; i++ {
+		shares1[i].Free()
+		shares2[i].Free()
+		memberSecrets[i].Free()
+		memberKeys[i].Free()
+	}
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
+
+func TestVerifyKeyShare(test *testing.T) {
+
+	t := 3
+	n := 5
+
+	// Generate a cryptosystem and the key shares.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	groupKey, memberKeys, groupSecret, memberSecrets, commitments, err := GenKeyShares(t, n, system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Each share must match the commitments at its own index only.
+	for i := 0; i < n; i++ {
+		valid, err := VerifyKeyShare(i, memberSecrets[i], commitments)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !valid {
+			test.Fatal("Failed to verify key share.")
+		}
+		valid, err = VerifyKeyShare((i+1)%n, memberSecrets[i], commitments)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if valid {
+			test.Fatal("Verified key share at the wrong index.")
+		}
+	}
+	_, err = VerifyKeyShare(0, memberSecrets[0], nil)
+	if err == nil {
+		test.Fatal("Verified key share against no commitments.")
+	}
+
+	// Clean up.
+	for j := 0; j < t; j++ {
+		commitments[j].Free()
+	}
+	for i := 0; i < n; i++ {
+		memberKeys[i].Free()
+		memberSecrets[i].Free()
+	}
+	groupSecret.Free()
+	groupKey.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,110 @@
+/**
+ * File        : wire.go
+ * Description : Versioned wire format.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module defines a self-describing wire format for keys and signatures.
+ * Each value is prefixed with a version byte, a kind byte, and the fingerprint
+ * of the cryptosystem, so that a value from another system or a future format
+ * is rejected instead of being decoded into a garbage point.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// The current version of the wire format.
+const WireVersion byte = 1
+
+// The kind of a value in the wire format.
+const (
+	wirePublicKey byte = iota + 1
+	wirePrivateKey
+	wireSignature
+)
+
+// The length of the wire format header.
+const wireHeaderSize = 2 + sha256.Size
+
+// Export the public key in the wire format.
+func (key PublicKey) ToWire() []byte {
+	return append(key.system.wireHeader(wirePublicKey), key.ToBytes()...)
+}
+
+// PublicKeyFromWire imports a public key in the wire format.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PublicKeyFromWire(system System, data []byte) (PublicKey, error) {
+	payload, err := system.checkWireHeader(wirePublicKey, data)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKeyFromBytes(system, payload)
+}
+
+// Export the private key in the wire format.
+func (secret PrivateKey) ToWire() []byte {
+	return append(secret.system.wireHeader(wirePrivateKey), secret.ToBytes()...)
+}
+
+// PrivateKeyFromWire imports a private key in the wire format.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func PrivateKeyFromWire(system System, data []byte) (PrivateKey, error) {
+	payload, err := system.checkWireHeader(wirePrivateKey, data)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	return PrivateKeyFromBytes(system, payload)
+}
+
+// Export a signature in the wire format.
+func (system System) SigToWire(signature Signature) []byte {
+	return append(system.wireHeader(wireSignature), system.SigToBytes(signature)...)
+}
+
+// Import a signature in the wire format.
+//
+// This function allocates C structures on the C heap using malloc. It is
+// the responsibility of the caller to prevent memory leaks by arranging
+// for the C structures to be freed.
+func (system System) SigFromWire(data []byte) (Signature, error) {
+	payload, err := system.checkWireHeader(wireSignature, data)
+	if err != nil {
+		return Signature{}, err
+	}
+	return system.SigFromBytes(payload)
+}
+
+// Build the wire format header for a value of the given kind.
+func (system System) wireHeader(kind byte) []byte {
+	fingerprint := system.Fingerprint()
+	return append([]byte{WireVersion, kind}, fingerprint[:]...)
+}
+
+// Check the wire format header and return the payload.
+func (system System) checkWireHeader(kind byte, data []byte) ([]byte, error) {
+	if len(data) < wireHeaderSize {
+		return nil, errors.New("bls.FromWire: Data too short.")
+	}
+	if data[0] != WireVersion {
+		return nil, errors.New("bls.FromWire: Unsupported version.")
+	}
+	if data[1] != kind {
+		return nil, errors.New("bls.FromWire: Unexpected kind.")
+	}
+	fingerprint := system.Fingerprint()
+	if string(data[2:wireHeaderSize]) != string(fingerprint[:]) {
+		return nil, errors.New("bls.FromWire: Value belongs to another system.")
+	}
+	return data[wireHeaderSize:], nil
+}
@@ -0,0 +1,80 @@
+/**
+ * File        : wire_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for the wire format.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestWire(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Round-trip the key and the signature.
+	keyIn, err := PublicKeyFromWire(system, key.ToWire())
+	if err != nil {
+		test.Fatal(err)
+	}
+	signatureIn, err := system.SigFromWire(system.SigToWire(signature))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !Verify(signatureIn, hash, keyIn) {
+		test.Fatal("Failed to verify signature.")
+	}
+
+	// Mismatched kinds, versions, and systems must be rejected.
+	_, err = system.SigFromWire(key.ToWire())
+	if err == nil {
+		test.Fatal("Accepted a key as a signature.")
+	}
+	data := key.ToWire()
+	data[0]++
+	_, err = PublicKeyFromWire(system, data)
+	if err == nil {
+		test.Fatal("Accepted an unsupported version.")
+	}
+	other, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	_, err = PublicKeyFromWire(other, key.ToWire())
+	if err == nil {
+		test.Fatal("Accepted a key of another system.")
+	}
+
+	// Clean up.
+	other.Free()
+	signatureIn.Free()
+	keyIn.Free()
+	signature.Free()
+	secret.Free()
+	key.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}
@@ -0,0 +1,112 @@
+/**
+ * File        : witness.go
+ * Description : Witness export for zero-knowledge proof systems.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module decomposes the verification of a signature into the field
+ * elements that an arithmetic circuit needs as witnesses, so that proof
+ * systems can attest to a verification performed by this package.
+ */
+
+package bls
+
+/*
+#include <pbc/pbc.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"unsafe"
+)
+
+// A Witness holds the intermediate values of a signature verification. Group
+// elements are given by their affine coordinates, flattened into base field
+// elements as described by Coordinates.
+type Witness struct {
+	Digest    [sha256.Size]byte
+	Hash      []*big.Int
+	Signature []*big.Int
+	Generator []*big.Int
+	PublicKey []*big.Int
+	Valid     bool
+}
+
+// Export the witness for the verification of a signature on the message digest
+// using the public key of the signer. The witness records the digest, the
+// message digest mapped to G1, the signature, the system parameter, the public
+// key, and the result of the verification. A signature or public key at
+// infinity yields an error.
+func ExportWitness(signature Signature, hash [sha256.Size]byte, key PublicKey) (Witness, error) {
+
+	// Calculate h.
+	h := (*C.struct_element_s)(C.malloc(sizeOfElement))
+	C.element_init_G1(h, key.system.pairing.get)
+	C.element_from_hash(h, unsafe.Pointer(&hash[0]), sha256.Size)
+
+	// Collect the witness.
+	witness := Witness{Digest: hash}
+	var err error
+	witness.Hash, err = Element{h}.Coordinates()
+	if err == nil {
+		witness.Signature, err = signature.Coordinates()
+	}
+	if err == nil {
+		witness.Generator, err = key.system.g.Coordinates()
+	}
+	if err == nil {
+		witness.PublicKey, err = key.gx.Coordinates()
+	}
+
+	// Clean up.
+	C.element_clear(h)
+
+	// Return the witness.
+	if err != nil {
+		return Witness{}, err
+	}
+	witness.Valid = Verify(signature, hash, key)
+	return witness, nil
+
+}
+
+// Decompose the element into base field elements. A point on a curve yields its
+// affine x-coordinate followed by its y-coordinate, and an element of an
+// extension field yields its coefficients, each decomposed recursively. The
+// point at infinity has no affine coordinates and yields an error.
+func (element Element) Coordinates() ([]*big.Int, error) {
+
+	// Check for the point at infinity. It is both the zero and the one of its
+	// group, which no field element is.
+	if C.element_is0(element.get) == 1 && C.element_is1(element.get) == 1 {
+		return nil, errors.New("bls.Coordinates: Point at infinity.")
+	}
+
+	// Read a base field element.
+	if C.element_item_count(element.get) == 0 {
+		var z C.mpz_t
+		C.mpz_init(&z[0])
+		C.element_to_mpz(&z[0], element.get)
+		x := getMpz(&z[0])
+		C.mpz_clear(&z[0])
+		return []*big.Int{x}, nil
+	}
+
+	// Decompose the items.
+	var coordinates []*big.Int
+	n := int(C.element_item_count(element.get))
+	for i := 0; i < n; i++ {
+		item := C.element_item(element.get, C.int(i))
+		xs, err := Element{item}.Coordinates()
+		if err != nil {
+			return nil, err
+		}
+		coordinates = append(coordinates, xs...)
+	}
+	return coordinates, nil
+
+}
@@ -0,0 +1,73 @@
+/**
+ * File        : witness_test.go
+ * Description : Unit tests.
+ * Copyright   : Copyright (c) 2017-2018 DFINITY Stiftung. All rights reserved.
+ * Maintainer  : Enzo Haussecker <enzo@dfinity.org>
+ * Stability   : Experimental
+ *
+ * This module provides unit tests for witness export.
+ */
+
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestExportWitness(test *testing.T) {
+
+	message := "This is a message."
+
+	// Generate a key pair.
+	params := GenParamsTypeA(160, 512)
+	pairing := GenPairing(params)
+	system, err := GenSystem(pairing)
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, secret, err := GenKeys(system)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Sign the message.
+	hash := sha256.Sum256([]byte(message))
+	signature := Sign(hash, secret)
+
+	// Export the witness.
+	witness, err := ExportWitness(signature, hash, key)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !witness.Valid {
+		test.Fatal("Failed to verify signature.")
+	}
+	if witness.Digest != hash {
+		test.Fatal("Witness digest mismatch.")
+	}
+	if len(witness.Hash) != 2 || len(witness.Signature) != 2 {
+		test.Fatal("Expected affine coordinates in G1.")
+	}
+	if len(witness.Generator) != 2 || len(witness.PublicKey) != 2 {
+		test.Fatal("Expected affine coordinates in G2.")
+	}
+
+	// A signature at infinity has no coordinates.
+	infinity := interpolateShares([]Signature{signature}, []*big.Int{big.NewInt(0)}, system)
+	_, err = ExportWitness(infinity, hash, key)
+	if err == nil {
+		test.Fatal("Exported a witness for the point at infinity.")
+	}
+
+	// Clean up.
+	infinity.Free()
+	signature.Free()
+	key.Free()
+	secret.Free()
+	system.Free()
+	pairing.Free()
+	params.Free()
+
+}